@@ -35,10 +35,9 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"frodo/cnf"
-	"frodo/db/mysql"
 	dictActions "frodo/dictionary/actions"
-	"frodo/liveattrs"
 	"frodo/liveattrs/laconf"
+	"frodo/pipeline"
 
 	vteCnf "github.com/czcorpus/vert-tagextract/v3/cnf"
 )
@@ -154,10 +153,13 @@ func run(ctx context.Context, configFilePath string) {
 		return
 	}
 
-	liveattrsPath := fmt.Sprintf("liveAttributes/%s/data", config.GetDatasetName())
-	liveattrsParams := url.Values{
-		"aliasOf":     []string{config.Corpname},
-		"reconfigure": []string{"1"},
+	// All the jobs (liveattrs for each ngram size, dataset size
+	// and ngrams) are submitted at once as a single pipeline
+	// with each step waiting for the previous one.
+	datasetName := config.GetDatasetName()
+	liveattrsParams := map[string]string{
+		"aliasOf":     config.Corpname,
+		"reconfigure": "1",
 	} // required so the liveattrs job don't search for the corpus in the database
 	liveAttrsArgs := laconf.PatchArgs{
 		VerticalFiles: verts,
@@ -166,87 +168,88 @@ func run(ctx context.Context, configFilePath string) {
 			CalcARF:     config.CalcARF,
 		},
 	}
-	var datasetSize int64
+	var plnReq pipeline.Request
+	prevStep := []string{}
 	for i := 1; i <= config.NGramSize; i++ {
-		log.Info().Msgf("Running live attributes job with ngrams of size %d", i)
 		liveAttrsArgs.Ngrams.NgramSize = i
-		fetchDatasetSize := func(jobInfo []byte) error {
-			if i == 1 {
-				var status liveattrs.LiveAttrsJobInfo
-				if err := json.Unmarshal(jobInfo, &status); err != nil {
-					return err
-				}
-				datasetSize = int64(status.ProcessedTokens)
-
-				fmt.Printf("liveattrs job finished:")
-				fmt.Printf("processed lines: %d", status.ProcessedLines)
-				fmt.Printf("processed atom structures: %d", status.ProcessedAtoms)
-				fmt.Printf("processed tokens: %d", status.ProcessedTokens)
-			}
-			return nil
-		}
-		if err := doJob(
-			ctx,
-			config.API.BaseURL,
-			liveattrsPath,
-			liveattrsParams,
-			liveAttrsArgs,
-			time.Duration(config.DataFetchJobTimeoutSecs)*time.Second,
-			fetchDatasetSize,
-		); err != nil {
-			log.Error().Err(err).Msg("Error running live attributes job")
+		args, err := json.Marshal(liveAttrsArgs)
+		if err != nil {
+			log.Error().Err(err).Msg("Error encoding live attributes job arguments")
 			return
 		}
-	}
-
-	db, err := mysql.OpenImportTunedDB(*config.Database)
-	if err != nil {
-		log.Error().Err(err).Msg("Error opening database connection")
-		return
-	}
-	defer db.Close()
-
-	if datasetSize > 0 {
-		if _, err := db.DB().ExecContext(
-			ctx,
-			"INSERT INTO dataset_sizes (name, size) VALUES (?, ?) ON DUPLICATE KEY UPDATE size = ?",
-			config.GetDatasetName(),
-			datasetSize,
-			datasetSize,
-		); err != nil {
-			log.Error().Err(err).Msg("Error inserting/updating dataset size")
-			return
+		step := pipeline.Step{
+			ID:     fmt.Sprintf("liveattrs%d", i),
+			Action: pipeline.ActionLiveAttrs,
+			Target: datasetName,
+			Query:  liveattrsParams,
+			Args:   args,
+			After:  prevStep,
 		}
+		plnReq.Steps = append(plnReq.Steps, step)
+		prevStep = []string{step.ID}
 	}
+	plnReq.Steps = append(
+		plnReq.Steps,
+		pipeline.Step{
+			ID:     "datasetSize",
+			Action: pipeline.ActionDatasetSize,
+			Target: datasetName,
+			After:  prevStep,
+		},
+	)
 
-	// Run ngrams job
-	ngramsPath := fmt.Sprintf("dictionary/%s/ngrams", config.GetDatasetName())
-	ngramsParams := url.Values{
-		"append":    []string{"0"},
-		"ngramSize": []string{fmt.Sprintf("%d", config.NGramSize)},
-		"aliasOf":   []string{config.Corpname},
-	}
-	ngramsArgs := dictActions.NGramsReqArgs{
+	ngramsArgs, err := json.Marshal(dictActions.NGramsReqArgs{
 		ColMapping:            config.GetColMapping(),
 		PosTagset:             corp.TagsetCSCNC2020,
 		UsePartitionedTable:   false,
 		MinFreq:               1,
 		SkipGroupedNameSearch: true, // required so the ngrams job don't search for the corpus in the database
+	})
+	if err != nil {
+		log.Error().Err(err).Msg("Error encoding ngrams job arguments")
+		return
 	}
-	log.Info().Msg("Running ngrams job")
+	plnReq.Steps = append(
+		plnReq.Steps,
+		pipeline.Step{
+			ID:     "ngrams",
+			Action: pipeline.ActionNgrams,
+			Target: datasetName,
+			Query: map[string]string{
+				"append":    "0",
+				"ngramSize": fmt.Sprintf("%d", config.NGramSize),
+				"aliasOf":   config.Corpname,
+			},
+			Args:  ngramsArgs,
+			After: []string{"datasetSize"},
+		},
+	)
 
-	if err := doJob(
-		ctx,
-		config.API.BaseURL,
-		ngramsPath,
-		ngramsParams,
-		ngramsArgs,
-		time.Duration(config.DictBuildJobTimeoutSecs)*time.Second,
-		nil,
-	); err != nil {
-		log.Error().Err(err).Msg("Error running live attributes job")
+	log.Info().Int("numSteps", len(plnReq.Steps)).Msg("Running dictionary build pipeline")
+	maxProcTime := time.Duration(config.NGramSize*config.DataFetchJobTimeoutSecs+config.DictBuildJobTimeoutSecs) * time.Second
+	plnStatus, err := doPipeline(ctx, config.API.BaseURL, plnReq, maxProcTime)
+	if err != nil {
+		log.Error().Err(err).Msg("Error running dictionary build pipeline")
 		return
 	}
+	for _, step := range plnStatus.Steps {
+		if step.ID != "datasetSize" {
+			continue
+		}
+		jobURL, err := url.JoinPath(config.API.BaseURL, "jobs", step.JobID)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to get dataset size job")
+			break
+		}
+		var sizeJob struct {
+			Size int64 `json:"size"`
+		}
+		if err := getJSON(jobURL, &sizeJob); err != nil {
+			log.Error().Err(err).Msg("failed to get dataset size job")
+			break
+		}
+		log.Info().Int64("size", sizeJob.Size).Str("dataset", datasetName).Msg("dataset size updated")
+	}
 
 	if config.APIGuardReset.isDefined() {
 		log.Info().Msg("clearing apiguard cache")
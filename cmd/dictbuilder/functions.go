@@ -23,6 +23,7 @@ import (
 	"errors"
 	"fmt"
 	"frodo/db/mysql"
+	"frodo/pipeline"
 	"io"
 	"net/http"
 	"net/url"
@@ -47,104 +48,92 @@ func replaceTable(db *mysql.Adapter, corpusName string, tmpCorpusName string, su
 	return nil
 }
 
-// refreshJobStatus asks for job status via http and updates provided job object
-// (which is a simplified subset of all possible job statuses we can encounter here).
-// The function also returns raw job info response for later processing.
-func refreshJobStatus(jobURL string, job *JobStatus) ([]byte, error) {
-	resp, err := http.Get(jobURL)
-	if err != nil {
-		return []byte{}, err
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return []byte{}, err
-	}
-
-	if err := json.Unmarshal(body, job); err != nil {
-		return []byte{}, err
-	}
-
-	return body, nil
-}
-
-func doJob(
+// doPipeline submits a pipeline of jobs and waits until all its steps
+// are resolved (or until maxProcTime elapses). The final pipeline status
+// is returned in case the pipeline finished successfully.
+func doPipeline(
 	ctx context.Context,
 	api string,
-	jobPath string,
-	jobParams url.Values,
-	jobArgs any,
+	req pipeline.Request,
 	maxProcTime time.Duration,
-	onFinalStatus func(response []byte) error,
-) error {
-	jobURL, err := url.JoinPath(api, jobPath)
+) (pipeline.Status, error) {
+	var status pipeline.Status
+	submitURL, err := url.JoinPath(api, "jobs", "pipeline")
 	if err != nil {
-		return err
+		return status, err
 	}
-	if len(jobParams) > 0 {
-		jobURL += "?" + jobParams.Encode()
-	}
-
-	args, err := json.Marshal(jobArgs)
+	args, err := json.Marshal(req)
 	if err != nil {
-		return err
+		return status, err
 	}
-	resp, err := http.Post(jobURL, "application/json", bytes.NewBuffer(args))
+	resp, err := http.Post(submitURL, "application/json", bytes.NewBuffer(args))
 	if err != nil {
-		return err
+		return status, err
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return err
+		return status, err
 	}
-
-	var job JobStatus
-	if err := json.Unmarshal(body, &job); err != nil {
-		return err
+	if resp.StatusCode != http.StatusCreated {
+		var respErr JobStatus
+		if err := json.Unmarshal(body, &respErr); err != nil || respErr.Error == "" {
+			return status, fmt.Errorf("pipeline submit returned status %d", resp.StatusCode)
+		}
+		return status, errors.New(respErr.Error)
 	}
-	if job.Error != "" {
-		return errors.New(job.Error)
+	if err := json.Unmarshal(body, &status); err != nil {
+		return status, err
 	}
 
-	// periodically check job status
-	refreshURL, err := url.JoinPath(api, "jobs", job.ID)
+	// periodically check pipeline status
+	refreshURL, err := url.JoinPath(api, "jobs", "pipeline", status.ID)
 	if err != nil {
-		return err
+		return status, err
 	}
-	log.Info().Msgf("Job started with ID: %s", job.ID)
-	var rawJobStatus []byte
+	log.Info().Msgf("Pipeline started with ID: %s", status.ID)
 	t0 := time.Now()
-	for !job.Finished && time.Since(t0) < maxProcTime {
+	for isPipelineActive(status) && time.Since(t0) < maxProcTime {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return status, ctx.Err()
 		case <-time.After(5 * time.Second):
 		}
-		respData, err := refreshJobStatus(refreshURL, &job)
-		if err != nil {
-			return err
+		if err := getJSON(refreshURL, &status); err != nil {
+			return status, err
 		}
-		rawJobStatus = respData
 	}
 
-	if !job.Finished {
-		return fmt.Errorf("job timeout - took %.2f seconds", time.Since(t0).Seconds())
+	if isPipelineActive(status) {
+		return status, fmt.Errorf("pipeline timeout - took %.2f seconds", time.Since(t0).Seconds())
 	}
-	if !job.OK {
-		return errors.New(job.Error)
+	for _, step := range status.Steps {
+		log.Info().
+			Str("step", step.ID).
+			Str("jobId", step.JobID).
+			Str("status", step.Status).
+			Str("error", step.Error).
+			Msg("pipeline step resolved")
 	}
-
-	if onFinalStatus != nil {
-		if err := onFinalStatus(rawJobStatus); err != nil {
-			return fmt.Errorf("job's onFinalStatus failed: %w", err)
-		}
+	if status.Status != pipeline.StatusFinished {
+		return status, fmt.Errorf("pipeline %s", status.Status)
 	}
+	log.Info().Msg("Pipeline finished successfully")
+	return status, nil
+}
 
-	log.Info().Msg("Job finished successfully")
-	return nil
+func isPipelineActive(status pipeline.Status) bool {
+	return status.Status == pipeline.StatusQueued || status.Status == pipeline.StatusRunning
+}
+
+func getJSON(srcURL string, target any) error {
+	resp, err := http.Get(srcURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(target)
 }
 
 func clearAPIGuardCaches(conf aPIGuardResetConf) error {
@@ -18,7 +18,6 @@ package main
 
 import (
 	"context"
-	"encoding/gob"
 	"flag"
 	"fmt"
 	"net/http"
@@ -39,6 +38,7 @@ import (
 	"frodo/cnf"
 	"frodo/db/mysql"
 	"frodo/debug"
+	"frodo/dictionary"
 	dictActions "frodo/dictionary/actions"
 	"frodo/docs"
 	"frodo/general"
@@ -46,11 +46,16 @@ import (
 	"frodo/keywords"
 	"frodo/liveattrs"
 	laActions "frodo/liveattrs/actions"
+	ladb "frodo/liveattrs/db"
 	"frodo/liveattrs/db/freqdb"
 	"frodo/liveattrs/laconf"
 	"frodo/ltsearch"
 	"frodo/metadb"
+	"frodo/metrics"
+	"frodo/pipeline"
 	"frodo/root"
+	"frodo/scheduler"
+	"frodo/sharedcache"
 	"frodo/ujc/lex"
 	"frodo/ujc/ssjc"
 
@@ -64,8 +69,12 @@ var (
 )
 
 func init() {
-	gob.Register(&liveattrs.LiveAttrsJobInfo{})
-	gob.Register(&freqdb.NgramJobInfo{})
+	jobs.RegisterJobInfoType(&liveattrs.LiveAttrsJobInfo{})
+	jobs.RegisterJobInfoType(&freqdb.NgramJobInfo{})
+	jobs.RegisterJobInfoType(&dictionary.DatasetSizeJobInfo{})
+	jobs.RegisterJobInfoType(&ladb.IndexJobInfo{})
+	jobs.RegisterJobInfoType(&keywords.KeywordsBuildJob{})
+	jobs.RegisterJobInfoType(&jobs.DummyJobInfo{})
 }
 
 // @title           FRODO - Frequency Registry Of Dictionary Objects
@@ -115,6 +124,15 @@ func main() {
 	}
 	log.Info().Msg("Starting FRODO")
 	cnf.ApplyDefaults(conf)
+	if err := conf.Jobs.Validate(); err != nil {
+		log.Fatal().Err(err).Msg("invalid jobs configuration")
+	}
+	if err := conf.Scheduler.Validate(); err != nil {
+		log.Fatal().Err(err).Msg("invalid scheduler configuration")
+	}
+	if err := conf.SharedCache.Validate(); err != nil {
+		log.Fatal().Err(err).Msg("invalid shared cache configuration")
+	}
 
 	docs.SwaggerInfo.Version = version.Version
 	docs.SwaggerInfo.Host = fmt.Sprintf("%s:%d", conf.ListenAddress, conf.ListenPort)
@@ -196,8 +214,20 @@ func main() {
 
 	rootActions := root.Actions{Version: version, Conf: conf}
 
-	jobStopChannel := make(chan string)
-	jobActions := jobs.NewActions(conf.Jobs, conf.Language, ctx, jobStopChannel)
+	jobStore, err := jobs.NewJobStore(conf.Jobs, laDB.DB())
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to initialize job store")
+	}
+	jobActions := jobs.NewActions(
+		conf.Jobs, conf.Language, ctx, jobStore, jobs.NewJobHistory(laDB.DB()))
+	metrics.RegisterJobStats(jobActions)
+	metrics.RegisterDBStats(laDB.DB(), laDB.DBName())
+
+	sharedCache, err := sharedcache.NewCache(ctx, conf.SharedCache)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to initialize shared cache")
+	}
+	defer sharedCache.Close()
 
 	laConfRegistry := laconf.NewLiveAttrsBuildConfProvider(
 		conf.LiveAttrs.ConfDirPath,
@@ -210,39 +240,31 @@ func main() {
 			Corp: conf.CorporaSetup,
 		},
 		ctx,
-		jobStopChannel,
 		jobActions,
 		corpusMeta,
 		corpusMetaW,
 		laDB,
 		laConfRegistry,
+		sharedCache,
 		version,
 	)
 
-	for _, dj := range jobActions.GetDetachedJobs() {
-		if dj.IsFinished() {
-			continue
-		}
-		switch tdj := dj.(type) {
-		case *liveattrs.LiveAttrsJobInfo:
-			err := liveattrsActions.RestartLiveAttrsJob(ctx, tdj)
-			if err != nil {
-				log.Error().Err(err).Msgf("Failed to restart job %s. The job will be removed.", tdj.ID)
-			}
-			jobActions.ClearDetachedJob(tdj.ID)
-		default:
-			log.Error().Msg("unknown detached job type")
-		}
-	}
-
 	engine.GET(
 		"/", rootActions.RootAction)
+	engine.GET(
+		"/metrics", metrics.Handler())
 	engine.GET(
 		"/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	engine.POST(
 		"/liveAttributes/:corpusId/data", liveattrsActions.Create)
 	engine.DELETE(
 		"/liveAttributes/:corpusId/data", liveattrsActions.Delete)
+	engine.PATCH(
+		"/liveAttributes/:corpusId/data", liveattrsActions.UpdateData)
+	engine.POST(
+		"/liveAttributes/:corpusId/data/rollback", liveattrsActions.Rollback)
+	engine.POST(
+		"/liveAttributes/:corpusId/validate", liveattrsActions.Validate)
 	engine.POST(
 		"/liveAttributes/:corpusId/cleanTmpTables", liveattrsActions.CleanTmpTables)
 	engine.GET(
@@ -251,12 +273,20 @@ func main() {
 		"/liveAttributes/:corpusId/conf", liveattrsActions.CreateConf)
 	engine.PATCH(
 		"/liveAttributes/:corpusId/conf", liveattrsActions.PatchConfig)
+	engine.GET(
+		"/liveAttributes/:corpusId/conf/history", liveattrsActions.ConfHistory)
+	engine.GET(
+		"/liveAttributes/:corpusId/conf/history/:version", liveattrsActions.ConfVersion)
+	engine.POST(
+		"/liveAttributes/:corpusId/conf/history/:version/rollback", liveattrsActions.RollbackConf)
 	engine.GET(
 		"/liveAttributes/:corpusId/qsDefaults", liveattrsActions.QSDefaults)
 	engine.DELETE(
 		"/liveAttributes/:corpusId/confCache", liveattrsActions.FlushCache)
 	engine.POST(
 		"/liveAttributes/:corpusId/query", liveattrsActions.Query)
+	engine.POST(
+		"/liveAttributes/:corpusId/distribution", liveattrsActions.Distribution)
 	engine.POST(
 		"/liveAttributes/:corpusId/fillAttrs", liveattrsActions.FillAttrs)
 	engine.POST(
@@ -276,6 +306,22 @@ func main() {
 	engine.POST(
 		"/liveAttributes/:corpusId/mixSubcorpus",
 		liveattrsActions.MixSubcorpus)
+	engine.GET(
+		"/liveAttributes/:corpusId/indexes", liveattrsActions.IndexAdvice)
+	engine.POST(
+		"/liveAttributes/:corpusId/indexes", liveattrsActions.UpdateIndexes)
+	engine.POST(
+		"/liveAttributes/:corpusId/subcdef", liveattrsActions.SubcorpusDefinition)
+	engine.POST(
+		"/liveAttributes/:corpusId/subcorpora", liveattrsActions.CreateSubcorpus)
+	engine.GET(
+		"/liveAttributes/:corpusId/subcorpora", liveattrsActions.ListSubcorpora)
+	engine.GET(
+		"/liveAttributes/:corpusId/subcorpora/:subcId", liveattrsActions.GetSubcorpus)
+	engine.PATCH(
+		"/liveAttributes/:corpusId/subcorpora/:subcId", liveattrsActions.PatchSubcorpus)
+	engine.DELETE(
+		"/liveAttributes/:corpusId/subcorpora/:subcId", liveattrsActions.DeleteSubcorpus)
 	engine.GET(
 		"/liveAttributes/:corpusId/inferredAtomStructure",
 		liveattrsActions.InferredAtomStructure)
@@ -289,13 +335,13 @@ func main() {
 	dictActionsHandler := dictActions.NewActions(
 		ctx,
 		conf.CorporaSetup,
-		jobStopChannel,
 		jobActions,
 		corpusMeta,
 		corpusMetaW,
 		laDB,
 		conf.LiveAttrs.CustomNgramTablesDataDir,
 		laConfRegistry,
+		sharedCache,
 		version,
 	)
 
@@ -304,6 +350,9 @@ func main() {
 	engine.POST(
 		"/dictionary/:corpusId/ngrams",
 		dictActionsHandler.GenerateNgrams)
+	engine.POST(
+		"/dictionary/:corpusId/datasetSize",
+		dictActionsHandler.UpdateDatasetSize)
 	engine.POST(
 		"/dictionary/:corpusId/querySuggestions",
 		dictActionsHandler.CreateQuerySuggestions)
@@ -364,14 +413,34 @@ func main() {
 		keywordsHandler.GetKWOFWeek,
 	)
 
+	pipelineActions := pipeline.NewActions(ctx, jobActions, engine)
+
 	engine.GET(
 		"/jobs", jobActions.JobList)
+	engine.GET(
+		"/jobs/events", jobActions.AllJobEvents)
 	engine.GET(
 		"/jobs/utilization", jobActions.Utilization)
+	engine.GET(
+		"/jobs/history", jobActions.JobHistory)
+	engine.GET(
+		"/jobs/queue", jobActions.JobQueue)
+	engine.DELETE(
+		"/jobs/queue/:jobId", jobActions.CancelQueuedJob)
+	engine.POST(
+		"/jobs/queue/:jobId/moveToFront", jobActions.MoveQueuedJobToFront)
+	engine.POST(
+		"/jobs/queue/:jobId/moveToBack", jobActions.MoveQueuedJobToBack)
+	engine.POST(
+		"/jobs/pipeline", pipelineActions.Submit)
+	engine.GET(
+		"/jobs/pipeline/:pipelineId", pipelineActions.Status)
 	engine.GET(
 		"/jobs/:jobId", jobActions.JobInfo)
 	engine.DELETE(
 		"/jobs/:jobId", jobActions.Delete)
+	engine.GET(
+		"/jobs/:jobId/events", jobActions.JobEvents)
 	engine.GET(
 		"/jobs/:jobId/clearIfFinished", jobActions.ClearIfFinished)
 	engine.GET(
@@ -385,6 +454,12 @@ func main() {
 	engine.DELETE(
 		"/jobs/:jobId/emailNotification/:address",
 		jobActions.RemoveNotification)
+	engine.GET(
+		"/jobs/:jobId/webhookNotification", jobActions.GetWebhooks)
+	engine.PUT(
+		"/jobs/:jobId/webhookNotification", jobActions.AddWebhook)
+	engine.DELETE(
+		"/jobs/:jobId/webhookNotification", jobActions.RemoveWebhook)
 
 	if conf.Logging.Level.IsDebugMode() {
 		debugActions := debug.NewActions(jobActions)
@@ -392,6 +467,27 @@ func main() {
 		engine.POST("/debug/finishJob/:jobId", debugActions.FinishDummyJob)
 	}
 
+	// all the job types have their restart functions registered by now
+	jobActions.RestartDetachedJobs()
+
+	jobScheduler, err := scheduler.NewScheduler(
+		ctx, conf.Scheduler, conf.GetLocation(), scheduler.NewHandlerTrigger(engine))
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to initialize scheduler")
+	}
+	schedulerActions := scheduler.NewActions(jobScheduler)
+	engine.GET(
+		"/schedules", schedulerActions.List)
+	engine.GET(
+		"/schedules/:scheduleId", schedulerActions.Get)
+	engine.POST(
+		"/schedules/:scheduleId/pause", schedulerActions.Pause)
+	engine.POST(
+		"/schedules/:scheduleId/resume", schedulerActions.Resume)
+	engine.POST(
+		"/schedules/:scheduleId/run", schedulerActions.Run)
+	jobScheduler.Start()
+
 	log.Info().Msgf("starting to listen at %s:%d", conf.ListenAddress, conf.ListenPort)
 	srv := &http.Server{
 		Handler:      engine,
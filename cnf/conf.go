@@ -21,6 +21,8 @@ import (
 	"frodo/corpus"
 	"frodo/jobs"
 	"frodo/liveattrs"
+	"frodo/scheduler"
+	"frodo/sharedcache"
 	"frodo/ujc"
 	"os"
 	"path/filepath"
@@ -49,6 +51,8 @@ type Conf struct {
 	CNCDB                  *corpus.DatabaseSetup `json:"cncDb"`
 	LiveAttrs              *liveattrs.Conf       `json:"liveAttrs"`
 	Jobs                   *jobs.Conf            `json:"jobs"`
+	Scheduler              *scheduler.Conf       `json:"scheduler"`
+	SharedCache            *sharedcache.Conf     `json:"sharedCache"`
 	UJC                    ujc.Conf              `json:"ujc"`
 	Language               string                `json:"language"`
 	srcPath                string
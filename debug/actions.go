@@ -17,6 +17,7 @@
 package debug
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
@@ -57,11 +58,15 @@ func (a *Actions) CreateDummyJob(ctx *gin.Context) {
 		jobInfo.Error = fmt.Errorf("dummy error")
 	}
 	finishSignal := make(chan bool)
-	fn := func(upds chan<- jobs.GeneralJobInfo) {
+	fn := func(jctx context.Context, upds chan<- jobs.GeneralJobInfo) {
 		defer close(upds)
-		<-finishSignal
-		jobInfo.Result = &jobs.DummyJobResult{Payload: "Job Done!"}
-		upds <- jobInfo.AsFinished()
+		select {
+		case <-finishSignal:
+			jobInfo.Result = &jobs.DummyJobResult{Payload: "Job Done!"}
+			upds <- jobInfo.AsFinished()
+		case <-jctx.Done():
+			upds <- jobInfo.AsCancelled()
+		}
 	}
 	a.jobActions.EnqueueJob(&fn, jobInfo)
 	a.finishSignals[jobID.String()] = finishSignal
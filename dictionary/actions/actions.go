@@ -20,11 +20,23 @@ import (
 	"context"
 	"frodo/corpus"
 	"frodo/db/mysql"
+	"frodo/dictionary"
 	"frodo/general"
 	"frodo/jobs"
+	"frodo/liveattrs/db/freqdb"
 	"frodo/liveattrs/laconf"
 	"frodo/metadb"
-	"sync"
+	"frodo/sharedcache"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// datasetSizeTTL limits how long a cached dataset size is used.
+	// The cached value is refreshed by the dataset size job but
+	// the dataset_sizes table may be also changed outside Frodo.
+	datasetSizeTTL = time.Hour
 )
 
 type Actions struct {
@@ -35,10 +47,6 @@ type Actions struct {
 	// ctx controls cancellation
 	ctx context.Context
 
-	// jobStopChannel receives job ID based on user interaction with job HTTP API in
-	// case users asks for stopping the vte process
-	jobStopChannel <-chan string
-
 	jobActions *jobs.Actions
 
 	// laDB is a live-attributes-specific database where Frodo needs full privileges
@@ -50,54 +58,51 @@ type Actions struct {
 
 	corpusMetaW metadb.SQLUpdater
 
-	datasetSizesCache map[string]int64
-
-	datasetSizesCacheLock sync.RWMutex
+	// sharedCache stores dataset sizes so all the instances
+	// of the service can share them
+	sharedCache sharedcache.Cache
 }
 
 func (a *Actions) getDatasetSize(name string) (int64, bool) {
-	a.datasetSizesCacheLock.RLock()
-	defer a.datasetSizesCacheLock.RUnlock()
-	v, ok := a.datasetSizesCache[name]
-	return v, ok
+	v, err := sharedcache.GetInt(a.ctx, a.sharedCache, sharedcache.DatasetSizeKey(name))
+	if err != nil && err != sharedcache.ErrorNotFound {
+		log.Error().Err(err).Str("dataset", name).Msg("failed to get cached dataset size")
+	}
+	return v, err == nil
 }
 
 func (a *Actions) setDatasetSize(name string, value int64) {
-	a.datasetSizesCacheLock.Lock()
-	defer a.datasetSizesCacheLock.Unlock()
-	a.datasetSizesCache[name] = value
-}
-
-func (a *Actions) clearDatasetSizes() {
-	a.datasetSizesCacheLock.Lock()
-	defer a.datasetSizesCacheLock.Unlock()
-	a.datasetSizesCache = make(map[string]int64)
+	err := sharedcache.SetInt(a.ctx, a.sharedCache, sharedcache.DatasetSizeKey(name), value, datasetSizeTTL)
+	if err != nil {
+		log.Error().Err(err).Str("dataset", name).Msg("failed to cache dataset size")
+	}
 }
 
 // NewActions is the default factory for Actions
 func NewActions(
 	ctx context.Context,
 	corpConf *corpus.CorporaSetup,
-	jobStopChannel <-chan string,
 	jobActions *jobs.Actions,
 	corpusMeta metadb.Provider,
 	corpusMetaW metadb.SQLUpdater,
 	laDB *mysql.Adapter,
 	laCustomNgramDataDirPath string,
 	laConfRegistry *laconf.LiveAttrsBuildConfProvider,
+	sharedCache sharedcache.Cache,
 	version general.VersionInfo,
 ) *Actions {
 	actions := &Actions{
 		ctx:                      ctx,
 		corpConf:                 corpConf,
 		jobActions:               jobActions,
-		jobStopChannel:           jobStopChannel,
 		laConfCache:              laConfRegistry,
 		corpusMeta:               corpusMeta,
 		corpusMetaW:              corpusMetaW,
 		laDB:                     laDB,
 		laCustomNgramDataDirPath: laCustomNgramDataDirPath,
-		datasetSizesCache:        make(map[string]int64),
+		sharedCache:              sharedCache,
 	}
+	jobActions.RegisterJobFuncFactory(&freqdb.NgramJobInfo{}, actions.restartNgramJob)
+	jobActions.RegisterJobFuncFactory(&dictionary.DatasetSizeJobInfo{}, actions.restartDatasetSizeJob)
 	return actions
 }
@@ -0,0 +1,110 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"frodo/dictionary"
+	"frodo/jobs"
+	"frodo/liveattrs/laconf"
+	"net/http"
+
+	"github.com/czcorpus/cnc-gokit/uniresp"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// restartDatasetSizeJob is a jobs.JobFuncFactory for detached dataset size jobs
+func (a *Actions) restartDatasetSizeJob(job jobs.GeneralJobInfo) (*jobs.JobFunc, error) {
+	switch tJob := job.(type) {
+	case *dictionary.DatasetSizeJobInfo:
+		return a.datasetSizeJobFunc(*tJob), nil
+	case dictionary.DatasetSizeJobInfo:
+		return a.datasetSizeJobFunc(tJob), nil
+	default:
+		return nil, fmt.Errorf("invalid dataset size job type %T", job)
+	}
+}
+
+// datasetSizeJobFunc creates a job function storing a size of a dataset
+// based on its liveattrs data. The liveattrs configuration is loaded once
+// the job runs so the job can follow a job creating the data.
+func (a *Actions) datasetSizeJobFunc(initialStatus dictionary.DatasetSizeJobInfo) *jobs.JobFunc {
+	fn := func(jctx context.Context, updateJobChan chan<- jobs.GeneralJobInfo) {
+		defer close(updateJobChan)
+		laConf, err := a.laConfCache.Get(initialStatus.CorpusID)
+		if err != nil {
+			updateJobChan <- initialStatus.WithError(err)
+			return
+		}
+		groupedName := laConf.Corpus
+		if laConf.ParallelCorpus != "" {
+			groupedName = laConf.ParallelCorpus
+		}
+		size, err := dictionary.UpdateDatasetSize(
+			jctx, a.laDB.DB(), groupedName, laConf.Corpus, initialStatus.CorpusID)
+		status := initialStatus
+		if err != nil {
+			updateJobChan <- status.WithError(err)
+			return
+		}
+		status.Size = size
+		a.setDatasetSize(status.CorpusID, size)
+		log.Info().
+			Str("dataset", status.CorpusID).
+			Int64("size", size).
+			Msg("updated dataset size")
+		updateJobChan <- status.AsFinished()
+	}
+	return &fn
+}
+
+// UpdateDatasetSize godoc
+// @Summary      Update a size of a dataset based on its liveattrs data
+// @Description  Creates a job which calculates a number of tokens of the dataset from its liveattrs data and stores it as the dataset size used for IPM calculation. The size is also stored in the shared cache so all the service instances use the new value.
+// @Produce      json
+// @Param        corpusId path string true "Dataset (corpus or alias) with a liveattrs configuration"
+// @Param        parentJobId query []string false "Run the job once the specified jobs finish" collectionFormat(multi)
+// @Success      200 {object} any
+// @Failure      404 {object} uniresp.ActionError
+// @Router       /dictionary/{corpusId}/datasetSize [post]
+func (a *Actions) UpdateDatasetSize(ctx *gin.Context) {
+	corpusID := ctx.Param("corpusId")
+	// the configuration may be still created by a parent job
+	// so we only check its existence if there are no parents
+	parentJobIDs := ctx.QueryArray("parentJobId")
+	if len(parentJobIDs) == 0 {
+		if _, err := a.laConfCache.Get(corpusID); err == laconf.ErrorNoSuchConfig {
+			uniresp.RespondWithErrorJSON(ctx, err, http.StatusNotFound)
+			return
+
+		} else if err != nil {
+			uniresp.RespondWithErrorJSON(ctx, err, http.StatusInternalServerError)
+			return
+		}
+	}
+	status := &dictionary.DatasetSizeJobInfo{
+		ID:       uuid.New().String(),
+		Type:     dictionary.DatasetSizeJobType,
+		CorpusID: corpusID,
+		Start:    jobs.CurrentDatetime(),
+		Update:   jobs.CurrentDatetime(),
+	}
+	a.jobActions.EnqueueJobAfterAll(a.datasetSizeJobFunc(*status), status, parentJobIDs)
+	uniresp.WriteJSONResponse(ctx.Writer, status.FullInfo())
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"frodo/corpus"
 	"frodo/db/mysql"
+	"frodo/jobs"
 	"frodo/liveattrs/db/freqdb"
 	"frodo/liveattrs/laconf"
 	"io"
@@ -88,11 +89,14 @@ func mergeAliasedConfig(orig, aliased *cnf.VTEConf) *cnf.VTEConf {
 }
 
 type NGramsReqArgs struct {
-	ColMapping            *corpus.QSAttributes `json:"colMapping,omitempty"`
-	PosTagset             corp.SupportedTagset `json:"posTagset"`
-	UsePartitionedTable   bool                 `json:"usePartitionedTable"`
-	MinFreq               int                  `json:"minFreq"`
-	SkipGroupedNameSearch bool                 `json:"skipGroupedNameSearch"`
+	ColMapping          *corpus.QSAttributes `json:"colMapping,omitempty"`
+	PosTagset           corp.SupportedTagset `json:"posTagset"`
+	UsePartitionedTable bool                 `json:"usePartitionedTable"`
+
+	// MinFreq specifies a minimum absolute frequency of n-grams
+	// to be included in the generated data (0 = no limit)
+	MinFreq               int  `json:"minFreq"`
+	SkipGroupedNameSearch bool `json:"skipGroupedNameSearch"`
 }
 
 func (args NGramsReqArgs) Validate() error {
@@ -133,6 +137,7 @@ func (a *Actions) getNgramArgs(req *http.Request) (NGramsReqArgs, error) {
 // @Param        corpusId path string true "Used corpus"
 // @Param        append query int false "Append mode" default(0)
 // @Param        ngramSize query int false "N-gram size" default(1)
+// @Param        parentJobId query []string false "Run the job once the specified jobs finish" collectionFormat(multi)
 // @Success      200 {object} any
 // @Router       /dictionary/{corpusId}/ngrams [post]
 func (a *Actions) GenerateNgrams(ctx *gin.Context) {
@@ -338,14 +343,54 @@ func (a *Actions) GenerateNgrams(ctx *gin.Context) {
 		args.UsePartitionedTable,
 		appendMode,
 		ngramSize,
+		laConf.Ngrams,
+		tagset,
 		posFn,
 		*args.ColMapping,
 		args.MinFreq,
 	)
-	jobInfo, err := generator.GenerateAfter(ctx.Request.URL.Query().Get("parentJobId"))
+	jobInfo, err := generator.GenerateAfter(ctx.QueryArray("parentJobId"))
 	if err != nil {
 		uniresp.RespondWithErrorJSON(ctx, err, http.StatusInternalServerError)
 		return
 	}
 	uniresp.WriteJSONResponse(ctx.Writer, jobInfo.FullInfo())
 }
+
+// restartNgramJob is a jobs.JobFuncFactory for detached ngram jobs
+func (a *Actions) restartNgramJob(job jobs.GeneralJobInfo) (*jobs.JobFunc, error) {
+	var jobInfo freqdb.NgramJobInfo
+	switch tJob := job.(type) {
+	case *freqdb.NgramJobInfo:
+		jobInfo = *tJob
+	case freqdb.NgramJobInfo:
+		jobInfo = tJob
+	default:
+		return nil, fmt.Errorf("invalid ngram job type %T", job)
+	}
+	args := jobInfo.Args
+	posFn, err := corpus.ApplyPosProperties(&args.NgramConf, args.ColMapping.Tag, args.PosTagset)
+	if err != nil {
+		return nil, err
+	}
+	tunedDb, err := mysql.OpenImportTunedDB(a.laDB.Conf())
+	if err != nil {
+		return nil, err
+	}
+	generator := freqdb.NewNgramFreqGenerator(
+		tunedDb,
+		a.jobActions,
+		args.GroupedName,
+		jobInfo.CorpusID,
+		a.laCustomNgramDataDirPath,
+		args.UsePartitionedTable,
+		args.AppendExisting,
+		args.NgramSize,
+		args.NgramConf,
+		args.PosTagset,
+		posFn,
+		args.ColMapping,
+		args.MinFreq,
+	)
+	return generator.JobFunc(jobInfo), nil
+}
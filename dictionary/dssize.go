@@ -0,0 +1,57 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dictionary
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// UpdateDatasetSize calculates a number of tokens of a corpus
+// from its liveattrs data (the liveattrs table is derived from
+// groupedName) and stores the value as a size of the dataset
+// (see SearchWithDatasetSizeForIPM). The stored size is returned.
+func UpdateDatasetSize(
+	ctx context.Context,
+	db *sql.DB,
+	groupedName, corpusID, datasetName string,
+) (int64, error) {
+	var size int64
+	row := db.QueryRowContext(
+		ctx,
+		fmt.Sprintf(
+			"SELECT COALESCE(SUM(poscount), 0) FROM `%s_liveattrs_entry` WHERE corpus_id = ?",
+			groupedName,
+		),
+		corpusID,
+	)
+	if err := row.Scan(&size); err != nil {
+		return 0, fmt.Errorf("failed to calculate size of dataset %s: %w", datasetName, err)
+	}
+	if size == 0 {
+		return 0, fmt.Errorf("failed to calculate size of dataset %s: no liveattrs data", datasetName)
+	}
+	_, err := db.ExecContext(
+		ctx,
+		"INSERT INTO dataset_sizes (name, size) VALUES (?, ?) ON DUPLICATE KEY UPDATE size = ?",
+		datasetName, size, size,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to store size of dataset %s: %w", datasetName, err)
+	}
+	return size, nil
+}
@@ -18,7 +18,9 @@ package jobs
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"frodo/metrics"
 	"net/http"
 	"reflect"
 	"slices"
@@ -31,10 +33,17 @@ import (
 	"github.com/rs/zerolog/log"
 	"golang.org/x/text/message"
 
-	"github.com/czcorpus/cnc-gokit/fs"
 	"github.com/czcorpus/cnc-gokit/uniresp"
 )
 
+var (
+	ErrorJobAlreadyFinished = errors.New("job already finished")
+	ErrorJobNotFound        = errors.New("job not found")
+	ErrorJobInterrupted     = errors.New("job interrupted by server shutdown")
+	ErrorJobNotRestartable  = errors.New("no restart handler registered for the job type")
+	ErrorFailedParent       = errors.New("failed parent(s)")
+)
+
 const (
 	tableActionUpdateJob = iota
 	tableActionFinishJob
@@ -49,6 +58,16 @@ type TableUpdate struct {
 	data   GeneralJobInfo
 }
 
+// jobContext holds a context of a running job
+type jobContext struct {
+	ctx             context.Context
+	cancel          context.CancelFunc
+	cancelRequested bool
+
+	// fn is the job's function (if any) so it can be retried
+	fn *QueuedFunc
+}
+
 // Actions contains async job-related actions
 type Actions struct {
 	ctx              context.Context
@@ -60,14 +79,41 @@ type Actions struct {
 	jobQueue         *JobQueue
 	jobQueueLock     sync.Mutex
 	jobDeps          JobsDeps
-	jobStop          chan<- string
-	msgPrinter       *message.Printer
+	jobDepsLock      sync.Mutex
+	jobContexts      map[string]*jobContext
+	jobContextsLock  sync.Mutex
+
+	// awaitingRetry contains IDs of failed jobs enqueued to be run
+	// again. Such jobs are unfinished but they are not running.
+	// The map is guarded by jobListLock.
+	awaitingRetry map[string]bool
+
+	store      JobStore
+	history    JobHistory
+	msgPrinter *message.Printer
+
+	// detachedParents contains parent job IDs of detached jobs
+	// which had been enqueued (but not started) before shutdown
+	detachedParents map[string][]string
+
+	// jobFuncFactories contains registered factories (key = job info kind)
+	// used to restart detached jobs. Factories are expected to be registered
+	// during the server startup.
+	jobFuncFactories map[string]JobFuncFactory
 
 	// tableUpdate represents a single "point" through which jobs
 	// are updated
 	tableUpdate chan TableUpdate
 
+	// events distributes job changes to clients listening for them
+	events *EventBroker
+
 	notificationRecipients map[string][]string
+
+	// webhooks contains per-job webhooks (key = job ID)
+	webhooks      map[string][]WebhookConf
+	webhooksLock  sync.Mutex
+	webhookSender *webhookSender
 }
 
 func (a *Actions) TestAllowsJobRestart(jinfo GeneralJobInfo) error {
@@ -77,6 +123,19 @@ func (a *Actions) TestAllowsJobRestart(jinfo GeneralJobInfo) error {
 	return nil
 }
 
+// storeJob writes the current job state to the job store
+func (a *Actions) storeJob(job GeneralJobInfo) {
+	if err := a.store.Save(job); err != nil {
+		log.Error().Err(err).Str("jobId", job.GetID()).Msg("failed to store job")
+	}
+}
+
+func (a *Actions) removeStoredJob(jobID string) {
+	if err := a.store.Remove(jobID); err != nil {
+		log.Error().Err(err).Str("jobId", jobID).Msg("failed to remove stored job")
+	}
+}
+
 func (a *Actions) createJobList(unfinishedOnly bool) JobInfoList {
 	a.jobListLock.RLock()
 	defer a.jobListLock.RUnlock()
@@ -101,23 +160,146 @@ func (a *Actions) HasRunningJobs() bool {
 	return false
 }
 
-func (a *Actions) EnqueueJob(fn *QueuedFunc, initialStatus GeneralJobInfo) {
+// wrapJobFunc creates a queue-compatible function which passes
+// the job's context (created once the job is registered) to the
+// original job function.
+func (a *Actions) wrapJobFunc(jobID string, fn *JobFunc) *QueuedFunc {
+	wrapped := func(updates chan<- GeneralJobInfo) {
+		(*fn)(a.runningJobContext(jobID), updates)
+	}
+	return &wrapped
+}
+
+func (a *Actions) runningJobContext(jobID string) context.Context {
+	a.jobContextsLock.Lock()
+	defer a.jobContextsLock.Unlock()
+	jc, ok := a.jobContexts[jobID]
+	if !ok {
+		log.Warn().Str("jobId", jobID).Msg("job context not found, using an uncancellable one")
+		return context.Background()
+	}
+	return jc.ctx
+}
+
+// releaseJobContext removes the context of a finished job
+// and returns it (or nil if there is no such context)
+func (a *Actions) releaseJobContext(jobID string) *jobContext {
+	a.jobContextsLock.Lock()
+	defer a.jobContextsLock.Unlock()
+	jc, ok := a.jobContexts[jobID]
+	if !ok {
+		return nil
+	}
+	jc.cancel()
+	delete(a.jobContexts, jobID)
+	return jc
+}
+
+func (a *Actions) EnqueueJob(jobFn *JobFunc, initialStatus GeneralJobInfo) {
+	a.enqueue(jobFn, initialStatus, []string{})
+	log.Info().Msgf("Enqueued job %s", initialStatus.GetID())
+}
+
+func (a *Actions) EqueueJobAfter(jobFn *JobFunc, initialStatus GeneralJobInfo, parentJobID string) {
+	a.enqueue(jobFn, initialStatus, []string{parentJobID})
+	log.Info().Msgf("Enqueued job %s with parent %s", initialStatus.GetID(), parentJobID)
+}
+
+// EnqueueJobAfterAll enqueues a job which will run once all the
+// parent jobs finish. In case there are no parents, the job
+// is enqueued the same way as with EnqueueJob. Empty parent IDs
+// are ignored.
+func (a *Actions) EnqueueJobAfterAll(jobFn *JobFunc, initialStatus GeneralJobInfo, parentJobIDs []string) {
+	parentJobIDs = slices.DeleteFunc(slices.Clone(parentJobIDs), func(v string) bool { return v == "" })
+	a.enqueue(jobFn, initialStatus, parentJobIDs)
+	log.Info().
+		Strs("parents", parentJobIDs).
+		Msgf("Enqueued job %s", initialStatus.GetID())
+}
+
+// enqueue adds a job to the queue. Dependencies are set before
+// the job is enqueued so it cannot be dispatched sooner than its
+// parents finish.
+func (a *Actions) enqueue(jobFn *JobFunc, initialStatus GeneralJobInfo, parentJobIDs []string) {
+	for _, parentID := range parentJobIDs {
+		a.jobDepsLock.Lock()
+		err := a.jobDeps.Add(initialStatus.GetID(), parentID)
+		a.jobDepsLock.Unlock()
+		if err != nil {
+			log.Error().
+				Err(err).
+				Str("jobId", initialStatus.GetID()).
+				Str("parentJobId", parentID).
+				Msg("failed to add job dependency")
+			continue
+		}
+		a.resolveParentState(parentID)
+	}
+	if err := a.store.SaveQueued(initialStatus, parentJobIDs); err != nil {
+		log.Error().Err(err).Str("jobId", initialStatus.GetID()).Msg("failed to store queued job")
+	}
+	fn := a.wrapJobFunc(initialStatus.GetID(), jobFn)
 	a.jobQueueLock.Lock()
-	a.jobQueue.Enqueue(fn, initialStatus)
+	a.jobQueue.EnqueueWithPriority(
+		fn, initialStatus, a.conf.TypeConf(initialStatus.GetType()).Priority)
 	a.jobQueueLock.Unlock()
-	log.Info().Msgf("Enqueued job %s", initialStatus.GetID())
 }
 
-func (a *Actions) EqueueJobAfter(fn *QueuedFunc, initialStatus GeneralJobInfo, parentJobID string) {
+// resolveParentState makes sure a dependency on an already finished
+// (or unknown) parent won't block a child job forever. A parent
+// which is neither known, queued nor detached is considered failed.
+func (a *Actions) resolveParentState(parentID string) {
+	if parent, ok := a.GetJob(parentID); ok {
+		if parent.IsFinished() {
+			a.setParentFinished(parentID, parent.GetError() != nil || parent.IsCancelled())
+		}
+		return
+	}
 	a.jobQueueLock.Lock()
-	a.jobQueue.Enqueue(fn, initialStatus)
+	queued := a.jobQueue.Contains(parentID)
 	a.jobQueueLock.Unlock()
-	a.jobDeps.Add(initialStatus.GetID(), parentJobID)
-	log.Info().Msgf("Enqueued job %s with parent %s", initialStatus.GetID(), parentJobID)
+	a.detachedJobsLock.Lock()
+	_, detached := a.detachedJobs[parentID]
+	a.detachedJobsLock.Unlock()
+	if !queued && !detached {
+		log.Warn().Str("parentJobId", parentID).Msg("unknown parent job, considering it failed")
+		a.setParentFinished(parentID, true)
+	}
+}
+
+// setParentFinished marks all the dependencies on the parent job
+// as resolved. All the access to jobDeps must go through jobDepsLock
+// as it is written by HTTP handlers, restored jobs and the dispatcher.
+func (a *Actions) setParentFinished(parentID string, hasError bool) {
+	a.jobDepsLock.Lock()
+	a.jobDeps.SetParentFinished(parentID, hasError)
+	a.jobDepsLock.Unlock()
 }
 
-func (a *Actions) dequeueAndRunJob() {
-	fn, initState, err := a.jobQueue.Dequeue()
+// dependencyState reports whether the job must still wait for
+// its parents and whether any of the parents failed.
+func (a *Actions) dependencyState(jobID string) (mustWait bool, hasFailedParent bool, err error) {
+	a.jobDepsLock.Lock()
+	defer a.jobDepsLock.Unlock()
+	if _, ok := a.jobDeps[jobID]; !ok { // job without dependencies
+		return false, false, nil
+	}
+	mustWait, err = a.jobDeps.MustWait(jobID)
+	if err != nil {
+		return false, false, fmt.Errorf("failed to obtain waiting status for job %s: %w", jobID, err)
+	}
+	if mustWait {
+		return true, false, nil
+	}
+	hasFailedParent, err = a.jobDeps.HasFailedParent(jobID)
+	if err != nil {
+		return false, false, fmt.Errorf("failed to check parents of job %s: %w", jobID, err)
+	}
+	return false, hasFailedParent, nil
+}
+
+func (a *Actions) dequeueAndRunJob(jobID string) {
+	fn, initState, err := a.jobQueue.Remove(jobID)
 	if err == nil {
 		log.Info().
 			Float32(
@@ -128,7 +310,7 @@ func (a *Actions) dequeueAndRunJob() {
 			Str("jobType", initState.GetType()).
 			Str("corpus", initState.GetCorpus()).
 			Msgf("Dequeued a new job")
-		updateJobChan := a.registerJob(initState)
+		updateJobChan := a.registerJob(initState, fn)
 		go func() {
 			(*fn)(updateJobChan)
 		}()
@@ -139,29 +321,127 @@ func (a *Actions) dequeueAndRunJob() {
 // run a job e.g. because of a failed dependency (= other job).
 // But we still need to respect basic workflow so we dequeue
 // the job, set the status and send it via a respective channel.
-func (a *Actions) dequeueJobAsFailed(err error) {
-	_, initState, _ := a.jobQueue.Dequeue()
+func (a *Actions) dequeueJobAsFailed(jobID string, err error) {
+	_, initState, rmErr := a.jobQueue.Remove(jobID)
+	if rmErr != nil {
+		log.Error().Err(rmErr).Str("jobId", jobID).Msg("failed to dequeue job")
+		return
+	}
 	finalState := initState.WithError(err)
-	updateJobChan := a.registerJob(finalState)
+	updateJobChan := a.registerJob(finalState, nil)
 	updateJobChan <- finalState.AsFinished()
+	close(updateJobChan)
 	log.Error().Err(err).Send()
 }
 
+// dequeueJobAsCancelled removes a job from the queue and registers
+// it as cancelled (so any dependent jobs won't run).
+func (a *Actions) dequeueJobAsCancelled(jobID string) error {
+	_, initState, err := a.jobQueue.Remove(jobID)
+	if err != nil {
+		return err
+	}
+	finalState := initState.AsCancelled()
+	updateJobChan := a.registerJob(finalState, nil)
+	updateJobChan <- finalState
+	close(updateJobChan)
+	log.Info().Str("jobId", jobID).Msg("cancelled queued job")
+	return nil
+}
+
+// CancelJob cancels either a queued job (which is then registered
+// as cancelled right away) or a running job (in such case its context
+// is cancelled and the job is marked as cancelled once it stops).
+func (a *Actions) CancelJob(jobID string) error {
+	a.jobQueueLock.Lock()
+	if a.jobQueue.Contains(jobID) {
+		err := a.dequeueJobAsCancelled(jobID)
+		a.jobQueueLock.Unlock()
+		return err
+	}
+	a.jobQueueLock.Unlock()
+
+	job, ok := a.GetJob(jobID)
+	if !ok {
+		return ErrorJobNotFound
+	}
+	if job.IsFinished() {
+		return ErrorJobAlreadyFinished
+	}
+	a.jobContextsLock.Lock()
+	defer a.jobContextsLock.Unlock()
+	jc, ok := a.jobContexts[jobID]
+	if !ok {
+		return ErrorJobAlreadyFinished
+	}
+	jc.cancelRequested = true
+	jc.cancel()
+	log.Info().Str("jobId", jobID).Msg("requested job cancellation")
+	return nil
+}
+
+// scheduleRetry enqueues a failed job to be run again in case
+// its type's retry policy allows it. Until the job is dispatched,
+// it is kept in the job table as unfinished (but not running).
+func (a *Actions) scheduleRetry(job GeneralJobInfo, fn *QueuedFunc) bool {
+	tc := a.conf.TypeConf(job.GetType())
+	attempt := job.GetNumRestarts() + 1
+	if fn == nil || job.IsCancelled() || attempt >= tc.Retry.MaxAttempts ||
+		!tc.Retry.IsRetryable(job.GetError()) {
+		return false
+	}
+	if !isRetrySafe(job) {
+		log.Warn().
+			Err(job.GetError()).
+			Str("jobId", job.GetID()).
+			Msg("job failed, not retrying a non-idempotent job")
+		return false
+	}
+	delay := tc.Retry.Backoff(attempt)
+	retried := job.AsRetry()
+	func() {
+		a.jobListLock.Lock()
+		defer a.jobListLock.Unlock()
+		a.jobList[job.GetID()] = retried
+		a.awaitingRetry[job.GetID()] = true
+	}()
+	if err := a.store.SaveQueued(retried, []string{}); err != nil {
+		log.Error().Err(err).Str("jobId", job.GetID()).Msg("failed to store queued job")
+	}
+	a.jobQueueLock.Lock()
+	a.jobQueue.EnqueueDelayed(fn, retried, tc.Priority, time.Now().Add(delay))
+	a.jobQueueLock.Unlock()
+	log.Warn().
+		Err(job.GetError()).
+		Str("jobId", job.GetID()).
+		Int("attempt", attempt).
+		Float64("backoff", delay.Seconds()).
+		Msg("job failed, scheduled retry")
+	return true
+}
+
 // registerJob adds a new job to the job table and provides
-// a channel to update its status
-func (a *Actions) registerJob(j GeneralJobInfo) chan GeneralJobInfo {
+// a channel to update its status. The fn argument is the job's
+// function (if any) which is kept for possible retries.
+func (a *Actions) registerJob(j GeneralJobInfo, fn *QueuedFunc) chan GeneralJobInfo {
+	a.detachedJobsLock.Lock()
 	_, ok := a.detachedJobs[j.GetID()]
+	delete(a.detachedJobs, j.GetID())
+	a.detachedJobsLock.Unlock()
 	if ok {
 		log.Info().Msgf("Registering again detached job %s", j.GetID())
-		a.detachedJobsLock.Lock()
-		delete(a.detachedJobs, j.GetID())
-		a.detachedJobsLock.Unlock()
 	}
 	func() {
 		a.jobListLock.Lock()
 		defer a.jobListLock.Unlock()
 		a.jobList[j.GetID()] = j
+		delete(a.awaitingRetry, j.GetID())
 	}()
+	a.storeJob(j)
+	jctx, cancel := context.WithCancel(a.ctx)
+	a.jobContextsLock.Lock()
+	a.jobContexts[j.GetID()] = &jobContext{ctx: jctx, cancel: cancel, fn: fn}
+	a.jobContextsLock.Unlock()
 	syncUpdates := make(chan GeneralJobInfo, 100)
 	go func() {
 		var item GeneralJobInfo
@@ -245,26 +525,39 @@ func (a *Actions) JobInfo(ctx *gin.Context) {
 }
 
 // Delete godoc
-// @Summary      Delete existing job
+// @Summary      Cancel a queued or running job
+// @Description  A queued job is cancelled immediately. A running job is asked to stop and it is marked as cancelled once it stops and cleans up its partial output.
 // @Produce      json
 // @Param        jobId path string true "Job ID"
-// @Param        compact query int false "Get compact info" default(0)
-// @Success      200 {object} GeneralJobInfo
+// @Success      200 {object} any
 // @Failure      404 {object} uniresp.ActionError
+// @Failure      409 {object} uniresp.ActionError
 // @Router       /jobs/{jobId} [delete]
 func (a *Actions) Delete(ctx *gin.Context) {
+	jobID := ctx.Param("jobId")
 	job := func() GeneralJobInfo {
 		a.jobListLock.RLock()
 		defer a.jobListLock.RUnlock()
-		return FindJob(a.jobList, ctx.Param("jobId"))
+		return FindJob(a.jobList, jobID)
 	}()
 	if job != nil {
-		a.jobStop <- job.GetID()
-		uniresp.WriteJSONResponse(ctx.Writer, job)
-
-	} else {
+		jobID = job.GetID()
+	}
+	err := a.CancelJob(jobID)
+	if err == ErrorJobNotFound || err == ErrorNotQueued {
 		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError("job not found"), http.StatusNotFound)
+		return
+
+	} else if err == ErrorJobAlreadyFinished {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError("job already finished"), http.StatusConflict)
+		return
+
+	} else if err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError("failed to cancel job: %w", err), http.StatusInternalServerError)
+		return
 	}
+	job, _ = a.GetJob(jobID)
+	uniresp.WriteJSONResponse(ctx.Writer, job.FullInfo())
 }
 
 // ClearIfFinished godoc
@@ -281,6 +574,9 @@ func (a *Actions) ClearIfFinished(ctx *gin.Context) {
 		return ClearFinishedJob(a.jobList, ctx.Param("jobId"))
 	}()
 	if job != nil {
+		if removed {
+			a.removeStoredJob(job.GetID())
+		}
 		uniresp.WriteJSONResponse(ctx.Writer, map[string]any{"removed": removed, "jobInfo": job})
 
 	} else {
@@ -291,21 +587,17 @@ func (a *Actions) ClearIfFinished(ctx *gin.Context) {
 func (a *Actions) goWaitExit() {
 	go func() {
 		<-a.ctx.Done()
-		if a.conf.StatusDataPath != "" {
-			log.Info().Msgf("saving state to %s", a.conf.StatusDataPath)
-			jobList := a.createJobList(true)
-			err := jobList.Serialize(a.conf.StatusDataPath)
-			if err != nil {
-				log.Error().Err(err)
-			}
-
-		} else {
-			log.Warn().Msg("no status file specified, discarding job list")
+		// all the job changes are already stored, so we just
+		// make sure nothing is left unflushed
+		if err := a.store.Close(); err != nil {
+			log.Error().Err(err).Msg("failed to close job store")
 		}
 	}()
 }
 
 func (a *Actions) GetDetachedJobs() []GeneralJobInfo {
+	a.detachedJobsLock.Lock()
+	defer a.detachedJobsLock.Unlock()
 	ans := make([]GeneralJobInfo, len(a.detachedJobs))
 	i := 0
 	for _, v := range a.detachedJobs {
@@ -315,26 +607,99 @@ func (a *Actions) GetDetachedJobs() []GeneralJobInfo {
 	return ans
 }
 
+// ClearDetachedJob removes a detached job. In case the job
+// has not been enqueued again (i.e. restarted), it is also
+// removed from the job store.
 func (a *Actions) ClearDetachedJob(jobID string) bool {
 	a.detachedJobsLock.Lock()
-	defer a.detachedJobsLock.Unlock()
 	_, ok := a.detachedJobs[jobID]
 	delete(a.detachedJobs, jobID)
+	a.detachedJobsLock.Unlock()
+	if ok {
+		_, registered := a.GetJob(jobID)
+		a.jobQueueLock.Lock()
+		enqueued := a.jobQueue.Contains(jobID)
+		a.jobQueueLock.Unlock()
+		if !registered && !enqueued {
+			a.removeStoredJob(jobID)
+		}
+	}
 	return ok
 }
 
+// dispatchNextJob picks the highest-priority job which can be run
+// (i.e. there is a free slot for its type and all its parents are
+// finished) and runs it. Jobs with failed parents are dequeued as failed.
+// At most one job is dispatched (or dequeued as failed) per call.
+func (a *Actions) dispatchNextJob() {
+	a.jobQueueLock.Lock()
+	defer a.jobQueueLock.Unlock()
+	// Now calling again the numOfUnfinishedJobs() may return
+	// different value but it can be only a value smaller than
+	// numUnfinished as the change can be only caused by another
+	// job being finished (adding of jobs for execution happens
+	// only here and is not concurrent).
+	if a.conf.MaxNumConcurrentJobs <= a.numOfUnfinishedJobs() {
+		return
+	}
+	runningByType := a.numOfUnfinishedJobsByType()
+	now := time.Now()
+	for _, job := range a.jobQueue.ByPriority() {
+		jobID := job.GetID()
+		if notBefore, _ := a.jobQueue.NotBefore(jobID); now.Before(notBefore) {
+			continue
+		}
+		typeLimit := a.conf.TypeConf(job.GetType()).MaxNumConcurrentJobs
+		if typeLimit > 0 && runningByType[job.GetType()] >= typeLimit {
+			continue
+		}
+		mustWait, hasFailedParent, err := a.dependencyState(jobID)
+		if err != nil {
+			a.dequeueJobAsFailed(jobID, err)
+			return
+
+		} else if mustWait {
+			continue
+
+		} else if hasFailedParent {
+			err := fmt.Errorf("failed to run job %s due to %w", jobID, ErrorFailedParent)
+			a.dequeueJobAsFailed(jobID, err)
+			return
+		}
+		a.dequeueAndRunJob(jobID)
+		return
+	}
+}
+
 func (a *Actions) numOfUnfinishedJobs() int {
 	a.jobListLock.RLock()
 	defer a.jobListLock.RUnlock()
 	ans := 0
 	for _, v := range a.jobList {
-		if !v.IsFinished() {
+		if !v.IsFinished() && !a.awaitingRetry[v.GetID()] {
 			ans++
 		}
 	}
 	return ans
 }
 
+// RunningJobsByType returns numbers of running jobs (key = job type)
+func (a *Actions) RunningJobsByType() map[string]int {
+	return a.numOfUnfinishedJobsByType()
+}
+
+func (a *Actions) numOfUnfinishedJobsByType() map[string]int {
+	a.jobListLock.RLock()
+	defer a.jobListLock.RUnlock()
+	ans := make(map[string]int)
+	for _, v := range a.jobList {
+		if !v.IsFinished() && !a.awaitingRetry[v.GetID()] {
+			ans[v.GetType()]++
+		}
+	}
+	return ans
+}
+
 func (a *Actions) LastUnfinishedJobOfType(datasetID string, jobType string) (GeneralJobInfo, bool) {
 	var tmp GeneralJobInfo
 	a.jobListLock.RLock()
@@ -520,6 +885,8 @@ func (a *Actions) Utilization(ctx *gin.Context) {
 		"currentRunningJobs":   numUnfinished,
 		"utilization":          float32(numUnfinished) / float32(a.conf.MaxNumConcurrentJobs),
 		"jobQueueLength":       a.jobQueue.Size(),
+		"runningJobsByType":    a.numOfUnfinishedJobsByType(),
+		"jobTypeLimits":        a.conf.JobTypes,
 	}
 	uniresp.WriteJSONResponse(ctx.Writer, ans)
 }
@@ -529,38 +896,49 @@ func NewActions(
 	conf *Conf,
 	lang string,
 	ctx context.Context,
-	jobStop chan<- string,
+	store JobStore,
+	history JobHistory,
 ) *Actions {
 	ans := &Actions{
 		conf:                   conf,
 		jobList:                make(map[string]GeneralJobInfo),
 		detachedJobs:           make(map[string]GeneralJobInfo),
 		tableUpdate:            make(chan TableUpdate),
-		jobStop:                jobStop,
+		events:                 NewEventBroker(),
+		jobContexts:            make(map[string]*jobContext),
+		awaitingRetry:          make(map[string]bool),
+		jobFuncFactories:       make(map[string]JobFuncFactory),
 		notificationRecipients: make(map[string][]string),
+		webhooks:               make(map[string][]WebhookConf),
+		webhookSender:          newWebhookSender(ctx, conf.Webhooks),
 		msgPrinter:             message.NewPrinter(message.MatchLanguage(lang)),
 		jobQueue:               &JobQueue{},
 		jobDeps:                make(JobsDeps),
+		store:                  store,
+		history:                history,
 		ctx:                    ctx,
 	}
 	ans.goWaitExit()
-	isFile, err := fs.IsFile(conf.StatusDataPath)
+	storedJobs, err := store.LoadAll()
 	if err != nil {
-		log.Error().Err(err)
+		log.Error().Err(err).Msg("failed to load stored jobs")
 	}
-	if isFile {
-		log.Info().Msgf("found status data in %s - loading...", conf.StatusDataPath)
-		jobs, err := LoadJobList(conf.StatusDataPath)
-		if err != nil {
-			log.Error().Err(err).Msg("failed to load status data")
-		}
-		for _, job := range jobs {
-			if job != nil {
-				ans.detachedJobs[job.GetID()] = job
-				log.Info().Msgf("added detached job %s", job.GetID())
-			}
+	for _, job := range storedJobs {
+		if job.IsFinished() {
+			ans.jobList[job.GetID()] = job
+
+		} else {
+			ans.detachedJobsLock.Lock()
+			ans.detachedJobs[job.GetID()] = job
+			ans.detachedJobsLock.Unlock()
+			log.Info().Msgf("added detached job %s", job.GetID())
 		}
 	}
+	ans.detachedParents, err = store.LoadQueued()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to load stored queued jobs")
+		ans.detachedParents = make(map[string][]string)
+	}
 
 	// here we listen for context Done() and clean finished
 	// jobs info regularly
@@ -584,54 +962,9 @@ func NewActions(
 		for {
 			select {
 			case <-ticker2.C:
-				func() {
-					ans.jobQueueLock.Lock()
-					defer ans.jobQueueLock.Unlock()
-					numUnfinished := ans.numOfUnfinishedJobs()
-					// Now calling again the numOfUnfinishedJobs() may return
-					// different value but it can be only a value smaller than
-					// numUnfinished as the change can be only caused by another
-					// job being finished (adding of jobs for execution happens
-					// only here and is not concurrent).
-					if ans.conf.MaxNumConcurrentJobs > numUnfinished {
-						// first, let's check whether the current job depends
-						// on other job(s) (= aka 'parents') and delay it in case
-						// parents are not ready yet
-						nextJobID, err := ans.jobQueue.PeekID()
-						if err != nil {
-							// empty queue
-						} else if _, ok := ans.jobDeps[nextJobID]; ok { // job with dependencies
-
-							mustWait, err := ans.jobDeps.MustWait(nextJobID)
-							if err != nil {
-								err := fmt.Errorf("failed to obtain waiting status for job %s: %w", nextJobID, err)
-								ans.dequeueJobAsFailed(err)
-
-							} else if mustWait {
-								ans.jobQueue.DelayNext()
-
-							} else {
-								hasFailedParent, err := ans.jobDeps.HasFailedParent(nextJobID)
-								if err != nil {
-									err := fmt.Errorf("failed to check parents of job %s: %w", nextJobID, err)
-									ans.dequeueJobAsFailed(err)
-
-								} else if hasFailedParent {
-									err := fmt.Errorf("failed to run job %s due to failed parent(s): %w", nextJobID, err)
-									ans.dequeueJobAsFailed(err)
-
-								} else {
-									ans.dequeueAndRunJob()
-								}
-							}
-
-						} else { // job without deps
-							ans.dequeueAndRunJob()
-						}
-					}
-				}()
+				ans.dispatchNextJob()
 			case <-ctx.Done():
-				ticker.Stop()
+				ticker2.Stop()
 				return
 			}
 		}
@@ -641,44 +974,81 @@ func NewActions(
 		for upd := range ans.tableUpdate {
 			switch upd.action {
 			case tableActionUpdateJob:
-				func() {
+				updated := func() GeneralJobInfo {
 					ans.jobListLock.Lock()
 					defer ans.jobListLock.Unlock()
 					curr, ok := ans.jobList[upd.itemID]
 					if !ok {
 						log.Warn().Str("jobId", upd.itemID).Msg("received update for an unknown/removed job")
-						return
+						return nil
+					}
+					data := upd.data
+					// a retried job function sends states based on
+					// the job's initial state so we have to keep
+					// the retry accounting
+					if curr.GetNumRestarts() > data.GetNumRestarts() {
+						data = data.WithRetryState(curr)
 					}
 					// make sure we keep the current error even if new status
 					// comes without one
-					if currErr := curr.GetError(); currErr != nil && upd.data.GetError() == nil {
-						ans.jobList[upd.itemID] = upd.data.WithError(currErr)
+					if currErr := curr.GetError(); currErr != nil && data.GetError() == nil {
+						ans.jobList[upd.itemID] = data.WithError(currErr)
 
 					} else {
-						ans.jobList[upd.itemID] = upd.data
+						ans.jobList[upd.itemID] = data
 					}
+					return ans.jobList[upd.itemID]
 				}()
+				if updated != nil {
+					ans.storeJob(updated)
+					ans.events.Publish(NewJobEvent(JobEventUpdate, updated))
+				}
 			case tableActionFinishJob:
-				func() {
+				jc := ans.releaseJobContext(upd.itemID)
+				cancelled := jc != nil && jc.cancelRequested
+				finished := func() GeneralJobInfo {
 					ans.jobListLock.Lock()
 					defer ans.jobListLock.Unlock()
 					curr, ok := ans.jobList[upd.itemID]
 					if !ok {
 						log.Warn().Str("jobId", upd.itemID).Msg("received finish for an unknown/removed job")
-						return
+						return nil
+					}
+					if cancelled {
+						ans.jobList[upd.itemID] = curr.AsCancelled()
+
+					} else {
+						ans.jobList[upd.itemID] = curr.AsFinished()
 					}
-					ans.jobList[upd.itemID] = curr.AsFinished()
+					return ans.jobList[upd.itemID]
 				}()
-				ans.jobDeps.SetParentFinished(upd.itemID, upd.data.GetError() != nil)
+				if finished == nil {
+					break
+				}
+				if jc != nil && ans.scheduleRetry(finished, jc.fn) {
+					if retried, ok := ans.GetJob(upd.itemID); ok {
+						ans.events.Publish(NewJobEvent(JobEventRetry, retried))
+					}
+					break
+				}
+				ans.storeJob(finished)
+				ans.archiveJob(finished)
+				ans.events.Publish(NewJobEvent(JobEventFinished, finished))
+				ans.setParentFinished(
+					upd.itemID, finished.GetError() != nil || finished.IsCancelled())
 				recipients, ok := ans.notificationRecipients[upd.itemID]
 				logAction := log.Info().Str("jobId", upd.itemID)
-				if upd.data != nil {
-					dur := time.Since(time.Time(upd.data.GetStartDT()))
-					logAction.Float64("duration", dur.Seconds())
-				}
+				dur := time.Since(time.Time(finished.GetStartDT()))
+				logAction.Float64("duration", dur.Seconds())
+				metrics.ObserveJobDuration(finished.GetType(), FinalStatus(finished), dur)
+				logAction.Bool("cancelled", finished.IsCancelled())
 				logAction.Msg("job finished")
+				ans.webhookSender.notify(finished, ans.jobWebhooks(finished))
+				ans.webhooksLock.Lock()
+				delete(ans.webhooks, upd.itemID)
+				ans.webhooksLock.Unlock()
 				if ok {
-					jdesc := extractJobDescription(ans.msgPrinter, upd.data)
+					jdesc := extractJobDescription(ans.msgPrinter, finished)
 					subject := ans.msgPrinter.Sprintf("Job of type \"%s\" finished", jdesc)
 					var sign string
 					if conf.EmailNotification.HasSignature() {
@@ -701,7 +1071,7 @@ func NewActions(
 							Paragraphs: []string{
 								subject,
 								ans.msgPrinter.Sprintf("Job ID: %s", upd.itemID),
-								localizedStatus(ans.msgPrinter, upd.data),
+								localizedStatus(ans.msgPrinter, finished),
 								"",
 								"",
 								sign,
@@ -716,11 +1086,20 @@ func NewActions(
 					}
 				}
 			case tableActionClearOldJobs:
-				func() {
+				removed := func() []string {
 					ans.jobListLock.Lock()
 					defer ans.jobListLock.Unlock()
-					clearOldJobs(ans.jobList)
+					return clearOldJobs(ans.jobList, ans.conf)
 				}()
+				ans.webhooksLock.Lock()
+				for _, jobID := range removed {
+					delete(ans.webhooks, jobID)
+				}
+				ans.webhooksLock.Unlock()
+				for _, jobID := range removed {
+					ans.removeStoredJob(jobID)
+				}
+				ans.removeOldArchivedJobs()
 			}
 
 		}
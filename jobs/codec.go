@@ -0,0 +1,145 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobs
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var (
+	registeredTypes     = make(map[string]reflect.Type)
+	registeredTypesLock sync.RWMutex
+
+	errorType = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// storedError is a JSON-friendly replacement for any error
+// value stored within a job info. Standard errors cannot be
+// decoded back from JSON so we store just their messages.
+type storedError struct {
+	Msg     string
+	present bool
+}
+
+func (e *storedError) Error() string {
+	return e.Msg
+}
+
+func (e *storedError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.Msg)
+}
+
+func (e *storedError) UnmarshalJSON(data []byte) error {
+	e.present = true
+	return json.Unmarshal(data, &e.Msg)
+}
+
+// RegisterJobInfoType registers a concrete job info type so it can
+// be restored from a job store. The function is expected to be called
+// from within an init() function (similarly to gob.Register).
+func RegisterJobInfoType(value GeneralJobInfo) {
+	tp := reflect.TypeOf(value)
+	for tp.Kind() == reflect.Pointer {
+		tp = tp.Elem()
+	}
+	registeredTypesLock.Lock()
+	registeredTypes[tp.String()] = tp
+	registeredTypesLock.Unlock()
+}
+
+// JobInfoKind returns a name under which the concrete type of the job
+// info is (or should be) registered
+func JobInfoKind(value GeneralJobInfo) string {
+	tp := reflect.TypeOf(value)
+	for tp.Kind() == reflect.Pointer {
+		tp = tp.Elem()
+	}
+	return tp.String()
+}
+
+// walkErrorFields calls fn for each settable field of type `error`
+// found in the struct v (including nested structs)
+func walkErrorFields(v reflect.Value, fn func(fv reflect.Value)) {
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < v.NumField(); i++ {
+		fv := v.Field(i)
+		if !fv.CanSet() {
+			continue
+		}
+		if fv.Type() == errorType {
+			fn(fv)
+
+		} else if fv.Kind() == reflect.Struct {
+			walkErrorFields(fv, fn)
+		}
+	}
+}
+
+// encodeJobInfo encodes the job into JSON along with
+// a registered name of its type.
+func encodeJobInfo(job GeneralJobInfo) (string, []byte, error) {
+	kind := JobInfoKind(job)
+	src := reflect.ValueOf(job)
+	for src.Kind() == reflect.Pointer {
+		src = src.Elem()
+	}
+	cp := reflect.New(src.Type()).Elem()
+	cp.Set(src)
+	walkErrorFields(cp, func(fv reflect.Value) {
+		if !fv.IsNil() {
+			err := fv.Interface().(error)
+			fv.Set(reflect.ValueOf(&storedError{Msg: err.Error()}))
+		}
+	})
+	data, err := json.Marshal(cp.Interface())
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to encode job %s: %w", job.GetID(), err)
+	}
+	return kind, data, nil
+}
+
+// decodeJobInfo restores a job info encoded by encodeJobInfo.
+// The returned value is always a pointer to the registered type.
+func decodeJobInfo(kind string, data []byte) (GeneralJobInfo, error) {
+	registeredTypesLock.RLock()
+	tp, ok := registeredTypes[kind]
+	registeredTypesLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("failed to decode job info: unregistered type %s", kind)
+	}
+	ans := reflect.New(tp)
+	walkErrorFields(ans.Elem(), func(fv reflect.Value) {
+		fv.Set(reflect.ValueOf(&storedError{}))
+	})
+	if err := json.Unmarshal(data, ans.Interface()); err != nil {
+		return nil, fmt.Errorf("failed to decode job info of type %s: %w", kind, err)
+	}
+	walkErrorFields(ans.Elem(), func(fv reflect.Value) {
+		if se, ok := fv.Interface().(*storedError); ok && !se.present {
+			fv.Set(reflect.Zero(errorType))
+		}
+	})
+	job, ok := ans.Interface().(GeneralJobInfo)
+	if !ok {
+		return nil, fmt.Errorf("failed to decode job info: type %s is not a job info", kind)
+	}
+	return job, nil
+}
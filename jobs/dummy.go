@@ -17,6 +17,7 @@
 package jobs
 
 import (
+	"slices"
 	"time"
 )
 
@@ -33,9 +34,11 @@ type DummyJobInfo struct {
 	Start           JSONTime        `json:"start"`
 	Update          JSONTime        `json:"update"`
 	Finished        bool            `json:"finished"`
+	Cancelled       bool            `json:"cancelled"`
 	Error           error           `json:"error,omitempty"`
 	Result          *DummyJobResult `json:"result"`
 	NumRestarts     int             `json:"numRestarts"`
+	Attempts        []JobAttempt    `json:"attempts,omitempty"`
 }
 
 func (j DummyJobInfo) GetID() string {
@@ -75,17 +78,51 @@ func (j DummyJobInfo) AsFinished() GeneralJobInfo {
 	return j
 }
 
+func (j DummyJobInfo) IsCancelled() bool {
+	return j.Cancelled
+}
+
+func (j DummyJobInfo) AsCancelled() GeneralJobInfo {
+	j.Update = CurrentDatetime()
+	j.Finished = true
+	j.Cancelled = true
+	j.Error = nil
+	return j
+}
+
+func (j DummyJobInfo) GetAttempts() []JobAttempt {
+	return j.Attempts
+}
+
+func (j DummyJobInfo) AsRetry() GeneralJobInfo {
+	j.Attempts = append(slices.Clone(j.Attempts), NewJobAttempt(j))
+	j.NumRestarts++
+	j.Start = CurrentDatetime()
+	j.Update = j.Start
+	j.Finished = false
+	j.Error = nil
+	return j
+}
+
+func (j DummyJobInfo) WithRetryState(prev GeneralJobInfo) GeneralJobInfo {
+	j.Start = prev.GetStartDT()
+	j.NumRestarts = prev.GetNumRestarts()
+	j.Attempts = prev.GetAttempts()
+	return j
+}
+
 func (j DummyJobInfo) CompactVersion() JobInfoCompact {
 	item := JobInfoCompact{
-		ID:       j.ID,
-		Type:     j.Type,
-		CorpusID: j.CorpusID,
-		Start:    j.Start,
-		Update:   j.Update,
-		Finished: j.Finished,
-		OK:       true,
+		ID:        j.ID,
+		Type:      j.Type,
+		CorpusID:  j.CorpusID,
+		Start:     j.Start,
+		Update:    j.Update,
+		Finished:  j.Finished,
+		Cancelled: j.Cancelled,
+		OK:        true,
 	}
-	if j.Error != nil || (j.Result == nil) {
+	if j.Error != nil || j.Cancelled || (j.Result == nil) {
 		item.OK = false
 	}
 	return item
@@ -99,10 +136,12 @@ func (j DummyJobInfo) FullInfo() any {
 		Start       JSONTime        `json:"start"`
 		Update      JSONTime        `json:"update"`
 		Finished    bool            `json:"finished"`
+		Cancelled   bool            `json:"cancelled"`
 		Error       string          `json:"error,omitempty"`
 		OK          bool            `json:"ok"`
 		Result      *DummyJobResult `json:"result"`
 		NumRestarts int             `json:"numRestarts"`
+		Attempts    []JobAttempt    `json:"attempts,omitempty"`
 	}{
 		ID:          j.ID,
 		Type:        j.Type,
@@ -110,10 +149,12 @@ func (j DummyJobInfo) FullInfo() any {
 		Start:       j.Start,
 		Update:      j.Update,
 		Finished:    j.Finished,
+		Cancelled:   j.Cancelled,
 		Error:       ErrorToString(j.Error),
-		OK:          j.Error == nil,
+		OK:          j.Error == nil && !j.Cancelled,
 		Result:      j.Result,
 		NumRestarts: j.NumRestarts,
+		Attempts:    j.Attempts,
 	}
 }
 
@@ -132,5 +173,6 @@ func (j DummyJobInfo) WithError(err error) GeneralJobInfo {
 		Error:       err,
 		Result:      j.Result,
 		NumRestarts: j.NumRestarts,
+		Attempts:    j.Attempts,
 	}
 }
@@ -0,0 +1,174 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobs
+
+import (
+	"time"
+)
+
+// startEstimator provides rough estimations of queued jobs start times.
+// It simulates the dispatcher using average durations of already finished
+// jobs of the same type. A zero time means "cannot estimate" (e.g. there
+// is no finished job of a required type yet).
+type startEstimator struct {
+	now          time.Time
+	avgDurations map[string]time.Duration
+	slots        []time.Time
+	typeSlots    map[string][]time.Time
+	typeLimits   map[string]int
+	finishTimes  map[string]time.Time
+}
+
+func laterOf(t1, t2 time.Time) time.Time {
+	if t1.IsZero() || t2.IsZero() {
+		return time.Time{}
+	}
+	if t1.After(t2) {
+		return t1
+	}
+	return t2
+}
+
+func earliestSlot(slots []time.Time) int {
+	ans := -1
+	for i, v := range slots {
+		if v.IsZero() {
+			continue
+		}
+		if ans == -1 || v.Before(slots[ans]) {
+			ans = i
+		}
+	}
+	if ans == -1 && len(slots) > 0 {
+		return 0
+	}
+	return ans
+}
+
+func (se *startEstimator) estimatedFinish(jobType string, start time.Time) time.Time {
+	dur, ok := se.avgDurations[jobType]
+	if !ok || start.IsZero() {
+		return time.Time{}
+	}
+	ans := start.Add(dur)
+	if ans.Before(se.now) {
+		return se.now
+	}
+	return ans
+}
+
+func (se *startEstimator) typeSlotsOf(jobType string) []time.Time {
+	limit := se.typeLimits[jobType]
+	if limit <= 0 {
+		return nil
+	}
+	if _, ok := se.typeSlots[jobType]; !ok {
+		se.typeSlots[jobType] = make([]time.Time, limit)
+		for i := range se.typeSlots[jobType] {
+			se.typeSlots[jobType][i] = se.now
+		}
+	}
+	return se.typeSlots[jobType]
+}
+
+// occupy places a job into the earliest global slot (and type slot
+// in case the type is limited) not earlier than notBefore and returns
+// the job's estimated start
+func (se *startEstimator) occupy(job GeneralJobInfo, notBefore time.Time) time.Time {
+	start := notBefore
+	slotIdx := earliestSlot(se.slots)
+	if slotIdx >= 0 {
+		start = laterOf(start, se.slots[slotIdx])
+	}
+	tSlots := se.typeSlotsOf(job.GetType())
+	tSlotIdx := earliestSlot(tSlots)
+	if tSlotIdx >= 0 {
+		start = laterOf(start, tSlots[tSlotIdx])
+	}
+	finish := se.estimatedFinish(job.GetType(), start)
+	if slotIdx >= 0 {
+		se.slots[slotIdx] = finish
+	}
+	if tSlotIdx >= 0 {
+		tSlots[tSlotIdx] = finish
+	}
+	se.finishTimes[job.GetID()] = finish
+	return start
+}
+
+// addRunning registers an already running job
+func (se *startEstimator) addRunning(job GeneralJobInfo) {
+	finish := se.estimatedFinish(job.GetType(), time.Time(job.GetStartDT()))
+	if idx := earliestSlot(se.slots); idx >= 0 {
+		se.slots[idx] = finish
+	}
+	tSlots := se.typeSlotsOf(job.GetType())
+	if idx := earliestSlot(tSlots); idx >= 0 {
+		tSlots[idx] = finish
+	}
+	se.finishTimes[job.GetID()] = finish
+}
+
+// addQueued registers a queued job and returns its estimated start.
+// The notBefore argument is the job's own earliest start (if any).
+func (se *startEstimator) addQueued(job GeneralJobInfo, parentIDs []string, notBefore time.Time) time.Time {
+	if notBefore.Before(se.now) {
+		notBefore = se.now
+	}
+	for _, pid := range parentIDs {
+		if ft, ok := se.finishTimes[pid]; ok {
+			notBefore = laterOf(notBefore, ft)
+		}
+	}
+	return se.occupy(job, notBefore)
+}
+
+func newStartEstimator(conf *Conf, jobList []GeneralJobInfo) *startEstimator {
+	now := time.Now()
+	sums := make(map[string]time.Duration)
+	counts := make(map[string]int)
+	for _, job := range jobList {
+		if !job.IsFinished() || job.GetError() != nil || job.IsCancelled() {
+			continue
+		}
+		cv := job.CompactVersion()
+		sums[job.GetType()] += cv.Update.Sub(cv.Start)
+		counts[job.GetType()]++
+	}
+	ans := &startEstimator{
+		now:          now,
+		avgDurations: make(map[string]time.Duration),
+		slots:        make([]time.Time, conf.MaxNumConcurrentJobs),
+		typeSlots:    make(map[string][]time.Time),
+		typeLimits:   make(map[string]int),
+		finishTimes:  make(map[string]time.Time),
+	}
+	for tp, sum := range sums {
+		ans.avgDurations[tp] = sum / time.Duration(counts[tp])
+	}
+	for i := range ans.slots {
+		ans.slots[i] = now
+	}
+	for tp, tc := range conf.JobTypes {
+		ans.typeLimits[tp] = tc.MaxNumConcurrentJobs
+	}
+	for _, job := range jobList {
+		if !job.IsFinished() {
+			ans.addRunning(job)
+		}
+	}
+	return ans
+}
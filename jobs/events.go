@@ -0,0 +1,311 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobs
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/czcorpus/cnc-gokit/uniresp"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	JobEventUpdate   = "update"
+	JobEventRetry    = "retry"
+	JobEventFinished = "finished"
+
+	eventSubscriberBufferSize = 100
+	eventKeepAliveInterval    = 30 * time.Second
+)
+
+// JobProgress describes how much data a job has processed so far.
+// Values not supported by a job type are left zero.
+type JobProgress struct {
+	ProcessedLines  int `json:"processedLines,omitempty"`
+	ProcessedTokens int `json:"processedTokens,omitempty"`
+	ProcessedAtoms  int `json:"processedAtoms,omitempty"`
+	TotalLines      int `json:"totalLines,omitempty"`
+}
+
+// ProgressReporter is an optional interface of job info types
+// able to report their progress in a unified way
+type ProgressReporter interface {
+	GetProgress() JobProgress
+}
+
+// JobEvent is a single message sent to clients listening
+// for job changes
+type JobEvent struct {
+	Event    string       `json:"event"`
+	JobID    string       `json:"jobId"`
+	JobType  string       `json:"jobType"`
+	Corpus   string       `json:"corpus"`
+	Finished bool         `json:"finished"`
+	Error    string       `json:"error,omitempty"`
+	Progress *JobProgress `json:"progress,omitempty"`
+	Job      any          `json:"job"`
+}
+
+// NewJobEvent creates an event based on the current state of a job
+func NewJobEvent(event string, job GeneralJobInfo) JobEvent {
+	ans := JobEvent{
+		Event:    event,
+		JobID:    job.GetID(),
+		JobType:  job.GetType(),
+		Corpus:   job.GetCorpus(),
+		Finished: job.IsFinished(),
+		Error:    ErrorToString(job.GetError()),
+		Job:      job.FullInfo(),
+	}
+	if pr, ok := job.(ProgressReporter); ok {
+		progress := pr.GetProgress()
+		ans.Progress = &progress
+	}
+	return ans
+}
+
+type eventSubscriber struct {
+	jobID  string
+	events chan JobEvent
+	closed bool
+	lock   sync.Mutex
+}
+
+// send passes an event to the subscriber without blocking.
+// It returns false in case the subscriber's buffer is full.
+func (sub *eventSubscriber) send(event JobEvent) bool {
+	sub.lock.Lock()
+	defer sub.lock.Unlock()
+	if sub.closed {
+		return true
+	}
+	select {
+	case sub.events <- event:
+		return true
+	default:
+		return false
+	}
+}
+
+func (sub *eventSubscriber) close() {
+	sub.lock.Lock()
+	defer sub.lock.Unlock()
+	if !sub.closed {
+		sub.closed = true
+		close(sub.events)
+	}
+}
+
+// EventBroker distributes job events to subscribed clients.
+// Publishing never blocks - in case a subscriber is too slow,
+// the events are dropped for the subscriber. A "finished" event
+// is never dropped silently - the subscriber's channel is closed
+// instead so the listener knows it has to read the final state
+// of the job by itself.
+type EventBroker struct {
+	subscribers map[*eventSubscriber]bool
+	lock        sync.RWMutex
+}
+
+// Subscribe registers a new listener. An empty jobID means
+// "all the jobs". The returned function must be called once
+// the listener is not interested in events anymore.
+func (eb *EventBroker) Subscribe(jobID string) (<-chan JobEvent, func()) {
+	sub := &eventSubscriber{
+		jobID:  jobID,
+		events: make(chan JobEvent, eventSubscriberBufferSize),
+	}
+	eb.lock.Lock()
+	eb.subscribers[sub] = true
+	eb.lock.Unlock()
+	return sub.events, func() {
+		eb.lock.Lock()
+		defer eb.lock.Unlock()
+		if eb.subscribers[sub] {
+			delete(eb.subscribers, sub)
+			sub.close()
+		}
+	}
+}
+
+// Publish sends an event to all the relevant subscribers
+func (eb *EventBroker) Publish(event JobEvent) {
+	eb.lock.RLock()
+	defer eb.lock.RUnlock()
+	for sub := range eb.subscribers {
+		if sub.jobID != "" && sub.jobID != event.JobID {
+			continue
+		}
+		if sub.send(event) {
+			continue
+		}
+		if event.Event == JobEventFinished {
+			log.Warn().
+				Str("jobId", event.JobID).
+				Msg("job event subscriber too slow, closing subscription")
+			sub.close()
+
+		} else {
+			log.Warn().
+				Str("jobId", event.JobID).
+				Str("event", event.Event).
+				Msg("job event subscriber too slow, dropping event")
+		}
+	}
+}
+
+func NewEventBroker() *EventBroker {
+	return &EventBroker{
+		subscribers: make(map[*eventSubscriber]bool),
+	}
+}
+
+// streamEvents writes events as Server-Sent Events until the client
+// disconnects, the server stops or the subscription is closed. For a stream
+// of a single job (non-empty jobID), the stream also ends once the job
+// finishes. The initial event (if any) is sent before any other event.
+func (a *Actions) streamEvents(
+	ctx *gin.Context,
+	jobID string,
+	initial *JobEvent,
+	events <-chan JobEvent,
+) {
+	stopOnFinish := jobID != ""
+	// event streams are long-lived so the server's write timeout
+	// must not apply here
+	if err := http.NewResponseController(ctx.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		log.Warn().Err(err).Msg("failed to disable write deadline for job events stream")
+	}
+	ctx.Writer.Header().Set("Content-Type", "text/event-stream")
+	ctx.Writer.Header().Set("Cache-Control", "no-cache")
+	ctx.Writer.Header().Set("Connection", "keep-alive")
+	ctx.Writer.Header().Set("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	ctx.Writer.Flush()
+
+	if initial != nil {
+		ctx.SSEvent(initial.Event, initial)
+		ctx.Writer.Flush()
+		if stopOnFinish && initial.Event == JobEventFinished {
+			return
+		}
+	}
+	keepAlive := time.NewTicker(eventKeepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case evt, ok := <-events:
+			if !ok {
+				// the broker may close a slow subscription instead of
+				// dropping the "finished" event so we read the final state
+				if !stopOnFinish {
+					return
+				}
+				if job, found := a.GetJob(jobID); found && job.IsFinished() {
+					ctx.SSEvent(JobEventFinished, NewJobEvent(JobEventFinished, job))
+					ctx.Writer.Flush()
+				}
+				return
+			}
+			ctx.SSEvent(evt.Event, evt)
+			ctx.Writer.Flush()
+			if stopOnFinish && evt.Event == JobEventFinished {
+				return
+			}
+		case <-keepAlive.C:
+			ctx.Writer.WriteString(": keep-alive\n\n")
+			ctx.Writer.Flush()
+		case <-ctx.Request.Context().Done():
+			return
+		case <-a.ctx.Done():
+			return
+		}
+	}
+}
+
+// findJobID returns a full ID of a known or queued job
+// by providing either full id or its prefix (see FindJob)
+func (a *Actions) findJobID(jobID string) (string, bool) {
+	job := func() GeneralJobInfo {
+		a.jobListLock.RLock()
+		defer a.jobListLock.RUnlock()
+		return FindJob(a.jobList, jobID)
+	}()
+	if job != nil {
+		return job.GetID(), true
+	}
+	a.jobQueueLock.Lock()
+	defer a.jobQueueLock.Unlock()
+	var ans string
+	for _, queued := range a.jobQueue.ByPriority() {
+		if strings.HasPrefix(queued.GetID(), jobID) {
+			if ans != "" {
+				return "", false
+			}
+			ans = queued.GetID()
+		}
+	}
+	return ans, ans != ""
+}
+
+// JobEvents godoc
+// @Summary      Streams changes of a specific job as Server-Sent Events
+// @Description  The stream starts with the current state of the job (if already running). Each event contains job progress and its full info. The stream ends once the job finishes. For an already finished job, a single "finished" event is sent.
+// @Produce      text/event-stream
+// @Param        jobId path string true "Job ID (or its unique prefix)"
+// @Success      200 {object} JobEvent
+// @Failure      404 {object} uniresp.ActionError
+// @Router       /jobs/{jobId}/events [get]
+func (a *Actions) JobEvents(ctx *gin.Context) {
+	jobID, ok := a.findJobID(ctx.Param("jobId"))
+	if !ok {
+		uniresp.WriteJSONErrorResponse(
+			ctx.Writer, uniresp.NewActionError("job not found"), http.StatusNotFound)
+		return
+	}
+	// we subscribe first so no event gets lost between
+	// obtaining the current state and subscribing
+	events, unsubscribe := a.events.Subscribe(jobID)
+	defer unsubscribe()
+
+	job, _ := a.GetJob(jobID)
+	var initial *JobEvent
+	if job != nil && job.IsFinished() {
+		evt := NewJobEvent(JobEventFinished, job)
+		initial = &evt
+
+	} else if job != nil {
+		evt := NewJobEvent(JobEventUpdate, job)
+		initial = &evt
+	}
+	a.streamEvents(ctx, jobID, initial, events)
+}
+
+// AllJobEvents godoc
+// @Summary      Streams changes of all the jobs as Server-Sent Events
+// @Description  In case the client cannot keep up with the events and a "finished" event cannot be delivered, the stream ends so the client can reconnect and read the current state of the jobs.
+// @Produce      text/event-stream
+// @Success      200 {object} JobEvent
+// @Router       /jobs/events [get]
+func (a *Actions) AllJobEvents(ctx *gin.Context) {
+	events, unsubscribe := a.events.Subscribe("")
+	defer unsubscribe()
+	a.streamEvents(ctx, "", nil, events)
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBrokerFiltersByJob(t *testing.T) {
+	broker := NewEventBroker()
+	single, unsubscribeSingle := broker.Subscribe("1")
+	all, unsubscribeAll := broker.Subscribe("")
+	broker.Publish(NewJobEvent(JobEventUpdate, DummyJobInfo{ID: "2"}))
+	broker.Publish(NewJobEvent(JobEventFinished, DummyJobInfo{ID: "1", Finished: true}))
+	unsubscribeSingle()
+	unsubscribeAll()
+	unsubscribeAll()
+
+	var singleIDs, allIDs []string
+	for evt := range single {
+		singleIDs = append(singleIDs, evt.JobID)
+	}
+	for evt := range all {
+		allIDs = append(allIDs, evt.JobID)
+	}
+	assert.Equal(t, []string{"1"}, singleIDs)
+	assert.Equal(t, []string{"2", "1"}, allIDs)
+}
+
+func TestEventBrokerDoesNotBlock(t *testing.T) {
+	broker := NewEventBroker()
+	events, unsubscribe := broker.Subscribe("1")
+	defer unsubscribe()
+	for i := 0; i < eventSubscriberBufferSize+10; i++ {
+		broker.Publish(NewJobEvent(JobEventUpdate, DummyJobInfo{ID: "1"}))
+	}
+	assert.Equal(t, eventSubscriberBufferSize, len(events))
+}
+
+func TestEventBrokerClosesSlowSubscriberOnFinish(t *testing.T) {
+	broker := NewEventBroker()
+	events, unsubscribe := broker.Subscribe("1")
+	defer unsubscribe()
+	for i := 0; i < eventSubscriberBufferSize; i++ {
+		broker.Publish(NewJobEvent(JobEventUpdate, DummyJobInfo{ID: "1"}))
+	}
+	broker.Publish(NewJobEvent(JobEventFinished, DummyJobInfo{ID: "1", Finished: true}))
+	broker.Publish(NewJobEvent(JobEventUpdate, DummyJobInfo{ID: "1"}))
+
+	numEvents := 0
+	for range events {
+		numEvents++
+	}
+	assert.Equal(t, eventSubscriberBufferSize, numEvents)
+}
+
+func TestFindJobIDMatchesPrefix(t *testing.T) {
+	a := &Actions{
+		jobList: map[string]GeneralJobInfo{
+			"abc-1": &DummyJobInfo{ID: "abc-1"},
+			"abd-2": &DummyJobInfo{ID: "abd-2"},
+		},
+		jobQueue: &JobQueue{},
+	}
+	f := func(chan<- GeneralJobInfo) {}
+	a.jobQueue.Enqueue(&f, &DummyJobInfo{ID: "xyz-3"})
+
+	jobID, ok := a.findJobID("abc")
+	assert.True(t, ok)
+	assert.Equal(t, "abc-1", jobID)
+	jobID, ok = a.findJobID("xy")
+	assert.True(t, ok)
+	assert.Equal(t, "xyz-3", jobID)
+	_, ok = a.findJobID("ab")
+	assert.False(t, ok)
+	_, ok = a.findJobID("foo")
+	assert.False(t, ok)
+}
@@ -0,0 +1,309 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobs
+
+import (
+	"bufio"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	journalOpPut = "put"
+	journalOpDel = "del"
+
+	// journalCompactionMinRecords specifies a minimum number of records
+	// written since the last compaction to consider another compaction
+	journalCompactionMinRecords = 1000
+
+	// journalCompactionRatio specifies how many times the number
+	// of written records must exceed the number of live jobs
+	// to trigger compaction
+	journalCompactionRatio = 4
+)
+
+type journalRecord struct {
+	Op      string          `json:"op"`
+	ID      string          `json:"id"`
+	Kind    string          `json:"kind,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	Queued  bool            `json:"queued,omitempty"`
+	Parents []string        `json:"parents,omitempty"`
+}
+
+// FileJobStore is an append-only JSON-lines journal of job status changes.
+// Each change is written and synced immediately. On open, the journal is
+// replayed (the last record of each job wins) and compacted.
+// An incomplete last line (e.g. after a crash) is ignored.
+// A gob-encoded job list written by older versions is converted
+// to the journal on open.
+type FileJobStore struct {
+	path       string
+	file       *os.File
+	entries    map[string]journalRecord
+	numWritten int
+	lock       sync.Mutex
+}
+
+func (store *FileJobStore) replay() error {
+	fr, err := os.Open(store.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+
+	} else if err != nil {
+		return fmt.Errorf("failed to replay job journal: %w", err)
+	}
+	defer fr.Close()
+	rd := bufio.NewReader(fr)
+	if first, err := rd.Peek(1); err == nil && first[0] != '{' {
+		// most likely a gob-encoded snapshot from older versions
+		fr.Close()
+		return store.convertLegacy()
+	}
+	scanner := bufio.NewScanner(rd)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		var rec journalRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			log.Warn().
+				Err(err).
+				Str("path", store.path).
+				Int("line", lineNum).
+				Msg("skipping invalid job journal record")
+			continue
+		}
+		switch rec.Op {
+		case journalOpPut:
+			store.entries[rec.ID] = rec
+		case journalOpDel:
+			delete(store.entries, rec.ID)
+		}
+	}
+	return scanner.Err()
+}
+
+// convertLegacy imports jobs from a gob-encoded job list written
+// by older versions. The original file is kept aside (with the .legacy
+// suffix) and the journal is created by the following compaction.
+func (store *FileJobStore) convertLegacy() error {
+	fr, err := os.Open(store.path)
+	if err != nil {
+		return fmt.Errorf("failed to convert legacy job status file: %w", err)
+	}
+	defer fr.Close()
+	registeredTypesLock.RLock()
+	for _, tp := range registeredTypes {
+		// older versions registered pointers to job info types
+		gob.Register(reflect.New(tp).Interface())
+	}
+	registeredTypesLock.RUnlock()
+	legacyJobs := make(JobInfoList, 0, 50)
+	if err := gob.NewDecoder(fr).Decode(&legacyJobs); err != nil {
+		return fmt.Errorf("failed to convert legacy job status file %s: %w", store.path, err)
+	}
+	for _, job := range legacyJobs {
+		kind, data, err := encodeJobInfo(job)
+		if err != nil {
+			return fmt.Errorf("failed to convert legacy job status file %s: %w", store.path, err)
+		}
+		store.entries[job.GetID()] = journalRecord{Op: journalOpPut, ID: job.GetID(), Kind: kind, Data: data}
+	}
+	legacyPath := store.path + ".legacy"
+	if err := os.Rename(store.path, legacyPath); err != nil {
+		return fmt.Errorf("failed to convert legacy job status file: %w", err)
+	}
+	log.Info().
+		Str("path", store.path).
+		Str("movedTo", legacyPath).
+		Int("numJobs", len(legacyJobs)).
+		Msg("converted legacy job status file")
+	return nil
+}
+
+// compact rewrites the journal so it contains only the latest
+// record of each job. The new file is written aside and then
+// atomically renamed.
+func (store *FileJobStore) compact() error {
+	tmpPath := store.path + ".tmp"
+	fw, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to compact job journal: %w", err)
+	}
+	ids := make([]string, 0, len(store.entries))
+	for id := range store.entries {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	bw := bufio.NewWriter(fw)
+	for _, id := range ids {
+		line, err := json.Marshal(store.entries[id])
+		if err != nil {
+			fw.Close()
+			return fmt.Errorf("failed to compact job journal: %w", err)
+		}
+		bw.Write(line)
+		bw.WriteByte('\n')
+	}
+	if err := bw.Flush(); err != nil {
+		fw.Close()
+		return fmt.Errorf("failed to compact job journal: %w", err)
+	}
+	if err := fw.Sync(); err != nil {
+		fw.Close()
+		return fmt.Errorf("failed to compact job journal: %w", err)
+	}
+	fw.Close()
+	if store.file != nil {
+		store.file.Close()
+		store.file = nil
+	}
+	if err := os.Rename(tmpPath, store.path); err != nil {
+		return fmt.Errorf("failed to compact job journal: %w", err)
+	}
+	store.file, err = os.OpenFile(store.path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to reopen job journal: %w", err)
+	}
+	store.numWritten = 0
+	return nil
+}
+
+func (store *FileJobStore) write(rec journalRecord) error {
+	if store.file == nil {
+		return ErrorStoreClosed
+	}
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to write job journal record: %w", err)
+	}
+	line = append(line, '\n')
+	if _, err := store.file.Write(line); err != nil {
+		return fmt.Errorf("failed to write job journal record: %w", err)
+	}
+	if err := store.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync job journal: %w", err)
+	}
+	store.numWritten++
+	if store.numWritten > journalCompactionMinRecords &&
+		store.numWritten > journalCompactionRatio*len(store.entries) {
+		return store.compact()
+	}
+	return nil
+}
+
+func (store *FileJobStore) Save(job GeneralJobInfo) error {
+	kind, data, err := encodeJobInfo(job)
+	if err != nil {
+		return err
+	}
+	return store.put(journalRecord{Op: journalOpPut, ID: job.GetID(), Kind: kind, Data: data})
+}
+
+func (store *FileJobStore) SaveQueued(job GeneralJobInfo, parentIDs []string) error {
+	kind, data, err := encodeJobInfo(job)
+	if err != nil {
+		return err
+	}
+	return store.put(journalRecord{
+		Op:      journalOpPut,
+		ID:      job.GetID(),
+		Kind:    kind,
+		Data:    data,
+		Queued:  true,
+		Parents: parentIDs,
+	})
+}
+
+func (store *FileJobStore) put(rec journalRecord) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	store.entries[rec.ID] = rec
+	return store.write(rec)
+}
+
+func (store *FileJobStore) Remove(jobID string) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	if _, ok := store.entries[jobID]; !ok {
+		return nil
+	}
+	delete(store.entries, jobID)
+	return store.write(journalRecord{Op: journalOpDel, ID: jobID})
+}
+
+func (store *FileJobStore) LoadAll() (JobInfoList, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	ans := make(JobInfoList, 0, len(store.entries))
+	for _, rec := range store.entries {
+		job, err := decodeJobInfo(rec.Kind, rec.Data)
+		if err != nil {
+			log.Error().Err(err).Str("jobId", rec.ID).Msg("failed to load stored job, skipping")
+			continue
+		}
+		ans = append(ans, job)
+	}
+	return ans, nil
+}
+
+func (store *FileJobStore) LoadQueued() (map[string][]string, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	ans := make(map[string][]string)
+	for _, rec := range store.entries {
+		if rec.Queued {
+			ans[rec.ID] = append([]string{}, rec.Parents...)
+		}
+	}
+	return ans, nil
+}
+
+func (store *FileJobStore) Close() error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	if store.file == nil {
+		return nil
+	}
+	err := store.file.Close()
+	store.file = nil
+	return err
+}
+
+// OpenFileJobStore opens (or creates) a job journal, replays
+// its contents and compacts it.
+func OpenFileJobStore(path string) (*FileJobStore, error) {
+	store := &FileJobStore{
+		path:    path,
+		entries: make(map[string]journalRecord),
+	}
+	if err := store.replay(); err != nil {
+		return nil, err
+	}
+	if err := store.compact(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
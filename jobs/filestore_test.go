@@ -0,0 +1,138 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobs
+
+import (
+	"encoding/gob"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	RegisterJobInfoType(&DummyJobInfo{})
+}
+
+func TestFileJobStoreReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.jsonl")
+	store, err := OpenFileJobStore(path)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Save(DummyJobInfo{ID: "1", Type: "dummy-job"}))
+	assert.NoError(t, store.Save(DummyJobInfo{ID: "2", Type: "dummy-job"}))
+	assert.NoError(t, store.Save(DummyJobInfo{ID: "1", Type: "dummy-job", Finished: true}))
+	assert.NoError(t, store.Remove("2"))
+	assert.NoError(t, store.Close())
+
+	store, err = OpenFileJobStore(path)
+	assert.NoError(t, err)
+	jobs, err := store.LoadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(jobs))
+	assert.Equal(t, "1", jobs[0].GetID())
+	assert.True(t, jobs[0].IsFinished())
+}
+
+func TestFileJobStoreKeepsErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.jsonl")
+	store, err := OpenFileJobStore(path)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Save(DummyJobInfo{ID: "1", Error: errors.New("failed to run")}))
+	assert.NoError(t, store.Save(DummyJobInfo{ID: "2"}))
+	assert.NoError(t, store.Close())
+
+	store, err = OpenFileJobStore(path)
+	assert.NoError(t, err)
+	jobs, err := store.LoadAll()
+	assert.NoError(t, err)
+	errs := make(map[string]error)
+	for _, j := range jobs {
+		errs[j.GetID()] = j.GetError()
+	}
+	assert.EqualError(t, errs["1"], "failed to run")
+	assert.Nil(t, errs["2"])
+}
+
+func TestFileJobStoreIgnoresIncompleteRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.jsonl")
+	store, err := OpenFileJobStore(path)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Save(DummyJobInfo{ID: "1"}))
+	assert.NoError(t, store.Close())
+	fw, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	assert.NoError(t, err)
+	fw.WriteString(`{"op":"put","id":"2","kind":"jobs.Dumm`)
+	fw.Close()
+
+	store, err = OpenFileJobStore(path)
+	assert.NoError(t, err)
+	jobs, err := store.LoadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(jobs))
+}
+
+func TestFileJobStoreKeepsQueuedDependencies(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.jsonl")
+	store, err := OpenFileJobStore(path)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Save(DummyJobInfo{ID: "1"}))
+	assert.NoError(t, store.SaveQueued(DummyJobInfo{ID: "2"}, []string{"1"}))
+	assert.NoError(t, store.SaveQueued(DummyJobInfo{ID: "3"}, []string{}))
+	assert.NoError(t, store.Save(DummyJobInfo{ID: "3"}))
+	assert.NoError(t, store.Close())
+
+	store, err = OpenFileJobStore(path)
+	assert.NoError(t, err)
+	queued, err := store.LoadQueued()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{"2": {"1"}}, queued)
+}
+
+func TestFileJobStoreConvertsLegacyJobList(t *testing.T) {
+	// older versions registered job types for gob directly
+	gob.Register(&DummyJobInfo{})
+	path := filepath.Join(t.TempDir(), "jobs.jsonl")
+	fw, err := os.Create(path)
+	assert.NoError(t, err)
+	legacyJobs := JobInfoList{
+		&DummyJobInfo{ID: "1", Type: "dummy-job", Finished: true},
+		&DummyJobInfo{ID: "2", Type: "dummy-job"},
+	}
+	assert.NoError(t, gob.NewEncoder(fw).Encode(legacyJobs))
+	assert.NoError(t, fw.Close())
+
+	store, err := OpenFileJobStore(path)
+	assert.NoError(t, err)
+	jobs, err := store.LoadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(jobs))
+	ids := []string{jobs[0].GetID(), jobs[1].GetID()}
+	sort.Strings(ids)
+	assert.Equal(t, []string{"1", "2"}, ids)
+	assert.NoError(t, store.Close())
+	assert.FileExists(t, path+".legacy")
+
+	// the journal is in the new format now
+	store, err = OpenFileJobStore(path)
+	assert.NoError(t, err)
+	jobs, err = store.LoadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(jobs))
+	assert.NoError(t, store.Close())
+}
@@ -0,0 +1,341 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobs
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/czcorpus/cnc-gokit/uniresp"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	dfltHistoryPageSize = 50
+	maxHistoryPageSize  = 1000
+)
+
+// HistoryFilter specifies which archived jobs should be returned.
+// Empty values mean "any".
+type HistoryFilter struct {
+	CorpusID string
+	JobType  string
+	Status   string
+	From     time.Time
+	To       time.Time
+	Offset   int
+	Limit    int
+}
+
+// sqlWhere produces an SQL condition (including the WHERE keyword
+// if needed) and respective arguments
+func (hf HistoryFilter) sqlWhere() (string, []any) {
+	conds := make([]string, 0, 5)
+	args := make([]any, 0, 5)
+	if hf.CorpusID != "" {
+		conds = append(conds, "corpus_id = ?")
+		args = append(args, hf.CorpusID)
+	}
+	if hf.JobType != "" {
+		conds = append(conds, "job_type = ?")
+		args = append(args, hf.JobType)
+	}
+	if hf.Status != "" {
+		conds = append(conds, "status = ?")
+		args = append(args, hf.Status)
+	}
+	if !hf.From.IsZero() {
+		conds = append(conds, "start >= ?")
+		args = append(args, hf.From)
+	}
+	if !hf.To.IsZero() {
+		conds = append(conds, "start < ?")
+		args = append(args, hf.To)
+	}
+	if len(conds) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// HistoryItem is an archived job
+type HistoryItem struct {
+	ID       string          `json:"id"`
+	JobType  string          `json:"type"`
+	CorpusID string          `json:"corpusId"`
+	Status   string          `json:"status"`
+	Start    JSONTime        `json:"start"`
+	Update   JSONTime        `json:"update"`
+	Job      json.RawMessage `json:"job"`
+}
+
+// HistoryPage is a single page of archived jobs
+type HistoryPage struct {
+	Total  int           `json:"total"`
+	Offset int           `json:"offset"`
+	Limit  int           `json:"limit"`
+	Items  []HistoryItem `json:"items"`
+}
+
+// JobHistory is an archive of finished jobs. Unlike the job list,
+// the archive is not cleared after a week.
+type JobHistory interface {
+
+	// Archive inserts or updates the finished job
+	Archive(job GeneralJobInfo) error
+
+	// Search returns archived jobs matching the filter,
+	// the most recent first
+	Search(filter HistoryFilter) (HistoryPage, error)
+
+	// RemoveOld removes jobs based on retention settings
+	// in the configuration and returns number of removed jobs
+	RemoveOld(conf *Conf) (int, error)
+}
+
+// MySQLJobHistory archives jobs in the `job_history` table
+// (see scripts/install.sql)
+type MySQLJobHistory struct {
+	db *sql.DB
+}
+
+func (h *MySQLJobHistory) Archive(job GeneralJobInfo) error {
+	data, err := json.Marshal(job.FullInfo())
+	if err != nil {
+		return fmt.Errorf("failed to archive job %s: %w", job.GetID(), err)
+	}
+	cv := job.CompactVersion()
+	_, err = h.db.Exec(
+		"INSERT INTO job_history (id, job_type, corpus_id, status, start, last_update, data) "+
+			"VALUES (?, ?, ?, ?, ?, ?, ?) "+
+			"ON DUPLICATE KEY UPDATE status = VALUES(status), last_update = VALUES(last_update), "+
+			"data = VALUES(data)",
+		job.GetID(), job.GetType(), job.GetCorpus(), FinalStatus(job),
+		time.Time(cv.Start), time.Time(cv.Update), string(data),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to archive job %s: %w", job.GetID(), err)
+	}
+	return nil
+}
+
+func (h *MySQLJobHistory) Search(filter HistoryFilter) (HistoryPage, error) {
+	ans := HistoryPage{
+		Offset: filter.Offset,
+		Limit:  filter.Limit,
+		Items:  []HistoryItem{},
+	}
+	where, args := filter.sqlWhere()
+	row := h.db.QueryRow("SELECT COUNT(*) FROM job_history"+where, args...)
+	if err := row.Scan(&ans.Total); err != nil {
+		return ans, fmt.Errorf("failed to search job history: %w", err)
+	}
+	rows, err := h.db.Query(
+		"SELECT id, job_type, corpus_id, status, start, last_update, data "+
+			"FROM job_history"+where+" ORDER BY start DESC, id LIMIT ? OFFSET ?",
+		append(args, filter.Limit, filter.Offset)...,
+	)
+	if err != nil {
+		return ans, fmt.Errorf("failed to search job history: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var item HistoryItem
+		var corpusID sql.NullString
+		var start, update time.Time
+		var data string
+		if err := rows.Scan(
+			&item.ID, &item.JobType, &corpusID, &item.Status, &start, &update, &data); err != nil {
+			return ans, fmt.Errorf("failed to search job history: %w", err)
+		}
+		item.CorpusID = corpusID.String
+		item.Start = JSONTime(start)
+		item.Update = JSONTime(update)
+		item.Job = json.RawMessage(data)
+		ans.Items = append(ans.Items, item)
+	}
+	return ans, rows.Err()
+}
+
+func (h *MySQLJobHistory) RemoveOld(conf *Conf) (int, error) {
+	var ans int64
+	remove := func(query string, args ...any) error {
+		res, err := h.db.Exec(query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to remove old archived jobs: %w", err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to remove old archived jobs: %w", err)
+		}
+		ans += n
+		return nil
+	}
+	customTypes := make([]any, 0, len(conf.JobTypes))
+	for jobType, tc := range conf.JobTypes {
+		if tc.HistoryRetentionDays == 0 {
+			continue
+		}
+		customTypes = append(customTypes, jobType)
+		err := remove(
+			"DELETE FROM job_history WHERE job_type = ? AND last_update < ?",
+			jobType, time.Now().AddDate(0, 0, -tc.HistoryRetentionDays),
+		)
+		if err != nil {
+			return int(ans), err
+		}
+	}
+	if conf.HistoryRetentionDays == 0 {
+		return int(ans), nil
+	}
+	query := "DELETE FROM job_history WHERE last_update < ?"
+	args := []any{time.Now().AddDate(0, 0, -conf.HistoryRetentionDays)}
+	if len(customTypes) > 0 {
+		query += " AND job_type NOT IN (" +
+			strings.Repeat("?, ", len(customTypes)-1) + "?)"
+		args = append(args, customTypes...)
+	}
+	err := remove(query, args...)
+	return int(ans), err
+}
+
+func NewMySQLJobHistory(db *sql.DB) *MySQLJobHistory {
+	return &MySQLJobHistory{db: db}
+}
+
+// nullHistory is used in case no archive is available
+type nullHistory struct{}
+
+func (nh nullHistory) Archive(job GeneralJobInfo) error {
+	return nil
+}
+
+func (nh nullHistory) Search(filter HistoryFilter) (HistoryPage, error) {
+	return HistoryPage{Offset: filter.Offset, Limit: filter.Limit, Items: []HistoryItem{}}, nil
+}
+
+func (nh nullHistory) RemoveOld(conf *Conf) (int, error) {
+	return 0, nil
+}
+
+// NewJobHistory creates a job history archive. In case
+// no database is provided, jobs are not archived.
+func NewJobHistory(db *sql.DB) JobHistory {
+	if db == nil {
+		log.Warn().Msg("no database for job history provided, finished jobs won't be archived")
+		return nullHistory{}
+	}
+	return NewMySQLJobHistory(db)
+}
+
+func (a *Actions) archiveJob(job GeneralJobInfo) {
+	if err := a.history.Archive(job); err != nil {
+		log.Error().Err(err).Str("jobId", job.GetID()).Msg("failed to archive job")
+	}
+}
+
+func (a *Actions) removeOldArchivedJobs() {
+	n, err := a.history.RemoveOld(a.conf)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to remove old archived jobs")
+
+	} else if n > 0 {
+		log.Info().Msgf("removed %d old archived job(s)", n)
+	}
+}
+
+// parseHistoryDate accepts either a date (2006-01-02) or a datetime
+// in the RFC3339 format
+func parseHistoryDate(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, v, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
+// parseHistoryFilter creates a filter based on URL query arguments
+func parseHistoryFilter(ctx *gin.Context) (HistoryFilter, error) {
+	ans := HistoryFilter{
+		CorpusID: ctx.Query("corpus"),
+		JobType:  ctx.Query("type"),
+		Status:   ctx.Query("status"),
+		Limit:    dfltHistoryPageSize,
+	}
+	if ans.Status != "" && !slices.Contains(
+		[]string{JobStatusFinished, JobStatusFailed, JobStatusCancelled}, ans.Status) {
+		return ans, fmt.Errorf("invalid status %s", ans.Status)
+	}
+	var err error
+	ans.From, err = parseHistoryDate(ctx.Query("from"))
+	if err != nil {
+		return ans, fmt.Errorf("invalid from: %w", err)
+	}
+	ans.To, err = parseHistoryDate(ctx.Query("to"))
+	if err != nil {
+		return ans, fmt.Errorf("invalid to: %w", err)
+	}
+	if v := ctx.Query("offset"); v != "" {
+		ans.Offset, err = strconv.Atoi(v)
+		if err != nil || ans.Offset < 0 {
+			return ans, fmt.Errorf("invalid offset %s", v)
+		}
+	}
+	if v := ctx.Query("limit"); v != "" {
+		ans.Limit, err = strconv.Atoi(v)
+		if err != nil || ans.Limit < 1 || ans.Limit > maxHistoryPageSize {
+			return ans, fmt.Errorf("invalid limit %s (allowed values: 1..%d)", v, maxHistoryPageSize)
+		}
+	}
+	return ans, nil
+}
+
+// JobHistory godoc
+// @Summary      Searches archived finished jobs
+// @Description  Jobs are archived once they are finished so they are available even after they are removed from the job list. Dates can be specified either as YYYY-MM-DD or as RFC3339 datetimes, the "to" date is exclusive.
+// @Produce      json
+// @Param        corpus query string false "Corpus ID"
+// @Param        type query string false "Job type"
+// @Param        status query string false "Job status (finished, failed, cancelled)"
+// @Param        from query string false "Jobs started at or after the date"
+// @Param        to query string false "Jobs started before the date"
+// @Param        offset query int false "Pagination offset" default(0)
+// @Param        limit query int false "Page size" default(50)
+// @Success      200 {object} HistoryPage
+// @Failure      400 {object} uniresp.ActionError
+// @Router       /jobs/history [get]
+func (a *Actions) JobHistory(ctx *gin.Context) {
+	filter, err := parseHistoryFilter(ctx)
+	if err != nil {
+		uniresp.RespondWithErrorJSON(ctx, err, http.StatusBadRequest)
+		return
+	}
+	ans, err := a.history.Search(filter)
+	if err != nil {
+		uniresp.RespondWithErrorJSON(ctx, err, http.StatusInternalServerError)
+		return
+	}
+	uniresp.WriteJSONResponse(ctx.Writer, ans)
+}
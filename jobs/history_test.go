@@ -0,0 +1,67 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistoryFilterSQLWhere(t *testing.T) {
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	where, args := HistoryFilter{CorpusID: "syn2020", Status: JobStatusFailed, From: from}.sqlWhere()
+	assert.Equal(t, " WHERE corpus_id = ? AND status = ? AND start >= ?", where)
+	assert.Equal(t, []any{"syn2020", JobStatusFailed, from}, args)
+
+	where, args = HistoryFilter{}.sqlWhere()
+	assert.Equal(t, "", where)
+	assert.Empty(t, args)
+}
+
+func TestClearOldJobsUsesTypeRetention(t *testing.T) {
+	conf := &Conf{
+		JobTypes: map[string]JobTypeConf{
+			"short": {RetentionHours: 1},
+		},
+	}
+	start := JSONTime(time.Now().Add(-2 * time.Hour))
+	data := map[string]GeneralJobInfo{
+		"1": DummyJobInfo{ID: "1", Type: "short", Start: start, Finished: true},
+		"2": DummyJobInfo{ID: "2", Type: "dummy-job", Start: start, Finished: true},
+		"3": DummyJobInfo{ID: "3", Type: "dummy-job", Start: JSONTime(time.Now().Add(-200 * time.Hour)), Finished: true},
+	}
+	removed := clearOldJobs(data, conf)
+	assert.ElementsMatch(t, []string{"1", "3"}, removed)
+	assert.Contains(t, data, "2")
+}
+
+func TestClearOldJobsKeepsUnfinished(t *testing.T) {
+	conf := &Conf{
+		JobTypes: map[string]JobTypeConf{
+			"short": {RetentionHours: 1},
+		},
+	}
+	start := JSONTime(time.Now().Add(-2 * time.Hour))
+	data := map[string]GeneralJobInfo{
+		"1": DummyJobInfo{ID: "1", Type: "short", Start: start},
+		"2": DummyJobInfo{ID: "2", Type: "short", Start: start, Finished: true},
+	}
+	removed := clearOldJobs(data, conf)
+	assert.Equal(t, []string{"2"}, removed)
+	assert.Contains(t, data, "1")
+}
@@ -36,6 +36,9 @@ func extractJobDescription(printer *message.Printer, info GeneralJobInfo) string
 }
 
 func localizedStatus(printer *message.Printer, info GeneralJobInfo) string {
+	if info.IsCancelled() {
+		return printer.Sprintf("Job was cancelled")
+	}
 	if info.GetError() == nil {
 		return printer.Sprintf("Job finished without errors")
 	}
@@ -17,20 +17,58 @@
 package jobs
 
 import (
-	"encoding/gob"
+	"fmt"
 	"frodo/mail"
-	"os"
 	"strings"
-	"time"
 
 	"github.com/rs/zerolog/log"
 )
 
+const (
+	JobStatusFinished  = "finished"
+	JobStatusFailed    = "failed"
+	JobStatusCancelled = "cancelled"
+)
+
 type Conf struct {
 	StatusDataPath       string                 `json:"statusDataPath"`
 	MaxNumConcurrentJobs int                    `json:"maxNumConcurrentJobs"`
 	MaxNumRestarts       int                    `json:"maxNumRestarts"`
 	EmailNotification    mail.EmailNotification `json:"emailNotification"`
+	Store                StoreConf              `json:"store"`
+	Webhooks             WebhooksConf           `json:"webhooks"`
+
+	// HistoryRetentionDays specifies how long finished jobs are kept
+	// in the job history archive. Zero means "forever". The value can be
+	// overridden for specific job types (see JobTypeConf).
+	HistoryRetentionDays int `json:"historyRetentionDays"`
+
+	// JobTypes contains job type-specific settings (key = job type)
+	JobTypes map[string]JobTypeConf `json:"jobTypes"`
+}
+
+func (conf *Conf) Validate() error {
+	for jobType, tc := range conf.JobTypes {
+		if err := tc.Retry.Validate(); err != nil {
+			return fmt.Errorf("invalid retry configuration of job type %s: %w", jobType, err)
+		}
+		if tc.RetentionHours < 0 || tc.HistoryRetentionDays < 0 {
+			return fmt.Errorf("invalid retention of job type %s: value must not be negative", jobType)
+		}
+	}
+	if conf.HistoryRetentionDays < 0 {
+		return fmt.Errorf("invalid historyRetentionDays: value must not be negative")
+	}
+	if err := conf.Webhooks.Validate(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// TypeConf returns settings for the specified job type. In case
+// there is no such configuration, default values are returned.
+func (conf *Conf) TypeConf(jobType string) JobTypeConf {
+	return conf.JobTypes[jobType]
 }
 
 // GeneralJobInfo defines a general job information
@@ -60,11 +98,35 @@ type GeneralJobInfo interface {
 	// a clone for the returned value.
 	AsFinished() GeneralJobInfo
 
+	// IsCancelled returns true if the job has been cancelled on a user request.
+	// A cancelled job is also finished but it is not considered failed.
+	IsCancelled() bool
+
+	// AsCancelled sets the internal status to a cancelled (and finished) state
+	// and returns an updated instance. Any error (typically produced by the job
+	// being interrupted) is cleared.
+	AsCancelled() GeneralJobInfo
+
 	// GetNumRestarts returns how many times was the job restarted. For the normally run
 	// job, this should be always 0. The number > 0 is expect to happen e.g. in case the
 	// service is shut down while some jobs are running.
 	GetNumRestarts() int
 
+	// GetAttempts returns a history of failed runs of a job
+	// which has been automatically retried (see RetryConf)
+	GetAttempts() []JobAttempt
+
+	// AsRetry records the current (failed) run into the attempt history,
+	// increases the number of restarts and returns an unfinished instance
+	// without error ready to be run again.
+	AsRetry() GeneralJobInfo
+
+	// WithRetryState returns a clone with the retry accounting (start,
+	// number of restarts, attempt history) taken from prev. This is used
+	// for status updates of a retried job as a job function knows only
+	// the initial state of its job.
+	WithRetryState(prev GeneralJobInfo) GeneralJobInfo
+
 	// GetError returns status error (if any) or nil
 	GetError() error
 
@@ -82,31 +144,6 @@ type GeneralJobInfo interface {
 // JobInfoList is just a list of any jobs
 type JobInfoList []GeneralJobInfo
 
-// Serialize gob-encodes the list and stores
-// it to a specified path
-func (jil JobInfoList) Serialize(path string) error {
-	fw, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return err
-	}
-	defer fw.Close()
-	enc := gob.NewEncoder(fw)
-	return enc.Encode(jil)
-}
-
-// LoadJobList loads gob-encoded job list
-// from a specified path
-func LoadJobList(path string) (JobInfoList, error) {
-	fw, err := os.OpenFile(path, os.O_RDONLY, 0644)
-	if err != nil {
-		return nil, err
-	}
-	dec := gob.NewDecoder(fw)
-	ans := make(JobInfoList, 0, 50)
-	err = dec.Decode(&ans)
-	return ans, err
-}
-
 func (jil JobInfoList) Len() int {
 	return len(jil)
 }
@@ -119,18 +156,35 @@ func (jil JobInfoList) Swap(i, j int) {
 	jil[i], jil[j] = jil[j], jil[i]
 }
 
-func clearOldJobs(data map[string]GeneralJobInfo) {
+// FinalStatus returns a status of a finished job
+// (JobStatusFinished, JobStatusFailed or JobStatusCancelled)
+func FinalStatus(job GeneralJobInfo) string {
+	if job.IsCancelled() {
+		return JobStatusCancelled
+	}
+	if job.GetError() != nil {
+		return JobStatusFailed
+	}
+	return JobStatusFinished
+}
+
+// clearOldJobs removes old finished jobs from the provided map
+// (based on retention of respective job types)
+// and returns IDs of the removed jobs. Unfinished jobs (running
+// or waiting for a retry) are kept regardless of their age.
+func clearOldJobs(data map[string]GeneralJobInfo, conf *Conf) []string {
 	curr := CurrentDatetime()
-	numRemoved := 0
+	removed := make([]string, 0, 10)
 	for k, v := range data {
-		if curr.Sub(v.GetStartDT()) > time.Duration(168)*time.Hour {
+		if v.IsFinished() && curr.Sub(v.GetStartDT()) > conf.TypeConf(v.GetType()).Retention() {
 			delete(data, k)
-			numRemoved++
+			removed = append(removed, k)
 		}
 	}
-	if numRemoved > 0 {
-		log.Info().Msgf("removed %d old job(s)", numRemoved)
+	if len(removed) > 0 {
+		log.Info().Msgf("removed %d old job(s)", len(removed))
 	}
+	return removed
 }
 
 // FindJob searches a job by providing either full id or its prefix.
@@ -172,6 +226,7 @@ type JobInfoCompact struct {
 	Start           JSONTime `json:"start"`
 	Update          JSONTime `json:"update"`
 	Finished        bool     `json:"finished"`
+	Cancelled       bool     `json:"cancelled"`
 	OK              bool     `json:"ok"`
 }
 
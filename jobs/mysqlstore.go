@@ -0,0 +1,138 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobs
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// MySQLJobStore stores jobs in the `job_store` table
+// (see scripts/install.sql)
+type MySQLJobStore struct {
+	db *sql.DB
+}
+
+func (store *MySQLJobStore) Save(job GeneralJobInfo) error {
+	kind, data, err := encodeJobInfo(job)
+	if err != nil {
+		return err
+	}
+	_, err = store.db.Exec(
+		"INSERT INTO job_store (id, kind, job_type, corpus_id, finished, data, last_update) "+
+			"VALUES (?, ?, ?, ?, ?, ?, ?) "+
+			"ON DUPLICATE KEY UPDATE kind = VALUES(kind), job_type = VALUES(job_type), "+
+			"corpus_id = VALUES(corpus_id), finished = VALUES(finished), data = VALUES(data), "+
+			"queued = 0, parent_ids = NULL, last_update = VALUES(last_update)",
+		job.GetID(), kind, job.GetType(), job.GetCorpus(), job.IsFinished(), string(data), time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save job %s: %w", job.GetID(), err)
+	}
+	return nil
+}
+
+func (store *MySQLJobStore) SaveQueued(job GeneralJobInfo, parentIDs []string) error {
+	kind, data, err := encodeJobInfo(job)
+	if err != nil {
+		return err
+	}
+	parents, err := json.Marshal(parentIDs)
+	if err != nil {
+		return fmt.Errorf("failed to save job %s: %w", job.GetID(), err)
+	}
+	_, err = store.db.Exec(
+		"INSERT INTO job_store (id, kind, job_type, corpus_id, finished, data, queued, parent_ids, last_update) "+
+			"VALUES (?, ?, ?, ?, ?, ?, 1, ?, ?) "+
+			"ON DUPLICATE KEY UPDATE kind = VALUES(kind), job_type = VALUES(job_type), "+
+			"corpus_id = VALUES(corpus_id), finished = VALUES(finished), data = VALUES(data), "+
+			"queued = 1, parent_ids = VALUES(parent_ids), last_update = VALUES(last_update)",
+		job.GetID(), kind, job.GetType(), job.GetCorpus(), job.IsFinished(), string(data),
+		string(parents), time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save job %s: %w", job.GetID(), err)
+	}
+	return nil
+}
+
+func (store *MySQLJobStore) Remove(jobID string) error {
+	_, err := store.db.Exec("DELETE FROM job_store WHERE id = ?", jobID)
+	if err != nil {
+		return fmt.Errorf("failed to remove job %s: %w", jobID, err)
+	}
+	return nil
+}
+
+func (store *MySQLJobStore) LoadAll() (JobInfoList, error) {
+	rows, err := store.db.Query("SELECT id, kind, data FROM job_store")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load stored jobs: %w", err)
+	}
+	defer rows.Close()
+	ans := make(JobInfoList, 0, 50)
+	for rows.Next() {
+		var id, kind, data string
+		if err := rows.Scan(&id, &kind, &data); err != nil {
+			return nil, fmt.Errorf("failed to load stored jobs: %w", err)
+		}
+		job, err := decodeJobInfo(kind, []byte(data))
+		if err != nil {
+			log.Error().Err(err).Str("jobId", id).Msg("failed to load stored job, skipping")
+			continue
+		}
+		ans = append(ans, job)
+	}
+	return ans, rows.Err()
+}
+
+func (store *MySQLJobStore) LoadQueued() (map[string][]string, error) {
+	rows, err := store.db.Query("SELECT id, parent_ids FROM job_store WHERE queued = 1")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load queued jobs: %w", err)
+	}
+	defer rows.Close()
+	ans := make(map[string][]string)
+	for rows.Next() {
+		var id string
+		var parents sql.NullString
+		if err := rows.Scan(&id, &parents); err != nil {
+			return nil, fmt.Errorf("failed to load queued jobs: %w", err)
+		}
+		parentIDs := []string{}
+		if parents.Valid && parents.String != "" {
+			if err := json.Unmarshal([]byte(parents.String), &parentIDs); err != nil {
+				log.Error().Err(err).Str("jobId", id).Msg("failed to load parents of a queued job, ignoring")
+			}
+		}
+		ans[id] = parentIDs
+	}
+	return ans, rows.Err()
+}
+
+// Close does nothing as the database is shared
+// with other components
+func (store *MySQLJobStore) Close() error {
+	return nil
+}
+
+func NewMySQLJobStore(db *sql.DB) *MySQLJobStore {
+	return &MySQLJobStore{db: db}
+}
@@ -0,0 +1,101 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobs
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	dfltJobRetentionHours = 168
+)
+
+// JobPriority specifies a priority class of a job. Jobs with higher
+// priority are dispatched first, jobs within the same priority class
+// are dispatched in the FIFO order. The zero value is the normal priority.
+type JobPriority int
+
+const (
+	PriorityLow    JobPriority = -1
+	PriorityNormal JobPriority = 0
+	PriorityHigh   JobPriority = 1
+)
+
+func (p JobPriority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	default:
+		return fmt.Sprintf("%d", int(p))
+	}
+}
+
+func (p JobPriority) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.String())
+}
+
+func (p *JobPriority) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("failed to unmarshal job priority: %w", err)
+	}
+	switch s {
+	case "low":
+		*p = PriorityLow
+	case "normal", "":
+		*p = PriorityNormal
+	case "high":
+		*p = PriorityHigh
+	default:
+		return fmt.Errorf("unknown job priority: %s", s)
+	}
+	return nil
+}
+
+// JobTypeConf contains job type-specific settings
+type JobTypeConf struct {
+	Priority JobPriority `json:"priority"`
+
+	// MaxNumConcurrentJobs limits the number of running jobs of the type.
+	// Zero means there is no type-specific limit (but the global
+	// Conf.MaxNumConcurrentJobs still applies).
+	MaxNumConcurrentJobs int `json:"maxNumConcurrentJobs"`
+
+	// Retry specifies whether and how failed jobs of the type are run again
+	Retry RetryConf `json:"retry"`
+
+	// RetentionHours specifies how long jobs of the type are kept
+	// in the job list. Zero means dfltJobRetentionHours.
+	RetentionHours int `json:"retentionHours"`
+
+	// HistoryRetentionDays specifies how long jobs of the type are kept
+	// in the job history archive. Zero means Conf.HistoryRetentionDays.
+	HistoryRetentionDays int `json:"historyRetentionDays"`
+}
+
+// Retention returns how long jobs of the type are kept in the job list
+func (tc JobTypeConf) Retention() time.Duration {
+	if tc.RetentionHours == 0 {
+		return time.Duration(dfltJobRetentionHours) * time.Hour
+	}
+	return time.Duration(tc.RetentionHours) * time.Hour
+}
@@ -17,19 +17,34 @@
 package jobs
 
 import (
+	"context"
 	"errors"
+	"sort"
+	"time"
 )
 
 var (
 	ErrorEmptyQueue = errors.New("empty queue")
+	ErrorNotQueued  = errors.New("job not found in queue")
 )
 
 type QueuedFunc = func(chan<- GeneralJobInfo)
 
+// JobFunc is a function performing a job. The provided context is cancelled
+// once a user asks for the job to be cancelled. In such case, the function
+// should stop as soon as possible, clean up any partial output and close
+// the updates channel as it would do with any other job finish.
+type JobFunc = func(ctx context.Context, updates chan<- GeneralJobInfo)
+
 type JobEntry struct {
 	next         *JobEntry
 	job          *QueuedFunc
 	initialState GeneralJobInfo
+	priority     JobPriority
+
+	// notBefore specifies the earliest time the job can be run
+	// (zero value means "any time")
+	notBefore time.Time
 }
 
 type JobQueue struct {
@@ -45,11 +60,35 @@ func (jq *JobQueue) Size() int {
 	return ans
 }
 
+// Enqueue adds a job with the normal priority
 func (jq *JobQueue) Enqueue(item *QueuedFunc, initialState GeneralJobInfo) {
-	entry := &JobEntry{
+	jq.EnqueueWithPriority(item, initialState, PriorityNormal)
+}
+
+func (jq *JobQueue) EnqueueWithPriority(item *QueuedFunc, initialState GeneralJobInfo, priority JobPriority) {
+	jq.appendEntry(&JobEntry{
 		job:          item,
 		initialState: initialState,
-	}
+		priority:     priority,
+	})
+}
+
+// EnqueueDelayed adds a job which cannot be run sooner than notBefore
+func (jq *JobQueue) EnqueueDelayed(
+	item *QueuedFunc,
+	initialState GeneralJobInfo,
+	priority JobPriority,
+	notBefore time.Time,
+) {
+	jq.appendEntry(&JobEntry{
+		job:          item,
+		initialState: initialState,
+		priority:     priority,
+		notBefore:    notBefore,
+	})
+}
+
+func (jq *JobQueue) appendEntry(entry *JobEntry) {
 	if jq.firstEntry == nil {
 		jq.firstEntry = entry
 	}
@@ -121,3 +160,111 @@ func (jq *JobQueue) PeekID() (string, error) {
 	}
 	return jq.firstEntry.initialState.GetID(), nil
 }
+
+// ByPriority returns initial states of the enqueued jobs in the order
+// they should be considered for dispatching - i.e. sorted by priority
+// (higher first) and then by their position in the queue.
+func (jq *JobQueue) ByPriority() []GeneralJobInfo {
+	entries := make([]*JobEntry, 0, 10)
+	for curr := jq.firstEntry; curr != nil; curr = curr.next {
+		entries = append(entries, curr)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].priority > entries[j].priority
+	})
+	ans := make([]GeneralJobInfo, len(entries))
+	for i, e := range entries {
+		ans[i] = e.initialState
+	}
+	return ans
+}
+
+func (jq *JobQueue) removeEntry(jobID string) *JobEntry {
+	var prev *JobEntry
+	for curr := jq.firstEntry; curr != nil; curr = curr.next {
+		if curr.initialState.GetID() == jobID {
+			if prev == nil {
+				jq.firstEntry = curr.next
+
+			} else {
+				prev.next = curr.next
+			}
+			if jq.lastEntry == curr {
+				jq.lastEntry = prev
+			}
+			curr.next = nil
+			return curr
+		}
+		prev = curr
+	}
+	return nil
+}
+
+// Remove removes a job with the provided ID from any position
+// in the queue. In case there is no such job, ErrorNotQueued
+// is returned.
+func (jq *JobQueue) Remove(jobID string) (*QueuedFunc, GeneralJobInfo, error) {
+	entry := jq.removeEntry(jobID)
+	if entry == nil {
+		return nil, nil, ErrorNotQueued
+	}
+	return entry.job, entry.initialState, nil
+}
+
+// MoveToFront moves a job to the first position of the queue.
+// Please note that the job still keeps its priority so it will
+// be the first only among jobs of the same priority.
+func (jq *JobQueue) MoveToFront(jobID string) error {
+	entry := jq.removeEntry(jobID)
+	if entry == nil {
+		return ErrorNotQueued
+	}
+	entry.next = jq.firstEntry
+	jq.firstEntry = entry
+	if jq.lastEntry == nil {
+		jq.lastEntry = entry
+	}
+	return nil
+}
+
+// MoveToBack moves a job to the last position of the queue.
+// Please note that the job still keeps its priority.
+func (jq *JobQueue) MoveToBack(jobID string) error {
+	entry := jq.removeEntry(jobID)
+	if entry == nil {
+		return ErrorNotQueued
+	}
+	jq.appendEntry(entry)
+	return nil
+}
+
+// Priority returns a priority of an enqueued job
+func (jq *JobQueue) Priority(jobID string) (JobPriority, error) {
+	for curr := jq.firstEntry; curr != nil; curr = curr.next {
+		if curr.initialState.GetID() == jobID {
+			return curr.priority, nil
+		}
+	}
+	return PriorityNormal, ErrorNotQueued
+}
+
+// NotBefore returns the earliest time an enqueued job can be run.
+// Zero time means there is no such limit.
+func (jq *JobQueue) NotBefore(jobID string) (time.Time, error) {
+	for curr := jq.firstEntry; curr != nil; curr = curr.next {
+		if curr.initialState.GetID() == jobID {
+			return curr.notBefore, nil
+		}
+	}
+	return time.Time{}, ErrorNotQueued
+}
+
+// Contains tests whether a job with the provided ID is enqueued
+func (jq *JobQueue) Contains(jobID string) bool {
+	for curr := jq.firstEntry; curr != nil; curr = curr.next {
+		if curr.initialState.GetID() == jobID {
+			return true
+		}
+	}
+	return false
+}
@@ -119,3 +119,66 @@ func TestDelayNextOnTwoItemQueue(t *testing.T) {
 	assert.Equal(t, &f2, v)
 	assert.NoError(t, err)
 }
+
+func TestByPriority(t *testing.T) {
+	q := JobQueue{}
+	f1 := func(chan<- GeneralJobInfo) {}
+	q.EnqueueWithPriority(&f1, &DummyJobInfo{ID: "1"}, PriorityLow)
+	q.EnqueueWithPriority(&f1, &DummyJobInfo{ID: "2"}, PriorityNormal)
+	q.EnqueueWithPriority(&f1, &DummyJobInfo{ID: "3"}, PriorityHigh)
+	q.EnqueueWithPriority(&f1, &DummyJobInfo{ID: "4"}, PriorityNormal)
+	ids := make([]string, 0, 4)
+	for _, v := range q.ByPriority() {
+		ids = append(ids, v.GetID())
+	}
+	assert.Equal(t, []string{"3", "2", "4", "1"}, ids)
+}
+
+func TestRemoveFromMiddle(t *testing.T) {
+	q := JobQueue{}
+	f1 := func(chan<- GeneralJobInfo) {}
+	f2 := func(chan<- GeneralJobInfo) {}
+	f3 := func(chan<- GeneralJobInfo) {}
+	q.Enqueue(&f1, &DummyJobInfo{ID: "1"})
+	q.Enqueue(&f2, &DummyJobInfo{ID: "2"})
+	q.Enqueue(&f3, &DummyJobInfo{ID: "3"})
+	v, st, err := q.Remove("2")
+	assert.NoError(t, err)
+	assert.Equal(t, &f2, v)
+	assert.Equal(t, "2", st.GetID())
+	assert.Equal(t, 2, q.Size())
+	assert.Equal(t, &f1, q.firstEntry.job)
+	assert.Equal(t, &f3, q.lastEntry.job)
+}
+
+func TestRemoveLast(t *testing.T) {
+	q := JobQueue{}
+	f1 := func(chan<- GeneralJobInfo) {}
+	f2 := func(chan<- GeneralJobInfo) {}
+	q.Enqueue(&f1, &DummyJobInfo{ID: "1"})
+	q.Enqueue(&f2, &DummyJobInfo{ID: "2"})
+	_, _, err := q.Remove("2")
+	assert.NoError(t, err)
+	assert.Equal(t, &f1, q.lastEntry.job)
+	q.Enqueue(&f2, &DummyJobInfo{ID: "3"})
+	assert.Equal(t, 2, q.Size())
+	_, _, err = q.Remove("4")
+	assert.Equal(t, ErrorNotQueued, err)
+}
+
+func TestMoveToFrontAndBack(t *testing.T) {
+	q := JobQueue{}
+	f1 := func(chan<- GeneralJobInfo) {}
+	q.Enqueue(&f1, &DummyJobInfo{ID: "1"})
+	q.Enqueue(&f1, &DummyJobInfo{ID: "2"})
+	q.Enqueue(&f1, &DummyJobInfo{ID: "3"})
+	assert.NoError(t, q.MoveToFront("3"))
+	assert.NoError(t, q.MoveToBack("1"))
+	ids := make([]string, 0, 3)
+	for _, v := range q.ByPriority() {
+		ids = append(ids, v.GetID())
+	}
+	assert.Equal(t, []string{"3", "2", "1"}, ids)
+	assert.Equal(t, "1", q.lastEntry.initialState.GetID())
+	assert.Equal(t, ErrorNotQueued, q.MoveToFront("4"))
+}
@@ -0,0 +1,156 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobs
+
+import (
+	"net/http"
+
+	"github.com/czcorpus/cnc-gokit/uniresp"
+	"github.com/gin-gonic/gin"
+)
+
+// QueuedJobInfo describes a job waiting in the job queue
+type QueuedJobInfo struct {
+	Position       int         `json:"position"`
+	ID             string      `json:"id"`
+	Type           string      `json:"type"`
+	CorpusID       string      `json:"corpusId"`
+	Priority       JobPriority `json:"priority"`
+	Dependencies   []string    `json:"dependencies"`
+	EstimatedStart *JSONTime   `json:"estimatedStart"`
+}
+
+func (a *Actions) queuedJobs() []QueuedJobInfo {
+	a.jobQueueLock.Lock()
+	defer a.jobQueueLock.Unlock()
+	estimator := newStartEstimator(a.conf, a.createJobList(true))
+	queued := a.jobQueue.ByPriority()
+	ans := make([]QueuedJobInfo, len(queued))
+	for i, job := range queued {
+		priority, _ := a.jobQueue.Priority(job.GetID())
+		a.jobDepsLock.Lock()
+		parents := a.jobDeps.getParentIDs(job.GetID())
+		a.jobDepsLock.Unlock()
+		ans[i] = QueuedJobInfo{
+			Position:     i,
+			ID:           job.GetID(),
+			Type:         job.GetType(),
+			CorpusID:     job.GetCorpus(),
+			Priority:     priority,
+			Dependencies: parents,
+		}
+		notBefore, _ := a.jobQueue.NotBefore(job.GetID())
+		if est := estimator.addQueued(job, parents, notBefore); !est.IsZero() {
+			tmp := JSONTime(est)
+			ans[i].EstimatedStart = &tmp
+		}
+	}
+	return ans
+}
+
+// IsQueued tests whether a job is waiting in the job queue
+func (a *Actions) IsQueued(jobID string) bool {
+	a.jobQueueLock.Lock()
+	defer a.jobQueueLock.Unlock()
+	return a.jobQueue.Contains(jobID)
+}
+
+// QueueLengthByType returns numbers of queued jobs (key = job type)
+func (a *Actions) QueueLengthByType() map[string]int {
+	a.jobQueueLock.Lock()
+	defer a.jobQueueLock.Unlock()
+	ans := make(map[string]int)
+	for _, job := range a.jobQueue.ByPriority() {
+		ans[job.GetType()]++
+	}
+	return ans
+}
+
+// JobQueue godoc
+// @Summary      Returns a list of queued jobs (i.e. jobs waiting for a free slot or for their parent jobs)
+// @Description  Jobs are listed in the order they will be considered for running. The estimated start is based on average durations of finished jobs and is null if it cannot be estimated.
+// @Produce      json
+// @Success      200 {array} QueuedJobInfo
+// @Router       /jobs/queue [get]
+func (a *Actions) JobQueue(ctx *gin.Context) {
+	uniresp.WriteJSONResponse(ctx.Writer, a.queuedJobs())
+}
+
+// CancelQueuedJob godoc
+// @Summary      Cancel a queued job before it starts
+// @Description  The job is registered as cancelled so its possible dependent jobs won't run either.
+// @Produce      json
+// @Param        jobId path string true "Job ID"
+// @Success      200 {object} any
+// @Failure      404 {object} uniresp.ActionError
+// @Router       /jobs/queue/{jobId} [delete]
+func (a *Actions) CancelQueuedJob(ctx *gin.Context) {
+	jobID := ctx.Param("jobId")
+	a.jobQueueLock.Lock()
+	defer a.jobQueueLock.Unlock()
+	if !a.jobQueue.Contains(jobID) {
+		uniresp.WriteJSONErrorResponse(
+			ctx.Writer, uniresp.NewActionError("job not found in queue"), http.StatusNotFound)
+		return
+	}
+	if err := a.dequeueJobAsCancelled(jobID); err != nil {
+		uniresp.WriteJSONErrorResponse(
+			ctx.Writer, uniresp.NewActionError("failed to cancel job: %w", err), http.StatusInternalServerError)
+		return
+	}
+	job, _ := a.GetJob(jobID)
+	uniresp.WriteJSONResponse(ctx.Writer, job.FullInfo())
+}
+
+// MoveQueuedJobToFront godoc
+// @Summary      Move a queued job to the front of the queue
+// @Description  The job keeps its priority class so it will be the first one among jobs of the same priority.
+// @Produce      json
+// @Param        jobId path string true "Job ID"
+// @Success      200 {array} QueuedJobInfo
+// @Failure      404 {object} uniresp.ActionError
+// @Router       /jobs/queue/{jobId}/moveToFront [post]
+func (a *Actions) MoveQueuedJobToFront(ctx *gin.Context) {
+	a.jobQueueLock.Lock()
+	err := a.jobQueue.MoveToFront(ctx.Param("jobId"))
+	a.jobQueueLock.Unlock()
+	if err == ErrorNotQueued {
+		uniresp.WriteJSONErrorResponse(
+			ctx.Writer, uniresp.NewActionError("job not found in queue"), http.StatusNotFound)
+		return
+	}
+	uniresp.WriteJSONResponse(ctx.Writer, a.queuedJobs())
+}
+
+// MoveQueuedJobToBack godoc
+// @Summary      Move a queued job to the back of the queue
+// @Description  The job keeps its priority class.
+// @Produce      json
+// @Param        jobId path string true "Job ID"
+// @Success      200 {array} QueuedJobInfo
+// @Failure      404 {object} uniresp.ActionError
+// @Router       /jobs/queue/{jobId}/moveToBack [post]
+func (a *Actions) MoveQueuedJobToBack(ctx *gin.Context) {
+	a.jobQueueLock.Lock()
+	err := a.jobQueue.MoveToBack(ctx.Param("jobId"))
+	a.jobQueueLock.Unlock()
+	if err == ErrorNotQueued {
+		uniresp.WriteJSONErrorResponse(
+			ctx.Writer, uniresp.NewActionError("job not found in queue"), http.StatusNotFound)
+		return
+	}
+	uniresp.WriteJSONResponse(ctx.Writer, a.queuedJobs())
+}
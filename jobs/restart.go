@@ -0,0 +1,118 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobs
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/rs/zerolog/log"
+)
+
+// JobFuncFactory re-creates a function of a job detached by a server
+// shutdown so the job can be enqueued again (using the same ID).
+type JobFuncFactory func(job GeneralJobInfo) (*JobFunc, error)
+
+// RegisterJobFuncFactory registers a factory used to restart detached
+// jobs of the same concrete type as the provided value.
+func (a *Actions) RegisterJobFuncFactory(value GeneralJobInfo, factory JobFuncFactory) {
+	a.jobFuncFactories[JobInfoKind(value)] = factory
+}
+
+// sortByDependencies orders jobs so that parents always come before
+// their children. Otherwise, the jobs are ordered by their start.
+func sortByDependencies(jobs JobInfoList, parents map[string][]string) JobInfoList {
+	sort.Sort(jobs)
+	byID := make(map[string]GeneralJobInfo)
+	for _, job := range jobs {
+		byID[job.GetID()] = job
+	}
+	ans := make(JobInfoList, 0, len(jobs))
+	visited := make(map[string]bool)
+	var visit func(job GeneralJobInfo)
+	visit = func(job GeneralJobInfo) {
+		if visited[job.GetID()] {
+			return
+		}
+		visited[job.GetID()] = true
+		for _, pid := range parents[job.GetID()] {
+			if parent, ok := byID[pid]; ok {
+				visit(parent)
+			}
+		}
+		ans = append(ans, job)
+	}
+	for _, job := range jobs {
+		visit(job)
+	}
+	return ans
+}
+
+// restartDetachedJob enqueues a detached job again. A job which had been
+// running is restarted (which counts as a new attempt) unless it reports
+// it is not idempotent, a job which had been just enqueued is enqueued
+// again along with its dependencies.
+func (a *Actions) restartDetachedJob(job GeneralJobInfo) error {
+	factory, ok := a.jobFuncFactories[JobInfoKind(job)]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrorJobNotRestartable, JobInfoKind(job))
+	}
+	parents, wasQueued := a.detachedParents[job.GetID()]
+	if !wasQueued {
+		// the interrupted run may have left partial results
+		// the job cannot cope with
+		if !isRetrySafe(job) {
+			return fmt.Errorf("%w (job is not safe to run again)", ErrorJobInterrupted)
+		}
+		if err := a.TestAllowsJobRestart(job); err != nil {
+			return err
+		}
+		job = job.WithError(ErrorJobInterrupted).AsRetry()
+	}
+	fn, err := factory(job)
+	if err != nil {
+		return fmt.Errorf("failed to restart job %s: %w", job.GetID(), err)
+	}
+	a.detachedJobsLock.Lock()
+	delete(a.detachedJobs, job.GetID())
+	a.detachedJobsLock.Unlock()
+	a.enqueue(fn, job, parents)
+	log.Info().
+		Str("jobId", job.GetID()).
+		Str("jobType", job.GetType()).
+		Bool("wasQueued", wasQueued).
+		Strs("parents", parents).
+		Msg("restarted detached job")
+	return nil
+}
+
+// RestartDetachedJobs restores unfinished jobs loaded from the job store
+// (i.e. jobs interrupted by a server shutdown) using registered
+// job function factories. Jobs which cannot be restarted are registered
+// as failed so any jobs depending on them fail too.
+// The method is expected to be called once all the factories are
+// registered.
+func (a *Actions) RestartDetachedJobs() {
+	detached := sortByDependencies(a.GetDetachedJobs(), a.detachedParents)
+	for _, job := range detached {
+		if err := a.restartDetachedJob(job); err != nil {
+			log.Error().Err(err).Str("jobId", job.GetID()).Msg("failed to restart detached job")
+			updateJobChan := a.registerJob(job.WithError(err), nil)
+			close(updateJobChan)
+		}
+	}
+	a.detachedParents = make(map[string][]string)
+}
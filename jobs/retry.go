@@ -0,0 +1,128 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobs
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+const (
+	dfltRetryInitialBackoffSecs = 10
+	dfltRetryMaxBackoffSecs     = 600
+)
+
+// RetryConf specifies how failed jobs of a type are retried
+type RetryConf struct {
+
+	// MaxAttempts is the maximum number of job runs (including the first one).
+	// Values 0 and 1 mean "no retry".
+	MaxAttempts int `json:"maxAttempts"`
+
+	// InitialBackoffSecs is a delay before the first retry. Each following
+	// retry doubles the delay.
+	InitialBackoffSecs int `json:"initialBackoffSecs"`
+
+	// MaxBackoffSecs limits the delay between two attempts
+	MaxBackoffSecs int `json:"maxBackoffSecs"`
+
+	// RetryableErrors contains regular expressions matched against
+	// job error messages. An empty list means that any error is retryable.
+	RetryableErrors []string `json:"retryableErrors"`
+}
+
+func (rc RetryConf) Validate() error {
+	for _, expr := range rc.RetryableErrors {
+		if _, err := regexp.Compile(expr); err != nil {
+			return fmt.Errorf("invalid retryable error expression %s: %w", expr, err)
+		}
+	}
+	if rc.InitialBackoffSecs < 0 || rc.MaxBackoffSecs < 0 {
+		return fmt.Errorf("retry backoff must not be negative")
+	}
+	return nil
+}
+
+// IsRetryable tests whether a job failed with the provided error
+// can be run again
+func (rc RetryConf) IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if len(rc.RetryableErrors) == 0 {
+		return true
+	}
+	for _, expr := range rc.RetryableErrors {
+		if ok, _ := regexp.MatchString(expr, err.Error()); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// IdempotencyReporter is an optional interface of job info types
+// which cannot be always safely run again. E.g. a job appending
+// data would duplicate the data written by its failed run.
+type IdempotencyReporter interface {
+	IsIdempotent() bool
+}
+
+// isRetrySafe tests whether running the job again
+// cannot corrupt data written by its previous run
+func isRetrySafe(job GeneralJobInfo) bool {
+	if tJob, ok := job.(IdempotencyReporter); ok {
+		return tJob.IsIdempotent()
+	}
+	return true
+}
+
+// Backoff returns a delay before running the job again
+// after the specified (1-based) attempt failed
+func (rc RetryConf) Backoff(attempt int) time.Duration {
+	initial := rc.InitialBackoffSecs
+	if initial == 0 {
+		initial = dfltRetryInitialBackoffSecs
+	}
+	maxBackoff := rc.MaxBackoffSecs
+	if maxBackoff == 0 {
+		maxBackoff = dfltRetryMaxBackoffSecs
+	}
+	ans := time.Duration(initial) * time.Second
+	for i := 1; i < attempt && ans < time.Duration(maxBackoff)*time.Second; i++ {
+		ans *= 2
+	}
+	return min(ans, time.Duration(maxBackoff)*time.Second)
+}
+
+// JobAttempt describes a single failed run of a job
+// which has been retried
+type JobAttempt struct {
+	Start  JSONTime `json:"start"`
+	Update JSONTime `json:"update"`
+	Error  string   `json:"error"`
+}
+
+// NewJobAttempt creates an attempt record based
+// on the current state of a job
+func NewJobAttempt(job GeneralJobInfo) JobAttempt {
+	cv := job.CompactVersion()
+	return JobAttempt{
+		Start:  cv.Start,
+		Update: cv.Update,
+		Error:  ErrorToString(job.GetError()),
+	}
+}
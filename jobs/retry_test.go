@@ -0,0 +1,90 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	rc := RetryConf{InitialBackoffSecs: 5, MaxBackoffSecs: 30}
+	assert.Equal(t, 5*time.Second, rc.Backoff(1))
+	assert.Equal(t, 10*time.Second, rc.Backoff(2))
+	assert.Equal(t, 20*time.Second, rc.Backoff(3))
+	assert.Equal(t, 30*time.Second, rc.Backoff(4))
+	assert.Equal(t, 30*time.Second, rc.Backoff(10))
+}
+
+func TestRetryableErrors(t *testing.T) {
+	rc := RetryConf{RetryableErrors: []string{"(?i)deadlock", "no such file"}}
+	assert.True(t, rc.IsRetryable(errors.New("Error 1213: Deadlock found when trying to get lock")))
+	assert.True(t, rc.IsRetryable(errors.New("open /data/vert.gz: no such file or directory")))
+	assert.False(t, rc.IsRetryable(errors.New("too many parsing errors")))
+	assert.False(t, rc.IsRetryable(nil))
+	assert.True(t, RetryConf{}.IsRetryable(errors.New("any error")))
+}
+
+func TestAsRetryKeepsHistory(t *testing.T) {
+	job := DummyJobInfo{ID: "1", Finished: true, Error: errors.New("deadlock")}
+	retried := job.AsRetry()
+	assert.False(t, retried.IsFinished())
+	assert.Nil(t, retried.GetError())
+	assert.Equal(t, 1, retried.GetNumRestarts())
+	assert.Equal(t, 1, len(retried.GetAttempts()))
+	assert.Equal(t, "deadlock", retried.GetAttempts()[0].Error)
+
+	upd := DummyJobInfo{ID: "1"}.WithRetryState(retried)
+	assert.Equal(t, 1, upd.GetNumRestarts())
+	assert.Equal(t, retried.GetAttempts(), upd.GetAttempts())
+}
+
+type appendingJobInfo struct {
+	DummyJobInfo
+	appendMode bool
+}
+
+func (j appendingJobInfo) IsIdempotent() bool {
+	return !j.appendMode
+}
+
+func TestNonIdempotentJobIsNotRetrySafe(t *testing.T) {
+	assert.True(t, isRetrySafe(DummyJobInfo{ID: "1"}))
+	assert.True(t, isRetrySafe(appendingJobInfo{DummyJobInfo: DummyJobInfo{ID: "1"}}))
+	assert.False(t, isRetrySafe(appendingJobInfo{DummyJobInfo: DummyJobInfo{ID: "1"}, appendMode: true}))
+}
+
+func TestInterruptedNonIdempotentJobIsNotRestarted(t *testing.T) {
+	var restarted []string
+	factory := func(job GeneralJobInfo) (*JobFunc, error) {
+		restarted = append(restarted, job.GetID())
+		fn := func(ctx context.Context, upd chan<- GeneralJobInfo) {}
+		return &fn, nil
+	}
+	a := &Actions{
+		jobFuncFactories: make(map[string]JobFuncFactory),
+		detachedParents:  make(map[string][]string),
+	}
+	a.RegisterJobFuncFactory(appendingJobInfo{}, factory)
+
+	err := a.restartDetachedJob(appendingJobInfo{DummyJobInfo: DummyJobInfo{ID: "1"}, appendMode: true})
+	assert.ErrorIs(t, err, ErrorJobInterrupted)
+	assert.Empty(t, restarted)
+}
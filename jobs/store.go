@@ -0,0 +1,117 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobs
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	ErrorStoreClosed = errors.New("job store is closed")
+)
+
+const (
+	StoreTypeFile  = "file"
+	StoreTypeMySQL = "mysql"
+)
+
+// StoreConf specifies how job information is persisted
+type StoreConf struct {
+
+	// Type is either "file" (default) or "mysql". The "file" type
+	// uses Conf.StatusDataPath as a path of a journal file. The "mysql"
+	// type uses the liveattrs database (see scripts/install.sql).
+	Type string `json:"type"`
+}
+
+// JobStore is a durable storage of job information. Each job status
+// change is written immediately so unfinished jobs can be recovered
+// even after a crash.
+type JobStore interface {
+
+	// Save inserts or updates the job
+	Save(job GeneralJobInfo) error
+
+	// SaveQueued inserts or updates a job which is enqueued
+	// but not running yet along with IDs of its parent jobs
+	SaveQueued(job GeneralJobInfo, parentIDs []string) error
+
+	// Remove removes the job from the store. Removing a non-existing
+	// job is not an error.
+	Remove(jobID string) error
+
+	// LoadAll loads all the stored jobs. Job types must be registered
+	// via RegisterJobInfoType.
+	LoadAll() (JobInfoList, error)
+
+	// LoadQueued provides IDs of stored jobs which have not been
+	// started yet. Values of the returned map are IDs of the respective
+	// parent jobs.
+	LoadQueued() (map[string][]string, error)
+
+	Close() error
+}
+
+// nullStore is used in case no persistence is configured
+type nullStore struct{}
+
+func (ns nullStore) Save(job GeneralJobInfo) error {
+	return nil
+}
+
+func (ns nullStore) SaveQueued(job GeneralJobInfo, parentIDs []string) error {
+	return nil
+}
+
+func (ns nullStore) Remove(jobID string) error {
+	return nil
+}
+
+func (ns nullStore) LoadAll() (JobInfoList, error) {
+	return JobInfoList{}, nil
+}
+
+func (ns nullStore) LoadQueued() (map[string][]string, error) {
+	return map[string][]string{}, nil
+}
+
+func (ns nullStore) Close() error {
+	return nil
+}
+
+// NewJobStore creates a job store based on the provided configuration.
+// The db argument is required only for the "mysql" store type.
+func NewJobStore(conf *Conf, db *sql.DB) (JobStore, error) {
+	switch conf.Store.Type {
+	case StoreTypeFile, "":
+		if conf.StatusDataPath == "" {
+			log.Warn().Msg("no status file specified, job information won't be persisted")
+			return nullStore{}, nil
+		}
+		return OpenFileJobStore(conf.StatusDataPath)
+	case StoreTypeMySQL:
+		if db == nil {
+			return nil, fmt.Errorf("cannot create job store - no database provided")
+		}
+		return NewMySQLJobStore(db), nil
+	default:
+		return nil, fmt.Errorf("unknown job store type: %s", conf.Store.Type)
+	}
+}
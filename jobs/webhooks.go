@@ -0,0 +1,359 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/czcorpus/cnc-gokit/uniresp"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	WebhookEventHeader     = "X-Frodo-Event"
+	WebhookJobIDHeader     = "X-Frodo-Job-Id"
+	WebhookSignatureHeader = "X-Frodo-Signature"
+
+	dfltWebhookTimeoutSecs = 10
+	dfltWebhookMaxAttempts = 5
+)
+
+var (
+	ErrorInvalidWebhookURL    = errors.New("invalid webhook URL")
+	ErrorMissingWebhookSecret = errors.New("missing webhook secret")
+)
+
+// WebhookConf describes a single HTTP callback
+type WebhookConf struct {
+	URL string `json:"url"`
+
+	// Secret is used to sign payloads (HMAC-SHA256). An empty value
+	// means that the global secret (WebhooksConf.Secret) is used.
+	Secret string `json:"secret,omitempty"`
+
+	// JobTypes limits a global webhook to the specified job types.
+	// An empty list means all the job types.
+	JobTypes []string `json:"jobTypes,omitempty"`
+}
+
+func (wc WebhookConf) Validate() error {
+	u, err := url.Parse(wc.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: %s", ErrorInvalidWebhookURL, wc.URL)
+	}
+	return nil
+}
+
+func (wc WebhookConf) acceptsJob(job GeneralJobInfo) bool {
+	return len(wc.JobTypes) == 0 || slices.Contains(wc.JobTypes, job.GetType())
+}
+
+// WebhooksConf configures HTTP notifications sent on job finish
+type WebhooksConf struct {
+
+	// Global webhooks are called for every finished job
+	Global []WebhookConf `json:"global"`
+
+	// Secret is the default secret for webhooks without their own one.
+	// Payloads are always signed so either this value or the webhook's
+	// own secret must be set.
+	Secret string `json:"secret"`
+
+	TimeoutSecs int `json:"timeoutSecs"`
+
+	// Retry specifies how failed deliveries are repeated. In case
+	// MaxAttempts is not set, dfltWebhookMaxAttempts is used.
+	Retry RetryConf `json:"retry"`
+}
+
+func (conf *WebhooksConf) Validate() error {
+	for _, wh := range conf.Global {
+		if err := wh.Validate(); err != nil {
+			return err
+		}
+		if conf.secretFor(wh) == "" {
+			return fmt.Errorf("%w for global webhook %s", ErrorMissingWebhookSecret, wh.URL)
+		}
+	}
+	if err := conf.Retry.Validate(); err != nil {
+		return fmt.Errorf("invalid webhook retry configuration: %w", err)
+	}
+	return nil
+}
+
+// secretFor returns the secret used to sign payloads sent to the webhook
+func (conf *WebhooksConf) secretFor(wh WebhookConf) string {
+	if wh.Secret != "" {
+		return wh.Secret
+	}
+	return conf.Secret
+}
+
+// WebhookSignature returns a signature of a payload as sent
+// in the WebhookSignatureHeader
+func WebhookSignature(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookSender delivers finished job information
+// to webhooks
+type webhookSender struct {
+	ctx         context.Context
+	client      *http.Client
+	maxAttempts int
+	backoff     func(attempt int) time.Duration
+}
+
+// send performs a single delivery attempt
+func (ws *webhookSender) send(wh WebhookConf, jobID, status string, payload []byte) error {
+	if wh.Secret == "" {
+		return ErrorMissingWebhookSecret
+	}
+	req, err := http.NewRequestWithContext(ws.ctx, http.MethodPost, wh.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, status)
+	req.Header.Set(WebhookJobIDHeader, jobID)
+	req.Header.Set(WebhookSignatureHeader, WebhookSignature(wh.Secret, payload))
+	resp, err := ws.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// deliver sends the payload to a webhook, retrying failed attempts.
+// The method blocks until the payload is delivered, all the attempts
+// fail or the sender's context is done.
+func (ws *webhookSender) deliver(wh WebhookConf, jobID, status string, payload []byte) error {
+	var err error
+	for attempt := 1; attempt <= ws.maxAttempts; attempt++ {
+		err = ws.send(wh, jobID, status, payload)
+		if err == nil {
+			return nil
+		}
+		if attempt == ws.maxAttempts {
+			break
+		}
+		log.Warn().
+			Err(err).
+			Str("jobId", jobID).
+			Str("url", wh.URL).
+			Int("attempt", attempt).
+			Msg("failed to deliver webhook notification, going to retry")
+		select {
+		case <-time.After(ws.backoff(attempt)):
+		case <-ws.ctx.Done():
+			return ws.ctx.Err()
+		}
+	}
+	return fmt.Errorf("failed to deliver webhook notification after %d attempts: %w", ws.maxAttempts, err)
+}
+
+// notify asynchronously sends a finished job to all the provided webhooks
+func (ws *webhookSender) notify(job GeneralJobInfo, webhooks []WebhookConf) {
+	if len(webhooks) == 0 {
+		return
+	}
+	payload, err := json.Marshal(job.FullInfo())
+	if err != nil {
+		log.Error().Err(err).Str("jobId", job.GetID()).Msg("failed to encode webhook payload")
+		return
+	}
+	status := FinalStatus(job)
+	for _, wh := range webhooks {
+		go func() {
+			if err := ws.deliver(wh, job.GetID(), status, payload); err != nil {
+				log.Error().Err(err).Str("jobId", job.GetID()).Str("url", wh.URL).Send()
+			}
+		}()
+	}
+}
+
+func newWebhookSender(ctx context.Context, conf WebhooksConf) *webhookSender {
+	timeout := conf.TimeoutSecs
+	if timeout == 0 {
+		timeout = dfltWebhookTimeoutSecs
+	}
+	maxAttempts := conf.Retry.MaxAttempts
+	if maxAttempts == 0 {
+		maxAttempts = dfltWebhookMaxAttempts
+	}
+	return &webhookSender{
+		ctx:         ctx,
+		client:      &http.Client{Timeout: time.Duration(timeout) * time.Second},
+		maxAttempts: maxAttempts,
+		backoff:     conf.Retry.Backoff,
+	}
+}
+
+// jobWebhooks returns all the webhooks (global and per-job ones)
+// which should be notified about the finished job
+func (a *Actions) jobWebhooks(job GeneralJobInfo) []WebhookConf {
+	ans := make([]WebhookConf, 0, len(a.conf.Webhooks.Global)+1)
+	for _, wh := range a.conf.Webhooks.Global {
+		if wh.acceptsJob(job) {
+			wh.Secret = a.conf.Webhooks.secretFor(wh)
+			ans = append(ans, wh)
+		}
+	}
+	a.webhooksLock.Lock()
+	defer a.webhooksLock.Unlock()
+	for _, wh := range a.webhooks[job.GetID()] {
+		wh.Secret = a.conf.Webhooks.secretFor(wh)
+		ans = append(ans, wh)
+	}
+	return ans
+}
+
+// webhookURLs returns URLs of per-job webhooks of a job
+func (a *Actions) webhookURLs(jobID string) []string {
+	a.webhooksLock.Lock()
+	defer a.webhooksLock.Unlock()
+	ans := make([]string, len(a.webhooks[jobID]))
+	for i, wh := range a.webhooks[jobID] {
+		ans[i] = wh.URL
+	}
+	return ans
+}
+
+// jobExists tests whether a job is either known or queued
+func (a *Actions) jobExists(jobID string) bool {
+	_, ok := a.GetJob(jobID)
+	return ok || a.IsQueued(jobID)
+}
+
+// AddWebhook godoc
+// @Summary      Add webhook called on job finish
+// @Description  Once the job is finished (successfully or not), its full info is POSTed to the URL as JSON. The payload is signed (HMAC-SHA256, the X-Frodo-Signature header) with the provided secret or with the globally configured one. In case neither is available, the webhook is rejected. Failed deliveries are retried. Webhooks cannot be added to already finished jobs.
+// @Accept       json
+// @Produce      json
+// @Param        jobId path string true "Job ID"
+// @Param        webhook body WebhookConf true "Webhook"
+// @Success      200 {object} any
+// @Failure      400 {object} uniresp.ActionError
+// @Failure      404 {object} uniresp.ActionError
+// @Failure      409 {object} uniresp.ActionError
+// @Router       /jobs/{jobId}/webhookNotification [put]
+func (a *Actions) AddWebhook(ctx *gin.Context) {
+	jobID := ctx.Param("jobId")
+	if !a.jobExists(jobID) {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError("job not found"), http.StatusNotFound)
+		return
+	}
+	var wh WebhookConf
+	if err := json.NewDecoder(ctx.Request.Body).Decode(&wh); err != nil {
+		uniresp.RespondWithErrorJSON(ctx, err, http.StatusBadRequest)
+		return
+	}
+	if err := wh.Validate(); err != nil {
+		uniresp.RespondWithErrorJSON(ctx, err, http.StatusBadRequest)
+		return
+	}
+	if a.conf.Webhooks.secretFor(wh) == "" {
+		uniresp.RespondWithErrorJSON(ctx, ErrorMissingWebhookSecret, http.StatusBadRequest)
+		return
+	}
+	wh.JobTypes = nil
+	a.webhooksLock.Lock()
+	// a job is marked as finished before its webhooks are notified
+	// (under the same lock) so we cannot miss the notification here
+	if job, ok := a.GetJob(jobID); ok && job.IsFinished() {
+		a.webhooksLock.Unlock()
+		uniresp.RespondWithErrorJSON(ctx, ErrorJobAlreadyFinished, http.StatusConflict)
+		return
+	}
+	webhooks := slices.DeleteFunc(
+		a.webhooks[jobID], func(item WebhookConf) bool { return item.URL == wh.URL })
+	a.webhooks[jobID] = append(webhooks, wh)
+	a.webhooksLock.Unlock()
+	resp := struct {
+		Registered bool `json:"registered"`
+	}{
+		Registered: true,
+	}
+	uniresp.WriteJSONResponse(ctx.Writer, resp)
+}
+
+// GetWebhooks godoc
+// @Summary      Get URLs of webhooks called on job finish
+// @Produce      json
+// @Param        jobId path string true "Job ID"
+// @Success      200 {object} any
+// @Failure      404 {object} uniresp.ActionError
+// @Router       /jobs/{jobId}/webhookNotification [get]
+func (a *Actions) GetWebhooks(ctx *gin.Context) {
+	jobID := ctx.Param("jobId")
+	if !a.jobExists(jobID) {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError("job not found"), http.StatusNotFound)
+		return
+	}
+	resp := struct {
+		URLs []string `json:"urls"`
+	}{
+		URLs: a.webhookURLs(jobID),
+	}
+	uniresp.WriteJSONResponse(ctx.Writer, resp)
+}
+
+// RemoveWebhook godoc
+// @Summary      Remove webhook called on job finish
+// @Produce      json
+// @Param        jobId path string true "Job ID"
+// @Param        url query string true "Webhook URL"
+// @Success      200 {object} any
+// @Failure      404 {object} uniresp.ActionError
+// @Router       /jobs/{jobId}/webhookNotification [delete]
+func (a *Actions) RemoveWebhook(ctx *gin.Context) {
+	jobID := ctx.Param("jobId")
+	if !a.jobExists(jobID) {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError("job not found"), http.StatusNotFound)
+		return
+	}
+	whURL := ctx.Query("url")
+	a.webhooksLock.Lock()
+	if webhooks, ok := a.webhooks[jobID]; ok {
+		a.webhooks[jobID] = slices.DeleteFunc(
+			webhooks, func(item WebhookConf) bool { return item.URL == whURL })
+	}
+	a.webhooksLock.Unlock()
+	resp := struct {
+		Registered bool `json:"registered"`
+	}{
+		Registered: false,
+	}
+	uniresp.WriteJSONResponse(ctx.Writer, resp)
+}
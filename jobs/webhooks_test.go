@@ -0,0 +1,154 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type webhookRequest struct {
+	event     string
+	signature string
+	body      []byte
+}
+
+func newTestWebhookSender(ctx context.Context, maxAttempts int) *webhookSender {
+	ans := newWebhookSender(ctx, WebhooksConf{Retry: RetryConf{MaxAttempts: maxAttempts}})
+	ans.backoff = func(attempt int) time.Duration { return time.Millisecond }
+	return ans
+}
+
+func TestWebhookDeliveryIsSignedAndRetried(t *testing.T) {
+	received := make(chan webhookRequest, 10)
+	numCalls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		numCalls++
+		if numCalls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(req.Body)
+		received <- webhookRequest{
+			event:     req.Header.Get(WebhookEventHeader),
+			signature: req.Header.Get(WebhookSignatureHeader),
+			body:      body,
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sender := newTestWebhookSender(ctx, 3)
+	job := DummyJobInfo{ID: "1", Type: "dummy-job", Finished: true, Error: errors.New("failed")}
+	sender.notify(job, []WebhookConf{{URL: server.URL, Secret: "s3cret"}})
+
+	select {
+	case req := <-received:
+		assert.Equal(t, JobStatusFailed, req.event)
+		assert.Equal(t, WebhookSignature("s3cret", req.body), req.signature)
+		var info map[string]any
+		assert.NoError(t, json.Unmarshal(req.body, &info))
+		assert.Equal(t, "1", info["id"])
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook not delivered")
+	}
+	assert.Equal(t, 3, numCalls)
+}
+
+func TestWebhookDeliveryGivesUp(t *testing.T) {
+	numCalls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		numCalls++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	sender := newTestWebhookSender(context.Background(), 2)
+	err := sender.deliver(WebhookConf{URL: server.URL, Secret: "s3cret"}, "1", JobStatusFinished, []byte("{}"))
+	assert.EqualError(
+		t, err, "failed to deliver webhook notification after 2 attempts: webhook responded with status 500")
+	assert.Equal(t, 2, numCalls)
+}
+
+func TestWebhookConfAcceptsJobTypes(t *testing.T) {
+	wh := WebhookConf{URL: "http://localhost/hook", JobTypes: []string{"ngram-generating"}}
+	assert.NoError(t, wh.Validate())
+	assert.True(t, wh.acceptsJob(DummyJobInfo{Type: "ngram-generating"}))
+	assert.False(t, wh.acceptsJob(DummyJobInfo{Type: "liveattrs"}))
+	assert.ErrorIs(t, WebhookConf{URL: "ftp://localhost"}.Validate(), ErrorInvalidWebhookURL)
+}
+
+func TestWebhooksConfRequiresSecret(t *testing.T) {
+	conf := WebhooksConf{Global: []WebhookConf{{URL: "http://localhost/hook"}}}
+	assert.ErrorIs(t, conf.Validate(), ErrorMissingWebhookSecret)
+	conf.Secret = "s3cret"
+	assert.NoError(t, conf.Validate())
+	conf.Secret = ""
+	conf.Global[0].Secret = "own-s3cret"
+	assert.NoError(t, conf.Validate())
+}
+
+func TestGlobalWebhookUsesGlobalSecret(t *testing.T) {
+	a := &Actions{
+		conf: &Conf{Webhooks: WebhooksConf{
+			Secret: "s3cret",
+			Global: []WebhookConf{{URL: "http://localhost/hook"}},
+		}},
+		webhooks: make(map[string][]WebhookConf),
+	}
+	hooks := a.jobWebhooks(DummyJobInfo{ID: "1", Type: "dummy-job"})
+	assert.Len(t, hooks, 1)
+	assert.Equal(t, "s3cret", hooks[0].Secret)
+}
+
+func TestAddWebhookRejectsFinishedJob(t *testing.T) {
+	a := &Actions{
+		conf: &Conf{Webhooks: WebhooksConf{Secret: "s3cret"}},
+		jobList: map[string]GeneralJobInfo{
+			"1": DummyJobInfo{ID: "1", Type: "dummy-job", Finished: true},
+			"2": DummyJobInfo{ID: "2", Type: "dummy-job"},
+		},
+		jobQueue: &JobQueue{},
+		webhooks: make(map[string][]WebhookConf),
+	}
+	addWebhook := func(jobID string) int {
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Params = gin.Params{{Key: "jobId", Value: jobID}}
+		ctx.Request = httptest.NewRequest(
+			http.MethodPut,
+			"/jobs/"+jobID+"/webhookNotification",
+			strings.NewReader(`{"url": "http://localhost/hook"}`),
+		)
+		a.AddWebhook(ctx)
+		return w.Code
+	}
+	assert.Equal(t, http.StatusConflict, addWebhook("1"))
+	assert.Empty(t, a.webhookURLs("1"))
+	assert.Equal(t, http.StatusOK, addWebhook("2"))
+	assert.Equal(t, []string{"http://localhost/hook"}, a.webhookURLs("2"))
+}
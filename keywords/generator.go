@@ -73,6 +73,7 @@ func generateKeywordsSync(
 	if err := vertigo.ParseVerticalFromScanner(ctx, vertScanner1, parserConf, tc1); err != nil {
 		status.Error = err
 		jobStatus <- status
+		return
 	}
 
 	// find keywords in 1 (ref)
@@ -88,6 +89,7 @@ func generateKeywordsSync(
 	if err := vertigo.ParseVerticalFromScanner(ctx, vertScanner1b, parserConf, processor1); err != nil {
 		status.Error = err
 		jobStatus <- status
+		return
 	}
 	processor1.Preview()
 
@@ -138,7 +140,15 @@ func generateKeywordsSync(
 	}
 }
 
-func RunJob(db *mysql.Adapter, datasetID string, args KeywordsBuildArgs, jobActions *jobs.Actions) (KeywordsBuildJob, error) {
+// RunJob enqueues a new keywords job. In case parentJobIDs are not empty,
+// the job will start after all the parents finish.
+func RunJob(
+	db *mysql.Adapter,
+	datasetID string,
+	args KeywordsBuildArgs,
+	jobActions *jobs.Actions,
+	parentJobIDs []string,
+) (KeywordsBuildJob, error) {
 	jobID, err := uuid.NewUUID()
 	if err != nil {
 		return KeywordsBuildJob{}, err
@@ -152,10 +162,16 @@ func RunJob(db *mysql.Adapter, datasetID string, args KeywordsBuildArgs, jobActi
 		Finished: false,
 		Args:     args,
 	}
-	fn := func(updateJobChan chan<- jobs.GeneralJobInfo) {
+	jobActions.EnqueueJobAfterAll(jobFunc(db, jobStatus), &jobStatus, parentJobIDs)
+	return jobStatus, nil
+}
+
+// jobFunc creates a function building keywords for the provided job
+func jobFunc(db *mysql.Adapter, jobStatus KeywordsBuildJob) *jobs.JobFunc {
+	args := jobStatus.Args
+	fn := func(jctx context.Context, updateJobChan chan<- jobs.GeneralJobInfo) {
 		statusChan := make(chan keywordsBuildStatus)
-		ctx := context.Background()
-		ctx, cancel := context.WithCancel(ctx)
+		ctx, cancel := context.WithCancel(jctx)
 		go func(runStatus KeywordsBuildJob) {
 			defer close(updateJobChan)
 			for statUpd := range statusChan {
@@ -201,6 +217,5 @@ func RunJob(db *mysql.Adapter, datasetID string, args KeywordsBuildArgs, jobActi
 		generateKeywordsSync(ctx, db, args, statusChan)
 		close(statusChan)
 	}
-	jobActions.EnqueueJob(&fn, &jobStatus)
-	return jobStatus, nil
+	return &fn
 }
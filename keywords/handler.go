@@ -3,6 +3,7 @@ package keywords
 import (
 	"context"
 	"errors"
+	"fmt"
 	"frodo/corpus"
 	"frodo/db/mysql"
 	"frodo/jobs"
@@ -68,8 +69,6 @@ type ActionHandler struct {
 
 	jobActions *jobs.Actions
 
-	jobCancel map[string]context.CancelFunc
-
 	laDB *mysql.Adapter
 
 	datasets corpus.MonitoringDatasets
@@ -111,7 +110,8 @@ func (handler *ActionHandler) ProcessKWOFWeek(ctx *gin.Context) {
 		},
 	}
 
-	job, err := RunJob(handler.laDB, dataset.Ident, args, handler.jobActions)
+	job, err := RunJob(
+		handler.laDB, dataset.Ident, args, handler.jobActions, ctx.QueryArray("parentJobId"))
 	if err != nil {
 		uniresp.RespondWithErrorJSON(ctx, err, http.StatusInternalServerError)
 		return
@@ -128,7 +128,8 @@ func (handler *ActionHandler) Process(ctx *gin.Context) {
 		return
 	}
 
-	job, err := RunJob(handler.laDB, datasetID, args, handler.jobActions)
+	job, err := RunJob(
+		handler.laDB, datasetID, args, handler.jobActions, ctx.QueryArray("parentJobId"))
 	if err != nil {
 		uniresp.RespondWithErrorJSON(ctx, err, http.StatusInternalServerError)
 		return
@@ -152,10 +153,24 @@ func (handler *ActionHandler) GetKWOFWeek(ctx *gin.Context) {
 	uniresp.WriteJSONResponse(ctx.Writer, kws)
 }
 
+// restartJob is a jobs.JobFuncFactory for detached keywords jobs
+func (handler *ActionHandler) restartJob(job jobs.GeneralJobInfo) (*jobs.JobFunc, error) {
+	switch tJob := job.(type) {
+	case *KeywordsBuildJob:
+		return jobFunc(handler.laDB, *tJob), nil
+	case KeywordsBuildJob:
+		return jobFunc(handler.laDB, tJob), nil
+	default:
+		return nil, fmt.Errorf("invalid keywords job type %T", job)
+	}
+}
+
 func NewActionHandler(laDB *mysql.Adapter, datasets corpus.MonitoringDatasets, jobActions *jobs.Actions) *ActionHandler {
-	return &ActionHandler{
+	handler := &ActionHandler{
 		jobActions: jobActions,
 		datasets:   datasets,
 		laDB:       laDB,
 	}
+	jobActions.RegisterJobFuncFactory(&KeywordsBuildJob{}, handler.restartJob)
+	return handler
 }
@@ -2,6 +2,7 @@ package keywords
 
 import (
 	"frodo/jobs"
+	"slices"
 	"time"
 )
 
@@ -40,8 +41,10 @@ type KeywordsBuildJob struct {
 	Start       jobs.JSONTime       `json:"start"`
 	Update      jobs.JSONTime       `json:"update"`
 	Finished    bool                `json:"finished"`
+	Cancelled   bool                `json:"cancelled"`
 	Error       error               `json:"error,omitempty"`
 	NumRestarts int                 `json:"numRestarts"`
+	Attempts    []jobs.JobAttempt   `json:"attempts,omitempty"`
 	Args        KeywordsBuildArgs   `json:"args"`
 	Result      keywordsBuildStatus `json:"result"`
 }
@@ -80,6 +83,46 @@ func (j KeywordsBuildJob) IsFinished() bool {
 	return j.Finished
 }
 
+func (j KeywordsBuildJob) GetProgress() jobs.JobProgress {
+	return jobs.JobProgress{
+		ProcessedLines: j.Result.NumProcLines,
+		TotalLines:     j.Result.TotalLines,
+	}
+}
+
+func (j KeywordsBuildJob) IsCancelled() bool {
+	return j.Cancelled
+}
+
+func (j KeywordsBuildJob) AsCancelled() jobs.GeneralJobInfo {
+	j.Update = jobs.CurrentDatetime()
+	j.Finished = true
+	j.Cancelled = true
+	j.Error = nil
+	return j
+}
+
+func (j KeywordsBuildJob) GetAttempts() []jobs.JobAttempt {
+	return j.Attempts
+}
+
+func (j KeywordsBuildJob) AsRetry() jobs.GeneralJobInfo {
+	j.Attempts = append(slices.Clone(j.Attempts), jobs.NewJobAttempt(j))
+	j.NumRestarts++
+	j.Start = jobs.CurrentDatetime()
+	j.Update = j.Start
+	j.Finished = false
+	j.Error = nil
+	return j
+}
+
+func (j KeywordsBuildJob) WithRetryState(prev jobs.GeneralJobInfo) jobs.GeneralJobInfo {
+	j.Start = prev.GetStartDT()
+	j.NumRestarts = prev.GetNumRestarts()
+	j.Attempts = prev.GetAttempts()
+	return j
+}
+
 func (j KeywordsBuildJob) FullInfo() any {
 	return struct {
 		ID          string              `json:"id"`
@@ -88,9 +131,11 @@ func (j KeywordsBuildJob) FullInfo() any {
 		Start       jobs.JSONTime       `json:"start"`
 		Update      jobs.JSONTime       `json:"update"`
 		Finished    bool                `json:"finished"`
+		Cancelled   bool                `json:"cancelled"`
 		Error       string              `json:"error,omitempty"`
 		OK          bool                `json:"ok"`
 		NumRestarts int                 `json:"numRestarts"`
+		Attempts    []jobs.JobAttempt   `json:"attempts,omitempty"`
 		Args        KeywordsBuildArgs   `json:"args"`
 		Result      keywordsBuildStatus `json:"result"`
 	}{
@@ -100,9 +145,11 @@ func (j KeywordsBuildJob) FullInfo() any {
 		Start:       j.Start,
 		Update:      j.Update,
 		Finished:    j.Finished,
+		Cancelled:   j.Cancelled,
 		Error:       jobs.ErrorToString(j.Error),
-		OK:          j.Error == nil,
+		OK:          j.Error == nil && !j.Cancelled,
 		NumRestarts: j.NumRestarts,
+		Attempts:    j.Attempts,
 		Args:        j.Args,
 		Result:      j.Result,
 	}
@@ -110,15 +157,16 @@ func (j KeywordsBuildJob) FullInfo() any {
 
 func (j KeywordsBuildJob) CompactVersion() jobs.JobInfoCompact {
 	item := jobs.JobInfoCompact{
-		ID:       j.ID,
-		Type:     j.Type,
-		CorpusID: j.CorpusID,
-		Start:    j.Start,
-		Update:   j.Update,
-		Finished: j.Finished,
-		OK:       true,
+		ID:        j.ID,
+		Type:      j.Type,
+		CorpusID:  j.CorpusID,
+		Start:     j.Start,
+		Update:    j.Update,
+		Finished:  j.Finished,
+		Cancelled: j.Cancelled,
+		OK:        true,
 	}
-	item.OK = j.Error == nil
+	item.OK = j.Error == nil && !j.Cancelled
 	return item
 }
 
@@ -135,7 +183,9 @@ func (j KeywordsBuildJob) WithError(err error) jobs.GeneralJobInfo {
 		Update:      jobs.JSONTime(time.Now()),
 		Finished:    true,
 		Error:       err,
+		Args:        j.Args,
 		Result:      j.Result,
 		NumRestarts: j.NumRestarts,
+		Attempts:    j.Attempts,
 	}
 }
@@ -25,6 +25,7 @@ import (
 	"frodo/liveattrs/request/response"
 	"frodo/liveattrs/utils"
 	"reflect"
+	"strconv"
 
 	"github.com/czcorpus/cnc-gokit/collections"
 	"github.com/rs/zerolog/log"
@@ -69,6 +70,10 @@ func (a *Actions) getAttrValues(
 	if err != nil {
 		return nil, err
 	}
+	attrTypes, err := a.laConfCache.GetAttrTypes(corpusInfo.Name)
+	if err != nil {
+		return nil, err
+	}
 	srchAttrs := collections.NewSet(laconf.GetSubcorpAttrs(laConf)...)
 	expandAttrs := collections.NewSet[string]()
 	if corpusInfo.BibLabelAttr != "" {
@@ -98,6 +103,8 @@ func (a *Actions) getAttrValues(
 		AlignedCorpora:      qry.Aligned,
 		AutocompleteAttr:    qry.AutocompleteAttr,
 		EmptyValPlaceholder: emptyValuePlaceholder,
+		AttrTypes:           attrTypes,
+		Filter:              qry.Filter,
 	}
 	dataIterator := laquery.DataIterator{
 		DB:      a.laDB.DB(),
@@ -110,7 +117,14 @@ func (a *Actions) getAttrValues(
 	}
 
 	for _, sattr := range qBuilder.SearchAttrs {
-		ans.AttrValues[sattr] = make([]*response.ListedValue, 0, 100)
+		// numeric attributes are summarized by their min/max
+		// values instead of (typically huge) value lists
+		if attrTypes.IsNumeric(sattr) && !expandAttrs.Contains(utils.ImportKey(sattr)) {
+			ans.AttrValues[sattr] = &response.RangeValue{}
+
+		} else {
+			ans.AttrValues[sattr] = make([]*response.ListedValue, 0, 100)
+		}
 	}
 	// 1) values collected one by one are collected in tmp_ans and then moved to 'ans'
 	//    with some exporting tweaks
@@ -151,6 +165,10 @@ func (a *Actions) getAttrValues(
 					attrVal.Count = row.Poscount
 					tmpAns[colKey][attrVal.ID] = &attrVal
 				}
+			case *response.RangeValue:
+				if v, err := strconv.ParseFloat(dbVal, 64); err == nil {
+					tColVal.Add(v)
+				}
 			case int:
 				ans.AttrValues[colKey] = tColVal + row.Poscount
 			case nil:
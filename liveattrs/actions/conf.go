@@ -21,9 +21,11 @@ import (
 	"fmt"
 	"frodo/corpus"
 	"frodo/liveattrs/laconf"
+	"frodo/sharedcache"
 	"io"
 	"net/http"
 	"path/filepath"
+	"strconv"
 
 	"github.com/czcorpus/cnc-gokit/uniresp"
 	"github.com/czcorpus/mquery-common/corp"
@@ -165,11 +167,16 @@ func (a *Actions) CreateConf(ctx *gin.Context) {
 		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusBadRequest)
 		return
 	}
-	err = a.laConfCache.Save(newConf)
+	err = a.laConfCache.SaveWithAttrTypes(newConf, jsonArgs.AttrTypes, ctx.GetHeader(laconf.AuthorHeader))
 	if err != nil {
 		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusBadRequest)
 		return
 	}
+	if jsonArgs.AttrTypes != nil {
+		// attribute types affect how cached query results were created
+		a.invalidateDataCaches(corpusID)
+	}
+	a.publishInvalidation(sharedcache.InvalidateLiveAttrsConf, corpusID)
 	expConf := newConf.WithoutPasswords()
 	uniresp.WriteJSONResponse(ctx.Writer, &expConf)
 }
@@ -182,7 +189,10 @@ func (a *Actions) CreateConf(ctx *gin.Context) {
 // @Success      200 {object} any
 // @Router       /liveAttributes/{corpusId}/confCache [delete]
 func (a *Actions) FlushCache(ctx *gin.Context) {
-	ok := a.laConfCache.Uncache(ctx.Param("corpusId"))
+	corpusID := ctx.Param("corpusId")
+	ok := a.laConfCache.Uncache(corpusID)
+	// other instances may have the config cached even if this one does not
+	a.publishInvalidation(sharedcache.InvalidateLiveAttrsConf, corpusID)
 	if !ok {
 		uniresp.RespondWithErrorJSON(ctx, fmt.Errorf("config not in cache"), http.StatusNotFound)
 		return
@@ -276,7 +286,16 @@ func (a *Actions) PatchConfig(ctx *gin.Context) {
 		return
 	}
 
-	a.laConfCache.Save(conf)
+	err = a.laConfCache.SaveWithAttrTypes(conf, jsonArgs.AttrTypes, ctx.GetHeader(laconf.AuthorHeader))
+	if err != nil {
+		uniresp.RespondWithErrorJSON(ctx, err, http.StatusInternalServerError)
+		return
+	}
+	if jsonArgs.AttrTypes != nil {
+		// attribute types affect how cached query results were created
+		a.invalidateDataCaches(corpusID)
+	}
+	a.publishInvalidation(sharedcache.InvalidateLiveAttrsConf, corpusID)
 	out := conf.WithoutPasswords()
 	uniresp.WriteJSONResponse(ctx.Writer, &out)
 }
@@ -314,3 +333,89 @@ func (a *Actions) QSDefaults(ctx *gin.Context) {
 
 	uniresp.WriteJSONResponse(ctx.Writer, ans)
 }
+
+// ConfHistory godoc
+// @Summary      ConfHistory lists stored versions of a liveattrs configuration
+// @Description  Each saved configuration is stored as a new version along with its author (X-Frodo-User header) and changes compared with the previous version. The list does not contain the configurations themselves.
+// @Produce      json
+// @Param        corpusId path string true "Used corpus"
+// @Success      200 {object} []laconf.ConfVersion
+// @Router       /liveAttributes/{corpusId}/conf/history [get]
+func (a *Actions) ConfHistory(ctx *gin.Context) {
+	corpusID := ctx.Param("corpusId")
+	baseErrTpl := "failed to get liveattrs conf history for %s: %w"
+	ans, err := a.laConfCache.History(corpusID)
+	if err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
+		return
+	}
+	uniresp.WriteJSONResponse(ctx.Writer, ans)
+}
+
+// confVersionArg parses the version URL parameter
+func confVersionArg(ctx *gin.Context) (int, error) {
+	version, err := strconv.Atoi(ctx.Param("version"))
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("invalid version: %s", ctx.Param("version"))
+	}
+	return version, nil
+}
+
+// ConfVersion godoc
+// @Summary      ConfVersion shows a stored version of a liveattrs configuration
+// @Description  ConfVersion shows a stored version of a liveattrs configuration. Note: passwords are replaced with multiple asterisk characters.
+// @Produce      json
+// @Param        corpusId path string true "Used corpus"
+// @Param        version path int true "Configuration version"
+// @Success      200 {object} laconf.ConfVersion
+// @Router       /liveAttributes/{corpusId}/conf/history/{version} [get]
+func (a *Actions) ConfVersion(ctx *gin.Context) {
+	corpusID := ctx.Param("corpusId")
+	baseErrTpl := "failed to get liveattrs conf version for %s: %w"
+	version, err := confVersionArg(ctx)
+	if err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusBadRequest)
+		return
+	}
+	ans, err := a.laConfCache.GetVersion(corpusID, version)
+	if err == laconf.ErrorNoSuchVersion {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusNotFound)
+		return
+
+	} else if err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
+		return
+	}
+	uniresp.WriteJSONResponse(ctx.Writer, ans)
+}
+
+// RollbackConf godoc
+// @Summary      RollbackConf restores a stored version of a liveattrs configuration
+// @Description  The restored configuration (including attribute types) is saved as a new version so the rollback itself can be reverted. Liveattrs data are not rebuilt.
+// @Produce      json
+// @Param        corpusId path string true "Used corpus"
+// @Param        version path int true "Configuration version to restore"
+// @Success      200 {object} laconf.ConfVersion
+// @Router       /liveAttributes/{corpusId}/conf/history/{version}/rollback [post]
+func (a *Actions) RollbackConf(ctx *gin.Context) {
+	corpusID := ctx.Param("corpusId")
+	baseErrTpl := "failed to roll back liveattrs conf of %s: %w"
+	version, err := confVersionArg(ctx)
+	if err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusBadRequest)
+		return
+	}
+	ans, err := a.laConfCache.Rollback(corpusID, version, ctx.GetHeader(laconf.AuthorHeader))
+	if err == laconf.ErrorNoSuchVersion {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusNotFound)
+		return
+
+	} else if err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
+		return
+	}
+	a.publishInvalidation(sharedcache.InvalidateLiveAttrsConf, corpusID)
+	// the restored version may come with different attribute types
+	a.invalidateDataCaches(corpusID)
+	uniresp.WriteJSONResponse(ctx.Writer, ans)
+}
@@ -21,14 +21,17 @@ import (
 	"frodo/jobs"
 	"frodo/liveattrs"
 	"frodo/liveattrs/db"
-	_ "frodo/liveattrs/laconf"
+	"frodo/liveattrs/laconf"
+	"frodo/sharedcache"
 	"net/http"
+	"slices"
 
 	"github.com/czcorpus/cnc-gokit/uniresp"
 	vteCnf "github.com/czcorpus/vert-tagextract/v3/cnf"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
 )
 
 // Create starts a process of creating fresh liveattrs data for a a specified corpus.
@@ -53,6 +56,7 @@ import (
 // @Param 		 patchArgs body laconf.PatchArgs true "The input todo struct"
 // @Param 		 reconfigure query int false "Ignore the stored liveattrs config (if any) and generate a new one based on corpus properties and provided PatchArgs. The resulting new config will be stored replacing the previous one." default(0)
 // @Param 		 append query int false "Append mode" default(0)
+// @Param        parentJobId query []string false "Run the job once the specified jobs finish" collectionFormat(multi)
 // @Success      200 {object} any
 // @Router       /liveAttributes/{corpusId}/data [post]
 func (a *Actions) Create(ctx *gin.Context) {
@@ -104,7 +108,7 @@ func (a *Actions) Create(ctx *gin.Context) {
 			return
 		}
 
-		err = a.laConfCache.Save(newConf)
+		err = a.laConfCache.Save(newConf, ctx.GetHeader(laconf.AuthorHeader))
 		if err != nil {
 			uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusBadRequest)
 			return
@@ -127,6 +131,10 @@ func (a *Actions) Create(ctx *gin.Context) {
 			ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusConflict)
 		return
 	}
+	confCorpusID := corpusID
+	if aliasOf != "" {
+		confCorpusID = aliasOf
+	}
 
 	// TODO search collisions only in liveattrs type jobs
 	jobID, err := uuid.NewUUID()
@@ -135,7 +143,12 @@ func (a *Actions) Create(ctx *gin.Context) {
 		return
 	}
 
-	if prevRunning, ok := a.jobActions.LastUnfinishedJobOfType(corpusID, liveattrs.JobType); ok {
+	// a job waiting for its parent cannot collide with it
+	parentJobIDs := ctx.QueryArray("parentJobId")
+	unlock := a.lockCorpus(corpusID)
+	defer unlock()
+	prevRunning, ok := a.jobActions.LastUnfinishedJobOfType(corpusID, liveattrs.JobType)
+	if ok && !slices.Contains(parentJobIDs, prevRunning.GetID()) {
 		err := fmt.Errorf("the previous job %s not finished yet", prevRunning.GetID())
 		uniresp.WriteJSONErrorResponse(
 			ctx.Writer,
@@ -144,6 +157,23 @@ func (a *Actions) Create(ctx *gin.Context) {
 		)
 		return
 	}
+	if jsonArgs.AttrTypes != nil {
+		err := a.laConfCache.SaveAttrTypes(confCorpusID, jsonArgs.AttrTypes, ctx.GetHeader(laconf.AuthorHeader))
+		if err != nil {
+			uniresp.WriteJSONErrorResponse(
+				ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
+			return
+		}
+		// attribute types affect how cached query results were created
+		a.invalidateDataCaches(corpusID)
+		a.publishInvalidation(sharedcache.InvalidateLiveAttrsConf, confCorpusID)
+	}
+	confVersion, err := a.laConfCache.CurrentVersion(confCorpusID)
+	if err != nil {
+		uniresp.WriteJSONErrorResponse(
+			ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
+		return
+	}
 
 	append := ctx.Request.URL.Query().Get("append")
 	status := &liveattrs.LiveAttrsJobInfo{
@@ -157,9 +187,10 @@ func (a *Actions) Create(ctx *gin.Context) {
 			NoCorpusDBUpdate: aliasOf != "",
 			TagsetAttr:       jsonArgs.GetTagsetAttr(),
 			TagsetName:       jsonArgs.GetTagsetName(),
+			ConfVersion:      confVersion,
 		},
 	}
-	a.generateData(status)
+	a.generateData(status, parentJobIDs)
 	uniresp.WriteJSONResponseWithStatus(ctx.Writer, http.StatusCreated, status.FullInfo())
 }
 
@@ -228,7 +259,7 @@ func (a *Actions) Delete(ctx *gin.Context) {
 
 // CleanTmpTables godoc
 // @Summary      Remove [corpus]_[data type]_new tables
-// @Description  Can be used to reset broken import process. It is allowed to run only if no other job is running.
+// @Description  Can be used to reset broken import process. Shadow tables of an unfinished rebuild are removed as well. It is allowed to run only if no other job is running.
 // @Produce      json
 // @Param        corpusId path string true "Used corpus"
 // @Success      200 {object} vteCnf.NgramConf
@@ -245,19 +276,123 @@ func (a *Actions) CleanTmpTables(ctx *gin.Context) {
 
 	corpusID := ctx.Param("corpusId")
 
-	if _, err := a.laDB.DB().Exec(
-		fmt.Sprintf("DROP TABLE IF EXISTS %s_liveattrs_entry_new", corpusID),
-	); err != nil {
+	if err := db.DropTmpTables(a.laDB.DB(), corpusID); err != nil {
 		uniresp.RespondWithErrorJSON(ctx, err, http.StatusInternalServerError)
 		return
 	}
-
-	if _, err := a.laDB.DB().Exec(
-		fmt.Sprintf("DROP TABLE IF EXISTS %s_colcounts_new", corpusID),
-	); err != nil {
+	if err := db.DropStagingTables(a.laDB.DB(), db.ShadowName(corpusID)); err != nil {
 		uniresp.RespondWithErrorJSON(ctx, err, http.StatusInternalServerError)
 		return
 	}
 
 	uniresp.WriteJSONResponse(ctx.Writer, map[string]bool{"ok": true})
 }
+
+// dataRollbackResponse describes liveattrs data and configuration
+// which are live after a data rollback
+type dataRollbackResponse struct {
+	OK bool `json:"ok"`
+
+	// DataVersion identifies the restored data (zero values for data
+	// built before the versions were recorded)
+	DataVersion db.DataVersion `json:"dataVersion"`
+
+	// ConfVersion is the current version of the liveattrs configuration
+	ConfVersion int `json:"confVersion"`
+
+	// RestoredConf is set in case the configuration had to be rolled back
+	// to the version the restored data were built with
+	RestoredConf *laconf.ConfVersion `json:"restoredConf,omitempty"`
+
+	// IndexJob is a job updating indexes of the restored data (if enabled)
+	IndexJob *db.IndexJobInfo `json:"indexJob,omitempty"`
+}
+
+// Rollback godoc
+// @Summary      Roll back liveattrs data to the previous version
+// @Description  Each liveattrs rebuild keeps the replaced tables as a previous version. The action exchanges the current tables with the previous ones so calling it again reverts the rollback. It is not allowed while a liveattrs job of the corpus is running. In case the restored data were built with a different version of the liveattrs configuration, the configuration is rolled back to that version too. Unless disabled, indexes of the restored data are updated by a new job. The response describes the data and configuration versions which are live after the rollback.
+// @Produce      json
+// @Param        corpusId path string true "Used corpus"
+// @Success      200 {object} dataRollbackResponse
+// @Router       /liveAttributes/{corpusId}/data/rollback [post]
+func (a *Actions) Rollback(ctx *gin.Context) {
+	corpusID := ctx.Param("corpusId")
+	baseErrTpl := "failed to roll back liveattrs of %s: %w"
+	unlock := a.lockCorpus(corpusID)
+	defer unlock()
+	if prev, ok := a.jobActions.LastUnfinishedJobOfType(corpusID, liveattrs.JobType); ok {
+		err := fmt.Errorf("the job %s not finished yet", prev.GetID())
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusConflict)
+		return
+	}
+	conf, err := a.laConfCache.Get(corpusID)
+	if err == laconf.ErrorNoSuchConfig {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusNotFound)
+		return
+
+	} else if err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
+		return
+	}
+	groupedName := vteGroupedName(conf)
+	err = db.RollbackSwap(a.laDB.DB(), groupedName)
+	if err == db.ErrorNoPrevVersion {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusConflict)
+		return
+
+	} else if err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
+		return
+	}
+	a.invalidateDataCaches(corpusID)
+	a.checkSubcorpora(corpusID)
+
+	// the tables are already swapped so errors below are reported
+	// but do not revert the rollback
+	var ans dataRollbackResponse
+	ans.OK = true
+	ans.DataVersion, err = db.GetDataVersion(a.laDB.DB(), groupedName)
+	if err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
+		return
+	}
+	ans.ConfVersion, err = a.laConfCache.CurrentVersion(corpusID)
+	if err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
+		return
+	}
+	if ans.DataVersion.ConfVersion > 0 && ans.DataVersion.ConfVersion != ans.ConfVersion {
+		curr, err := a.laConfCache.GetVersion(corpusID, ans.ConfVersion)
+		if err != nil {
+			uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
+			return
+		}
+		if curr.RestoredFrom != ans.DataVersion.ConfVersion {
+			ans.RestoredConf, err = a.laConfCache.Rollback(
+				corpusID, ans.DataVersion.ConfVersion, ctx.GetHeader(laconf.AuthorHeader))
+			if err != nil {
+				uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
+				return
+			}
+			ans.ConfVersion = ans.RestoredConf.Version
+			a.publishInvalidation(sharedcache.InvalidateLiveAttrsConf, corpusID)
+		}
+	}
+	if !a.conf.LA.AutoIndexes.Disabled {
+		ans.IndexJob = a.enqueueIndexUpdate(
+			corpusID,
+			db.IndexJobInfoArgs{
+				MaxColumns: a.conf.LA.AutoIndexes.GetMaxColumns(),
+				MinUsage:   a.conf.LA.AutoIndexes.GetMinUsage(),
+			},
+			[]string{},
+		)
+	}
+	log.Info().
+		Str("corpusId", corpusID).
+		Str("dataJobId", ans.DataVersion.JobID).
+		Int("dataConfVersion", ans.DataVersion.ConfVersion).
+		Int("confVersion", ans.ConfVersion).
+		Msg("rolled back liveattrs data")
+	uniresp.WriteJSONResponse(ctx.Writer, ans)
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"frodo/jobs"
+	"frodo/liveattrs"
+	"frodo/liveattrs/cache"
+	"frodo/liveattrs/laconf"
+	"frodo/sharedcache"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+
+	vteCnf "github.com/czcorpus/vert-tagextract/v3/cnf"
+	vteDB "github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateSavesAttrTypes(t *testing.T) {
+	// cancelled context prevents the job queue from running the created job
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	confDir := t.TempDir()
+	laConfCache := laconf.NewLiveAttrsBuildConfProvider(confDir, &vteDB.Conf{Type: "mysql"})
+	assert.NoError(t, laConfCache.Save(
+		&vteCnf.VTEConf{
+			Corpus:        "syn",
+			AtomStructure: "doc",
+			Structures:    map[string][]string{"doc": {"id", "pubyear"}},
+			VerticalFile:  "/var/opt/corpora/syn.vert",
+			DB:            vteDB.Conf{Type: "mysql"},
+		},
+		"alice",
+	))
+	jobStore, err := jobs.NewJobStore(&jobs.Conf{}, nil)
+	assert.NoError(t, err)
+	a := &Actions{
+		conf:        LAConf{LA: &liveattrs.Conf{AutoIndexes: liveattrs.AutoIndexesConf{Disabled: true}}},
+		ctx:         ctx,
+		jobActions:  jobs.NewActions(&jobs.Conf{}, "en", ctx, jobStore, jobs.NewJobHistory(nil)),
+		laConfCache: laConfCache,
+		eqCache:     cache.NewEmptyQueryCache(),
+		qCache:      cache.NewQueryCache(cache.QueryCacheConf{}),
+		sharedCache: sharedcache.NewMemoryCache(),
+		corpusLocks: make(map[string]*sync.Mutex),
+	}
+
+	w := httptest.NewRecorder()
+	gctx, _ := gin.CreateTestContext(w)
+	gctx.Params = gin.Params{{Key: "corpusId", Value: "syn"}}
+	gctx.Request = httptest.NewRequest(
+		http.MethodPost,
+		"/liveAttributes/syn/data",
+		strings.NewReader(`{"attrTypes": {"doc.pubyear": "int"}}`),
+	)
+	a.Create(gctx)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	rawData, err := os.ReadFile(path.Join(confDir, "syn.types.json"))
+	assert.NoError(t, err)
+	var types laconf.AttrTypes
+	assert.NoError(t, json.Unmarshal(rawData, &types))
+	assert.Equal(t, laconf.AttrTypes{"doc.pubyear": laconf.AttrTypeInt}, types)
+}
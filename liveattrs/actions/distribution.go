@@ -0,0 +1,130 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package actions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"frodo/corpus"
+	"frodo/liveattrs/db/qbuilder/laquery"
+	"frodo/liveattrs/laconf"
+	"frodo/liveattrs/request/distribution"
+	"frodo/liveattrs/request/response"
+	"frodo/liveattrs/utils"
+	"net/http"
+
+	"github.com/czcorpus/cnc-gokit/collections"
+	"github.com/czcorpus/cnc-gokit/uniresp"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	ErrorUnknownDistAttr = errors.New("unknown distribution attribute")
+)
+
+// getDistribution calculates full (i.e. not cut off) token and document
+// counts of the requested attributes' values within the selection
+func (a *Actions) getDistribution(
+	corpusInfo *corpus.DBInfo,
+	args distribution.Payload,
+) (*response.Distribution, error) {
+	laConf, err := a.laConfCache.Get(corpusInfo.Name)
+	if err != nil {
+		return nil, err
+	}
+	availAttrs := collections.NewSet(laconf.GetSubcorpAttrs(laConf)...)
+	for _, attr := range args.Groups {
+		if !availAttrs.Contains(attr) {
+			return nil, fmt.Errorf("%w: %s", ErrorUnknownDistAttr, attr)
+		}
+	}
+	attrTypes, err := a.laConfCache.GetAttrTypes(corpusInfo.Name)
+	if err != nil {
+		return nil, err
+	}
+	dataIterator := laquery.DataIterator{
+		DB: a.laDB.DB(),
+		Builder: &laquery.LAFilter{
+			CorpusInfo:          corpusInfo,
+			AttrMap:             args.Attrs,
+			SearchAttrs:         args.Groups,
+			AlignedCorpora:      args.Aligned,
+			EmptyValPlaceholder: emptyValuePlaceholder,
+			AttrTypes:           attrTypes,
+		},
+	}
+	builder := response.NewDistributionBuilder(args.Groups, args.CrossTab, emptyValuePlaceholder)
+	values := make(map[string]string, len(args.Groups))
+	err = dataIterator.Iterate(func(row laquery.ResultRow) error {
+		clear(values)
+		for _, attr := range args.Groups {
+			if v, ok := row.Attrs[utils.ImportKey(attr)]; ok {
+				values[attr] = v
+			}
+		}
+		builder.Add(values, row.Poscount)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate distribution: %w", err)
+	}
+	return builder.Result(), nil
+}
+
+// Distribution godoc
+// @Summary      Calculate frequency distribution of text type values
+// @Description  Calculate token and document counts of values of specified structural attributes within a text type selection. For two attributes, a cross-tabulation can be requested.
+// @Accept  	 json
+// @Produce      json
+// @Param        corpusId path string true "An ID of a corpus"
+// @Param 		 queryArgs body distribution.Payload true "Distribution arguments"
+// @Success      200 {object} response.Distribution
+// @Router       /liveAttributes/{corpusId}/distribution [post]
+func (a *Actions) Distribution(ctx *gin.Context) {
+	corpusID := ctx.Param("corpusId")
+	baseErrTpl := "failed to calculate distribution in corpus %s: %w"
+	var args distribution.Payload
+	if err := json.NewDecoder(ctx.Request.Body).Decode(&args); err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusBadRequest)
+		return
+	}
+	if err := args.Validate(); err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusBadRequest)
+		return
+	}
+	corpInfo, err := a.corpusMeta.LoadInfo(corpusID)
+	if err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
+		return
+	}
+	ans, err := a.getDistribution(corpInfo, args)
+	if err == laconf.ErrorNoSuchConfig {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusNotFound)
+		return
+
+	} else if errors.Is(err, ErrorUnknownDistAttr) {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusBadRequest)
+		return
+
+	} else if err != nil {
+		log.Error().Str("corpusId", corpusID).Err(err).Msg("failed to calculate distribution")
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
+		return
+	}
+	uniresp.WriteJSONResponse(ctx.Writer, ans)
+}
@@ -18,8 +18,12 @@ package actions
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"frodo/corpus"
 	"frodo/liveattrs/db"
+	"frodo/liveattrs/docexport"
+	"frodo/liveattrs/laconf"
 	"frodo/liveattrs/request/biblio"
 	"frodo/liveattrs/request/query"
 	"io"
@@ -29,6 +33,7 @@ import (
 
 	"github.com/czcorpus/cnc-gokit/uniresp"
 	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
 )
 
 var (
@@ -128,6 +133,7 @@ func isValidAttr(a string) bool {
 // @Param        attr query []string true "???"
 // @Param        page query int false "Page" default(1)
 // @Param        pageSize query int false "Page size" default(0)
+// @Param        format query string false "Export all the matching documents as a file (csv, tsv, jsonl, xlsx); pagination is ignored"
 // @Success      200 {object} []db.DocumentRow
 // @Router       /liveAttributes/{corpusId}/documentList [post]
 func (a *Actions) DocumentList(ctx *gin.Context) {
@@ -206,7 +212,22 @@ func (a *Actions) DocumentList(ctx *gin.Context) {
 	if err != nil && err != io.EOF {
 		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusBadRequest)
 		return
+	}
+	if err := qry.Filter.Validate(); err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusBadRequest)
+		return
+
+	}
 
+	attrTypes, err := a.laConfCache.GetAttrTypes(corpusID)
+	if err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
+		return
+	}
+
+	if format := ctx.Query("format"); format != "" {
+		a.exportDocuments(ctx, corpInfo, format, qry, attrTypes)
+		return
 	}
 
 	var ans []*db.DocumentRow
@@ -216,6 +237,8 @@ func (a *Actions) DocumentList(ctx *gin.Context) {
 		ctx.Request.URL.Query()["attr"],
 		qry.Aligned,
 		qry.Attrs,
+		attrTypes,
+		qry.Filter,
 		pginfo,
 	)
 	if err != nil {
@@ -229,6 +252,68 @@ func (a *Actions) DocumentList(ctx *gin.Context) {
 	uniresp.WriteJSONResponse(ctx.Writer, ans)
 }
 
+// exportDocuments streams all the documents matching the query
+// as a file in the specified format
+func (a *Actions) exportDocuments(
+	ctx *gin.Context,
+	corpInfo *corpus.DBInfo,
+	format string,
+	qry query.Payload,
+	attrTypes laconf.AttrTypes,
+) {
+	baseErrTpl := "failed to export document list from %s: %w"
+	viewAttrs := ctx.Request.URL.Query()["attr"]
+	// note: the writers do not send any data to ctx.Writer before
+	// the first flush so we can still set the headers afterwards
+	writer, err := docexport.NewWriter(format, ctx.Writer, viewAttrs)
+	if errors.Is(err, docexport.ErrorUnsupportedFormat) {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpInfo.Name, err), http.StatusBadRequest)
+		return
+
+	} else if err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpInfo.Name, err), http.StatusInternalServerError)
+		return
+	}
+	ctx.Header("Content-Type", docexport.ContentType(format))
+	ctx.Header(
+		"Content-Disposition",
+		fmt.Sprintf("attachment; filename=\"%s-documents.%s\"", corpInfo.Name, format),
+	)
+	var numRows int
+	err = db.IterDocuments(
+		a.laDB.DB(),
+		corpInfo,
+		viewAttrs,
+		qry.Aligned,
+		qry.Attrs,
+		attrTypes,
+		qry.Filter,
+		func(row *db.DocumentRow) error {
+			numRows++
+			return writer.WriteRow(row)
+		},
+	)
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil && !ctx.Writer.Written() {
+		ctx.Writer.Header().Del("Content-Disposition")
+		ctx.Writer.Header().Set("Content-Type", "application/json")
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpInfo.Name, err), http.StatusInternalServerError)
+		return
+
+	} else if err != nil {
+		// the response is already being sent so we cannot
+		// change its status
+		log.Error().
+			Err(err).
+			Str("corpusId", corpInfo.Name).
+			Str("format", format).
+			Int("writtenRows", numRows).
+			Msg("failed to export document list")
+	}
+}
+
 // NumMatchingDocuments godoc
 // @Summary      Count number of matching documents for specified corpus
 // @Accept       json
@@ -264,12 +349,24 @@ func (a *Actions) NumMatchingDocuments(ctx *gin.Context) {
 		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusBadRequest)
 		return
 	}
+	if err := qry.Filter.Validate(); err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusBadRequest)
+		return
+	}
+
+	attrTypes, err := a.laConfCache.GetAttrTypes(corpusID)
+	if err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
+		return
+	}
 
 	ans, err := db.GetNumOfDocuments(
 		a.laDB.DB(),
 		corpInfo,
 		qry.Aligned,
 		qry.Attrs,
+		attrTypes,
+		qry.Filter,
 	)
 	if err != nil {
 		uniresp.WriteJSONErrorResponse(
@@ -0,0 +1,183 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"frodo/jobs"
+	"frodo/liveattrs/db"
+	"net/http"
+	"strconv"
+
+	"github.com/czcorpus/cnc-gokit/uniresp"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// indexArgs reads optional index advisor arguments from URL,
+// using the configured values as defaults
+func (a *Actions) indexArgs(ctx *gin.Context) (db.IndexJobInfoArgs, error) {
+	ans := db.IndexJobInfoArgs{
+		MaxColumns: a.conf.LA.AutoIndexes.GetMaxColumns(),
+		MinUsage:   a.conf.LA.AutoIndexes.GetMinUsage(),
+	}
+	if v := ctx.Query("maxColumns"); v != "" {
+		maxColumns, err := strconv.Atoi(v)
+		if err != nil || maxColumns < 0 {
+			return ans, fmt.Errorf("invalid maxColumns value: %s", v)
+		}
+		ans.MaxColumns = maxColumns
+	}
+	if v := ctx.Query("minUsage"); v != "" {
+		minUsage, err := strconv.Atoi(v)
+		if err != nil || minUsage < 1 {
+			return ans, fmt.Errorf("invalid minUsage value: %s", v)
+		}
+		ans.MinUsage = minUsage
+	}
+	return ans, nil
+}
+
+// enqueueIndexUpdate enqueues a job updating indexes of the liveattrs
+// table of a corpus once all the parent jobs (if any) finish
+func (a *Actions) enqueueIndexUpdate(
+	corpusID string,
+	args db.IndexJobInfoArgs,
+	parentJobIDs []string,
+) *db.IndexJobInfo {
+	status := &db.IndexJobInfo{
+		ID:       uuid.New().String(),
+		Type:     db.IndexJobType,
+		CorpusID: corpusID,
+		Start:    jobs.CurrentDatetime(),
+		Update:   jobs.CurrentDatetime(),
+		Args:     args,
+	}
+	a.jobActions.EnqueueJobAfterAll(a.indexJobFunc(*status), status, parentJobIDs)
+	return status
+}
+
+// restartIndexJobFunc is a jobs.JobFuncFactory for detached
+// index update jobs
+func (a *Actions) restartIndexJobFunc(job jobs.GeneralJobInfo) (*jobs.JobFunc, error) {
+	switch tJob := job.(type) {
+	case *db.IndexJobInfo:
+		return a.indexJobFunc(*tJob), nil
+	case db.IndexJobInfo:
+		return a.indexJobFunc(tJob), nil
+	default:
+		return nil, fmt.Errorf("invalid index update job type %T", job)
+	}
+}
+
+// indexJobFunc creates a job function updating indexes of a liveattrs table
+func (a *Actions) indexJobFunc(initialStatus db.IndexJobInfo) *jobs.JobFunc {
+	fn := func(jctx context.Context, updateJobChan chan<- jobs.GeneralJobInfo) {
+		defer close(updateJobChan)
+		corpusInfo, err := a.corpusMeta.LoadInfo(initialStatus.CorpusID)
+		if err != nil {
+			updateJobChan <- initialStatus.WithError(err)
+			return
+		}
+		result, err := db.UpdateIndexes(
+			jctx,
+			a.laDB.DB(),
+			corpusInfo,
+			initialStatus.Args.MaxColumns,
+			initialStatus.Args.MinUsage,
+		)
+		status := initialStatus
+		status.Result = &result
+		if jctx.Err() != nil {
+			log.Info().
+				Str("corpusId", status.CorpusID).
+				Strs("created", result.CreatedIndexes).
+				Strs("removed", result.RemovedIndexes).
+				Msg("liveattrs index update cancelled")
+			return
+		}
+		if err != nil {
+			updateJobChan <- status.WithError(err)
+			return
+		}
+		log.Info().
+			Str("corpusId", status.CorpusID).
+			Strs("created", result.CreatedIndexes).
+			Strs("removed", result.RemovedIndexes).
+			Msg("updated liveattrs indexes")
+		updateJobChan <- status.AsFinished()
+	}
+	return &fn
+}
+
+// IndexAdvice godoc
+// @Summary      Show indexes of liveattrs data along with recommended changes
+// @Description  Show current indexes of a corpus liveattrs table, usage ranks of its columns and changes recommended based on the usage.
+// @Produce      json
+// @Param        corpusId path string true "An ID of a corpus"
+// @Param        maxColumns query int false "Max. number of automatically indexed columns (configured value by default)"
+// @Param        minUsage query int false "Min. number of queries for a column to be indexed (configured value by default)"
+// @Success      200 {object} db.IndexAdvice
+// @Router       /liveAttributes/{corpusId}/indexes [get]
+func (a *Actions) IndexAdvice(ctx *gin.Context) {
+	corpusID := ctx.Param("corpusId")
+	baseErrTpl := "failed to get index advice for %s: %w"
+	args, err := a.indexArgs(ctx)
+	if err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusBadRequest)
+		return
+	}
+	corpusInfo, err := a.corpusMeta.LoadInfo(corpusID)
+	if err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
+		return
+	}
+	ans, err := db.AdviseIndexes(ctx.Request.Context(), a.laDB.DB(), corpusInfo, args.MaxColumns, args.MinUsage)
+	if err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
+		return
+	}
+	uniresp.WriteJSONResponse(ctx.Writer, ans)
+}
+
+// UpdateIndexes godoc
+// @Summary      Apply recommended index changes to liveattrs data
+// @Description  Start a background job creating indexes of the most used columns of a corpus liveattrs table and dropping unused automatic indexes.
+// @Produce      json
+// @Param        corpusId path string true "An ID of a corpus"
+// @Param        maxColumns query int false "Max. number of automatically indexed columns (configured value by default)"
+// @Param        minUsage query int false "Min. number of queries for a column to be indexed (configured value by default)"
+// @Param        parentJobId query []string false "Run the job once the specified jobs finish" collectionFormat(multi)
+// @Success      201 {object} any
+// @Router       /liveAttributes/{corpusId}/indexes [post]
+func (a *Actions) UpdateIndexes(ctx *gin.Context) {
+	corpusID := ctx.Param("corpusId")
+	baseErrTpl := "failed to update indexes for %s: %w"
+	args, err := a.indexArgs(ctx)
+	if err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusBadRequest)
+		return
+	}
+	if prev, ok := a.jobActions.LastUnfinishedJobOfType(corpusID, db.IndexJobType); ok {
+		err := fmt.Errorf("the previous job %s not finished yet", prev.GetID())
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusConflict)
+		return
+	}
+	status := a.enqueueIndexUpdate(corpusID, args, ctx.QueryArray("parentJobId"))
+	uniresp.WriteJSONResponseWithStatus(ctx.Writer, http.StatusCreated, status.FullInfo())
+}
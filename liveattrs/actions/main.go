@@ -33,8 +33,11 @@ import (
 	"frodo/liveattrs/request/fillattrs"
 	"frodo/liveattrs/request/query"
 	"frodo/liveattrs/request/response"
+	"frodo/liveattrs/subcorpus"
 	"frodo/metadb"
+	"frodo/sharedcache"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -53,6 +56,9 @@ const (
 	emptyValuePlaceholder = "?"
 	dfltMaxAttrListSize   = 30
 	shortLabelMaxLength   = 30
+
+	// procTimeType identifies liveattrs runs in the proc_times table
+	procTimeType = "liveattrs"
 )
 
 var (
@@ -79,10 +85,6 @@ type Actions struct {
 	// ctx controls cancellation
 	ctx context.Context
 
-	// jobStopChannel receives job ID based on user interaction with job HTTP API in
-	// case users asks for stopping the vte process
-	jobStopChannel <-chan string
-
 	jobActions *jobs.Actions
 
 	laConfCache *laconf.LiveAttrsBuildConfProvider
@@ -97,11 +99,39 @@ type Actions struct {
 	// eqCache stores results for live-attributes empty queries (= initial text types data)
 	eqCache *cache.EmptyQueryCache
 
+	// qCache stores results for live-attributes queries with selected attributes
+	qCache *cache.QueryCache
+
+	// sharedCache distributes cache invalidation messages
+	// among instances of the service
+	sharedCache sharedcache.Cache
+
 	structAttrStats *db.StructAttrUsage
 
 	usageData chan<- db.RequestData
 
-	vteJobCancel map[string]context.CancelFunc
+	// subcRegistry stores saved subcorpora
+	subcRegistry *subcorpus.Registry
+
+	// corpusLocks serialize starting of liveattrs jobs and data
+	// rollbacks of individual corpora
+	corpusLocks     map[string]*sync.Mutex
+	corpusLocksLock sync.Mutex
+}
+
+// lockCorpus acquires a lock protecting liveattrs data of a corpus
+// from concurrent job starts and rollbacks. The returned function
+// releases the lock.
+func (a *Actions) lockCorpus(corpusID string) func() {
+	a.corpusLocksLock.Lock()
+	lock, ok := a.corpusLocks[corpusID]
+	if !ok {
+		lock = &sync.Mutex{}
+		a.corpusLocks[corpusID] = lock
+	}
+	a.corpusLocksLock.Unlock()
+	lock.Lock()
+	return lock.Unlock
 }
 
 // applyPatchArgs based on configuration stored in `jsonArgs`
@@ -164,6 +194,12 @@ func (a *Actions) applyPatchArgs(
 		targetConf.DateAttr = &tmp
 	}
 
+	if jsonArgs.AttrTypes != nil {
+		if err := jsonArgs.AttrTypes.Validate(targetConf); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -206,27 +242,100 @@ func (a *Actions) ensureVerticalFile(vconf *vteCnf.VTEConf, corpusInfo *corpus.I
 	return nil
 }
 
+// vteGroupedName returns a name vert-tagextract uses as a prefix
+// for its tables (see corpus.DBInfo.GroupedName)
+func vteGroupedName(conf *vteCnf.VTEConf) string {
+	if conf.ParallelCorpus != "" {
+		return conf.ParallelCorpus
+	}
+	return conf.Corpus
+}
+
+// shadowVteConf derives an extraction configuration writing into
+// shadow tables (see db.ShadowName). The bib. view is not created as
+// it would refer to the shadow tables even after they are swapped in.
+func shadowVteConf(conf vteCnf.VTEConf) *vteCnf.VTEConf {
+	conf.ParallelCorpus = db.ShadowName(vteGroupedName(&conf))
+	conf.BibView = vteDB.BibViewConf{}
+	return &conf
+}
+
 // generateData starts data extraction and generation
-// based on (initial) job status
-func (a *Actions) generateData(initialStatus *liveattrs.LiveAttrsJobInfo) {
-	jctx, cancel := context.WithCancel(a.ctx)
-	a.vteJobCancel[initialStatus.ID] = cancel
-	fn := func(updateJobChan chan<- jobs.GeneralJobInfo) {
+// based on (initial) job status once all the parent jobs (if any)
+// finish. Unless disabled, indexes of the liveattrs table are updated
+// once the data are successfully generated.
+func (a *Actions) generateData(initialStatus *liveattrs.LiveAttrsJobInfo, parentJobIDs []string) {
+	a.jobActions.EnqueueJobAfterAll(a.jobFunc(initialStatus), initialStatus, parentJobIDs)
+	if !a.conf.LA.AutoIndexes.Disabled {
+		a.enqueueIndexUpdate(
+			initialStatus.CorpusID,
+			db.IndexJobInfoArgs{
+				MaxColumns: a.conf.LA.AutoIndexes.GetMaxColumns(),
+				MinUsage:   a.conf.LA.AutoIndexes.GetMinUsage(),
+			},
+			[]string{initialStatus.ID},
+		)
+	}
+}
+
+// invalidateDataCaches removes cached results based on liveattrs
+// data of a corpus and tells other instances to do the same
+func (a *Actions) invalidateDataCaches(corpusID string) {
+	a.eqCache.Del(corpusID)
+	a.qCache.Del(corpusID)
+	if err := a.sharedCache.Del(a.ctx, sharedcache.DatasetSizeKey(corpusID)); err != nil {
+		log.Error().Err(err).Str("corpusId", corpusID).Msg("failed to remove cached dataset size")
+	}
+	a.publishInvalidation(sharedcache.InvalidateLiveAttrsData, corpusID)
+}
+
+// restartJobFunc is a jobs.JobFuncFactory for detached
+// live attributes jobs
+func (a *Actions) restartJobFunc(job jobs.GeneralJobInfo) (*jobs.JobFunc, error) {
+	switch tJob := job.(type) {
+	case *liveattrs.LiveAttrsJobInfo:
+		if tJob.Args.Update != nil {
+			return a.updateJobFunc(tJob), nil
+		}
+		return a.jobFunc(tJob), nil
+	case liveattrs.LiveAttrsJobInfo:
+		if tJob.Args.Update != nil {
+			return a.updateJobFunc(&tJob), nil
+		}
+		return a.jobFunc(&tJob), nil
+	default:
+		return nil, fmt.Errorf("invalid live attributes job type %T", job)
+	}
+}
+
+// jobFunc creates a job function for data extraction and generation.
+// The data are extracted into shadow tables which, once validated,
+// atomically replace the current tables (kept as a previous version
+// for a possible rollback).
+func (a *Actions) jobFunc(initialStatus *liveattrs.LiveAttrsJobInfo) *jobs.JobFunc {
+	fn := func(jctx context.Context, updateJobChan chan<- jobs.GeneralJobInfo) {
+		t0 := time.Now()
+		groupedName := vteGroupedName(&initialStatus.Args.VteConf)
+		if initialStatus.Args.Append || initialStatus.Args.VteConf.DefinesMovingDataWindow() {
+			if err := db.PrepareShadow(a.laDB.DB(), groupedName, initialStatus.Args.Append); err != nil {
+				updateJobChan <- initialStatus.WithError(err).AsFinished()
+				close(updateJobChan)
+				return
+			}
+		}
 		procStatus, err := vteLib.ExtractData(
 			jctx,
-			&initialStatus.Args.VteConf,
+			shadowVteConf(initialStatus.Args.VteConf),
 			initialStatus.Args.Append,
 		)
 		if err != nil {
 			updateJobChan <- initialStatus.WithError(
 				fmt.Errorf("failed to start vert-tagextract: %s", err)).AsFinished()
 			close(updateJobChan)
+			return
 		}
 		go func() {
-			defer func() {
-				close(updateJobChan)
-				delete(a.vteJobCancel, initialStatus.ID)
-			}()
+			defer close(updateJobChan)
 			jobStatus := liveattrs.LiveAttrsJobInfo{
 				ID:              initialStatus.ID,
 				Type:            liveattrs.JobType,
@@ -235,10 +344,15 @@ func (a *Actions) generateData(initialStatus *liveattrs.LiveAttrsJobInfo) {
 				Start:           initialStatus.Start,
 				Update:          jobs.CurrentDatetime(),
 				NumRestarts:     initialStatus.NumRestarts,
+				Attempts:        initialStatus.Attempts,
 				Args:            initialStatus.Args,
 			}
 
+			// vert-tagextract reports a failure of the whole process
+			// as the last status
+			var lastErr error
 			for upd := range procStatus {
+				lastErr = upd.Error
 				if upd.Error == vteProc.ErrorTooManyParsingErrors {
 					jobStatus.Error = upd.Error
 				}
@@ -249,14 +363,87 @@ func (a *Actions) generateData(initialStatus *liveattrs.LiveAttrsJobInfo) {
 
 				if upd.Error == vteProc.ErrorTooManyParsingErrors {
 					log.Error().Str("corpusId", jobStatus.CorpusID).Err(upd.Error).Msg("live attributes extraction failed")
-					return
 
 				} else if upd.Error != nil {
 					log.Error().Str("corpusId", jobStatus.CorpusID).Err(upd.Error).Msg("(just registered)")
 				}
 			}
 
-			a.eqCache.Del(jobStatus.CorpusID)
+			if jctx.Err() != nil || lastErr != nil {
+				// vert-tagextract leaves its temporary tables in place
+				// which would block any further import (including a retry)
+				err := db.DropStagingTables(a.laDB.DB(), db.ShadowName(groupedName))
+				if err != nil {
+					log.Error().Err(err).Str("corpusId", jobStatus.CorpusID).Msg("failed to clean up unfinished job")
+				}
+				if jctx.Err() != nil {
+					log.Info().Str("corpusId", jobStatus.CorpusID).Msg("live attributes extraction cancelled")
+
+				} else if jobStatus.Error == nil {
+					updateJobChan <- jobStatus.WithError(
+						fmt.Errorf("live attributes extraction failed: %w", lastErr))
+				}
+				return
+			}
+
+			// a rollback must not interleave with validating and swapping tables
+			unlock := a.lockCorpus(jobStatus.CorpusID)
+			swapResult, err := db.ValidateShadow(
+				a.laDB.DB(),
+				groupedName,
+				jobStatus.Args.VteConf.Corpus,
+				a.conf.LA.Swap.GetMaxShrinkRatio(),
+			)
+			jobStatus.SwapResult = &swapResult
+			if err == nil {
+				err = db.SetDataVersion(
+					a.laDB.DB(),
+					db.ShadowName(groupedName),
+					db.DataVersion{
+						JobID:       jobStatus.ID,
+						ConfVersion: jobStatus.Args.ConfVersion,
+						Created:     time.Now(),
+					},
+				)
+			}
+			if err == nil {
+				err = db.SwapShadowTables(a.laDB.DB(), groupedName)
+			}
+			unlock()
+			if err != nil {
+				if err := db.DropStagingTables(a.laDB.DB(), db.ShadowName(groupedName)); err != nil {
+					log.Error().Err(err).Str("corpusId", jobStatus.CorpusID).Msg("failed to remove shadow tables")
+				}
+				updateJobChan <- jobStatus.WithError(err)
+				return
+			}
+			jobStatus.DataSwapped = true
+			updateJobChan <- jobStatus
+			log.Info().
+				Str("corpusId", jobStatus.CorpusID).
+				Int("prevNumRows", swapResult.PrevNumRows).
+				Int("numRows", swapResult.NumRows).
+				Msg("swapped rebuilt liveattrs tables")
+			if err := db.EnsureBibView(a.laDB.DB(), groupedName, jobStatus.Args.VteConf.BibView); err != nil {
+				updateJobChan <- jobStatus.WithError(err)
+				return
+			}
+
+			// the statistics are used to estimate run time of future jobs
+			// (see Validate); appended data would distort them
+			if !jobStatus.Args.Append {
+				if err := db.AddProcTimeEntry(
+					a.laDB.DB(),
+					procTimeType,
+					jobStatus.ProcessedLines,
+					jobStatus.ProcessedLines,
+					time.Since(t0).Seconds(),
+				); err != nil {
+					log.Err(err).Msg("failed to write proc_time statistics (ignoring the error)")
+				}
+			}
+
+			a.invalidateDataCaches(jobStatus.CorpusID)
 			if jobStatus.Args.VteConf.DB.Type != "mysql" {
 				updateJobChan <- jobStatus.WithError(fmt.Errorf("only mysql liveattrs backend is supported in Frodo"))
 				return
@@ -288,22 +475,12 @@ func (a *Actions) generateData(initialStatus *liveattrs.LiveAttrsJobInfo) {
 				updateJobChan <- jobStatus.WithError(err)
 			}
 			updateJobChan <- jobStatus.AsFinished()
-		}()
-	}
-	a.jobActions.EnqueueJob(&fn, initialStatus)
-}
-
-func (a *Actions) runStopJobListener() {
-	for id := range a.jobStopChannel {
-		if job, ok := a.jobActions.GetJob(id); ok {
-			if tJob, ok2 := job.(liveattrs.LiveAttrsJobInfo); ok2 {
-				if cancel, ok3 := a.vteJobCancel[tJob.ID]; ok3 {
-					cancel()
-					log.Debug().Msg("cancelled job on user request")
-				}
+			if err == nil {
+				a.checkSubcorpora(jobStatus.CorpusID)
 			}
-		}
+		}()
 	}
+	return &fn
 }
 
 // Query godoc
@@ -324,6 +501,10 @@ func (a *Actions) Query(ctx *gin.Context) {
 		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusBadRequest)
 		return
 	}
+	if err := qry.Filter.Validate(); err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusBadRequest)
+		return
+	}
 	corpInfo, err := a.corpusMeta.LoadInfo(corpusID)
 	if err != nil {
 		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
@@ -336,6 +517,9 @@ func (a *Actions) Query(ctx *gin.Context) {
 	}
 
 	ans := a.eqCache.Get(corpusID, qry)
+	if ans == nil {
+		ans = a.qCache.Get(corpusID, qry)
+	}
 	if ans != nil {
 		uniresp.WriteJSONResponse(ctx.Writer, &ans)
 		usageEntry.IsCached = true
@@ -357,6 +541,7 @@ func (a *Actions) Query(ctx *gin.Context) {
 	usageEntry.ProcTime = time.Since(t0)
 	a.usageData <- usageEntry
 	a.eqCache.Set(corpusID, qry, ans)
+	a.qCache.Set(corpusID, qry, ans)
 	uniresp.WriteJSONResponse(ctx.Writer, &ans)
 }
 
@@ -413,13 +598,22 @@ func (a *Actions) GetAdhocSubcSize(ctx *gin.Context) {
 		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
 		return
 	}
+	if err := qry.Filter.Validate(); err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusBadRequest)
+		return
+	}
 	corpora := append([]string{corpusID}, qry.Aligned...)
 	corpusDBInfo, err := a.corpusMeta.LoadInfo(corpusID)
 	if err != nil {
 		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
 		return
 	}
-	size, err := db.GetSubcSize(a.laDB.DB(), corpusDBInfo, corpora, qry.Attrs)
+	attrTypes, err := a.laConfCache.GetAttrTypes(corpusID)
+	if err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
+		return
+	}
+	size, err := db.GetSubcSize(a.laDB.DB(), corpusDBInfo, corpora, qry.Attrs, attrTypes, qry.Filter)
 	if err != nil {
 		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
 		return
@@ -475,20 +669,6 @@ func (a *Actions) Stats(ctx *gin.Context) {
 	uniresp.WriteJSONResponse(ctx.Writer, &ans)
 }
 
-func (a *Actions) RestartLiveAttrsJob(ctx context.Context, jinfo *liveattrs.LiveAttrsJobInfo) error {
-	err := a.jobActions.TestAllowsJobRestart(jinfo)
-	if err != nil {
-		return err
-	}
-	jinfo.Start = jobs.CurrentDatetime()
-	jinfo.NumRestarts++
-	jinfo.Update = jobs.CurrentDatetime()
-
-	a.generateData(jinfo)
-	log.Info().Msgf("Restarted liveAttributes job %s", jinfo.ID)
-	return nil
-}
-
 // InferredAtomStructure godoc
 // @Summary      Get inferred atom structure for specified corpus
 // @Produce      json
@@ -517,16 +697,37 @@ func (a *Actions) InferredAtomStructure(ctx *gin.Context) {
 	uniresp.WriteJSONResponse(ctx.Writer, &ans)
 }
 
+// purgeLocalCaches removes cached data of a corpus
+// affected by the invalidation message
+func (a *Actions) purgeLocalCaches(msg sharedcache.Invalidation) {
+	switch msg.Kind {
+	case sharedcache.InvalidateLiveAttrsData:
+		a.eqCache.Del(msg.CorpusID)
+		a.qCache.Del(msg.CorpusID)
+	case sharedcache.InvalidateLiveAttrsConf:
+		a.laConfCache.Uncache(msg.CorpusID)
+	}
+}
+
+// publishInvalidation tells other instances of the service
+// to purge their local caches of a corpus
+func (a *Actions) publishInvalidation(kind, corpusID string) {
+	msg := sharedcache.Invalidation{Kind: kind, CorpusID: corpusID}
+	if err := a.sharedCache.Publish(a.ctx, msg); err != nil {
+		log.Error().Err(err).Str("corpusId", corpusID).Msg("failed to publish cache invalidation")
+	}
+}
+
 // NewActions is the recommended factory for Actions
 func NewActions(
 	conf LAConf,
 	ctx context.Context,
-	jobStopChannel <-chan string,
 	jobActions *jobs.Actions,
 	corpusMeta metadb.Provider,
 	corpusMetaW metadb.SQLUpdater,
 	laDB *mysql.Adapter,
 	laConfRegistry *laconf.LiveAttrsBuildConfProvider,
+	sharedCache sharedcache.Cache,
 	version general.VersionInfo,
 ) *Actions {
 	usageChan := make(chan db.RequestData)
@@ -534,17 +735,21 @@ func NewActions(
 		conf:            conf,
 		ctx:             ctx,
 		jobActions:      jobActions,
-		jobStopChannel:  jobStopChannel,
 		laConfCache:     laConfRegistry,
 		corpusMeta:      corpusMeta,
 		corpusMetaW:     corpusMetaW,
 		laDB:            laDB,
 		eqCache:         cache.NewEmptyQueryCache(),
+		qCache:          cache.NewQueryCache(conf.LA.QueryCache),
+		sharedCache:     sharedCache,
 		structAttrStats: db.NewStructAttrUsage(laDB.DB(), usageChan),
 		usageData:       usageChan,
-		vteJobCancel:    make(map[string]context.CancelFunc),
+		subcRegistry:    subcorpus.NewRegistry(laDB.DB()),
+		corpusLocks:     make(map[string]*sync.Mutex),
 	}
 	go actions.structAttrStats.RunHandler()
-	go actions.runStopJobListener()
+	sharedCache.Subscribe(actions.purgeLocalCaches)
+	jobActions.RegisterJobFuncFactory(&liveattrs.LiveAttrsJobInfo{}, actions.restartJobFunc)
+	jobActions.RegisterJobFuncFactory(&db.IndexJobInfo{}, actions.restartIndexJobFunc)
 	return actions
 }
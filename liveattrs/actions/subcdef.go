@@ -0,0 +1,181 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package actions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"frodo/liveattrs/subcdef"
+	"frodo/liveattrs/subcmixer"
+	"frodo/liveattrs/subcorpus"
+	"net/http"
+
+	"github.com/czcorpus/cnc-gokit/uniresp"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	subcdefFormatAuto   = "auto"
+	subcdefFormatWithin = "within"
+	subcdefFormatIDList = "idlist"
+
+	// subcdefAutoCompactMinDocs is a number of documents starting
+	// from which a document list is always compacted
+	subcdefAutoCompactMinDocs = 1000
+)
+
+type subcdefArgs struct {
+	Name string `json:"name"`
+
+	// Format is one of:
+	//  * "within" - a condition based on the selected attributes
+	//  * "idlist" - a condition based on bib. IDs of matching documents
+	//  * "auto" (default) - "within" if possible, "idlist" otherwise
+	Format string `json:"format"`
+
+	// Compact merges document IDs into value lists (idlist only).
+	// Large document lists are compacted regardless of the value.
+	Compact bool `json:"compact"`
+
+	// MaxListSize limits a size of a single value list
+	// of a compacted condition
+	MaxListSize int `json:"maxListSize"`
+
+	// Selection and MixerResult are mutually exclusive
+	Selection   *subcorpusSelection          `json:"selection,omitempty"`
+	MixerResult *subcmixer.CorpusComposition `json:"mixerResult,omitempty"`
+}
+
+func (args *subcdefArgs) validate() error {
+	if err := subcdef.ValidateName(args.Name); err != nil {
+		return err
+	}
+	switch args.Format {
+	case "":
+		args.Format = subcdefFormatAuto
+	case subcdefFormatAuto, subcdefFormatWithin, subcdefFormatIDList:
+	default:
+		return fmt.Errorf("unknown subcorpus definition format %s", args.Format)
+	}
+	if (args.Selection == nil) == (args.MixerResult == nil) {
+		return fmt.Errorf("exactly one of selection and mixerResult must be provided")
+	}
+	if args.MixerResult != nil && args.Format == subcdefFormatWithin {
+		return fmt.Errorf("%w: mixer result", subcdef.ErrorNotExpressible)
+	}
+	if args.Selection != nil {
+		return args.Selection.Filter.Validate()
+	}
+	return nil
+}
+
+// withinDefinition creates a definition from attributes of the selection.
+// Aligned corpora and filter expressions cannot be expressed this way.
+func (args *subcdefArgs) withinDefinition() (subcdef.Definition, error) {
+	if len(args.Selection.Aligned) > 0 {
+		return subcdef.Definition{}, fmt.Errorf("%w: aligned corpora", subcdef.ErrorNotExpressible)
+	}
+	if args.Selection.Filter != nil {
+		return subcdef.Definition{}, fmt.Errorf("%w: filter expression", subcdef.ErrorNotExpressible)
+	}
+	return subcdef.FromSelection(args.Name, args.Selection.Attrs)
+}
+
+// SubcorpusDefinition godoc
+// @Summary      Export a text type selection as a Manatee subcorpus definition
+// @Description  Render a text type selection or a subcmixer result as a subcorpus definition accepted by mksubc. The definition is either based on a structure condition or on a list of bib. IDs of matching documents.
+// @Accept  	 json
+// @Produce      plain
+// @Param        corpusId path string true "An ID of a corpus"
+// @Param 		 args body subcdefArgs true "Export arguments"
+// @Success      200 {string} string
+// @Router       /liveAttributes/{corpusId}/subcdef [post]
+func (a *Actions) SubcorpusDefinition(ctx *gin.Context) {
+	corpusID := ctx.Param("corpusId")
+	baseErrTpl := "failed to export subcorpus definition of %s: %w"
+	var args subcdefArgs
+	if err := json.NewDecoder(ctx.Request.Body).Decode(&args); err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusBadRequest)
+		return
+	}
+	if err := args.validate(); err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusBadRequest)
+		return
+	}
+	var def subcdef.Definition
+	var err error
+	if args.Format != subcdefFormatIDList && args.Selection != nil {
+		def, err = args.withinDefinition()
+		if errors.Is(err, subcdef.ErrorNotExpressible) && args.Format == subcdefFormatAuto {
+			args.Format = subcdefFormatIDList
+
+		} else if errors.Is(err, subcdef.ErrorNotExpressible) {
+			uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusUnprocessableEntity)
+			return
+
+		} else if err != nil {
+			uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusBadRequest)
+			return
+		}
+
+	} else {
+		args.Format = subcdefFormatIDList
+	}
+	if args.Format == subcdefFormatIDList {
+		corpInfo := a.loadSubcCorpusInfo(ctx, baseErrTpl)
+		if corpInfo == nil {
+			return
+		}
+		var docIDs []string
+		if args.Selection != nil {
+			docIDs, err = a.selectionDocIDs(
+				corpInfo,
+				subcorpus.Definition{
+					Aligned: args.Selection.Aligned,
+					Attrs:   args.Selection.Attrs,
+					Filter:  args.Selection.Filter,
+				},
+			)
+			if err != nil {
+				log.Error().Str("corpusId", corpusID).Err(err).Msg("failed to export subcorpus definition")
+				uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
+				return
+			}
+
+		} else {
+			docIDs = args.MixerResult.DocIDs
+		}
+		def, err = subcdef.FromDocIDs(
+			args.Name,
+			corpInfo.BibIDAttr,
+			docIDs,
+			args.Compact || len(docIDs) >= subcdefAutoCompactMinDocs,
+			args.MaxListSize,
+		)
+		if err != nil {
+			uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusUnprocessableEntity)
+			return
+		}
+	}
+	ctx.Header("Content-Type", "text/plain; charset=utf-8")
+	ctx.Header(
+		"Content-Disposition",
+		fmt.Sprintf("attachment; filename=\"%s-%s.subcdef\"", corpusID, args.Format),
+	)
+	ctx.String(http.StatusOK, def.String())
+}
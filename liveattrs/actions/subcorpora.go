@@ -0,0 +1,411 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package actions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"frodo/corpus"
+	"frodo/liveattrs/db"
+	"frodo/liveattrs/request/query"
+	"frodo/liveattrs/subcmixer"
+	"frodo/liveattrs/subcorpus"
+	"net/http"
+	"strings"
+
+	"github.com/czcorpus/cnc-gokit/uniresp"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	ErrorInvalidSubcorpus = errors.New("invalid subcorpus definition")
+)
+
+// subcorpusSelection is a text type selection a subcorpus is created from
+type subcorpusSelection struct {
+	Aligned []string      `json:"aligned"`
+	Attrs   query.Attrs   `json:"attrs"`
+	Filter  *query.Filter `json:"filter,omitempty"`
+}
+
+type createSubcorpusArgs struct {
+	Name  string `json:"name"`
+	Owner string `json:"owner"`
+
+	// Selection and MixerResult are mutually exclusive
+	Selection   *subcorpusSelection          `json:"selection,omitempty"`
+	MixerResult *subcmixer.CorpusComposition `json:"mixerResult,omitempty"`
+}
+
+func (args *createSubcorpusArgs) validate() error {
+	if strings.TrimSpace(args.Name) == "" {
+		return fmt.Errorf("%w: missing name", ErrorInvalidSubcorpus)
+	}
+	if strings.TrimSpace(args.Owner) == "" {
+		return fmt.Errorf("%w: missing owner", ErrorInvalidSubcorpus)
+	}
+	if (args.Selection == nil) == (args.MixerResult == nil) {
+		return fmt.Errorf(
+			"%w: exactly one of selection and mixerResult must be provided", ErrorInvalidSubcorpus)
+	}
+	if args.Selection != nil {
+		if err := args.Selection.Filter.Validate(); err != nil {
+			return err
+		}
+	}
+	if args.MixerResult != nil {
+		if args.MixerResult.Error != "" {
+			return fmt.Errorf(
+				"%w: cannot save failed mixer result: %s", ErrorInvalidSubcorpus, args.MixerResult.Error)
+		}
+		if len(args.MixerResult.DocIDs) == 0 {
+			return fmt.Errorf("%w: empty mixer result", ErrorInvalidSubcorpus)
+		}
+	}
+	return nil
+}
+
+type patchSubcorpusArgs struct {
+	Name *string `json:"name,omitempty"`
+
+	// Refreeze replaces the frozen document list by documents
+	// currently matching the subcorpus definition. This is supported
+	// only for subcorpora created from a text type selection.
+	Refreeze bool `json:"refreeze"`
+}
+
+// selectionDocIDs returns IDs of documents currently matching
+// the subcorpus definition
+func (a *Actions) selectionDocIDs(corpusInfo *corpus.DBInfo, def subcorpus.Definition) ([]string, error) {
+	attrTypes, err := a.laConfCache.GetAttrTypes(corpusInfo.Name)
+	if err != nil {
+		return nil, err
+	}
+	ans := make([]string, 0, 1000)
+	err = db.IterDocuments(
+		a.laDB.DB(),
+		corpusInfo,
+		[]string{},
+		def.Aligned,
+		def.Attrs,
+		attrTypes,
+		def.Filter,
+		func(row *db.DocumentRow) error {
+			ans = append(ans, row.ID)
+			return nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return ans, nil
+}
+
+// loadSubcorpus loads a subcorpus and makes sure it belongs to the corpus
+// specified in the URL. In case of an error, a response is written and
+// nil is returned.
+func (a *Actions) loadSubcorpus(ctx *gin.Context, baseErrTpl string) *subcorpus.Subcorpus {
+	corpusID := ctx.Param("corpusId")
+	subc, err := a.subcRegistry.Get(ctx.Param("subcId"))
+	if err == nil && subc.CorpusID != corpusID {
+		err = subcorpus.ErrorNotFound
+	}
+	if err == subcorpus.ErrorNotFound {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusNotFound)
+		return nil
+
+	} else if err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
+		return nil
+	}
+	return subc
+}
+
+// loadSubcCorpusInfo loads corpus information and makes sure the corpus
+// has a bib. ID attribute (which is what the frozen document lists consist of).
+// In case of an error, a response is written and nil is returned.
+func (a *Actions) loadSubcCorpusInfo(ctx *gin.Context, baseErrTpl string) *corpus.DBInfo {
+	corpusID := ctx.Param("corpusId")
+	corpInfo, err := a.corpusMeta.LoadInfo(corpusID)
+	if err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
+		return nil
+	}
+	if corpInfo.BibIDAttr == "" {
+		uniresp.WriteJSONErrorResponse(
+			ctx.Writer,
+			uniresp.NewActionError(baseErrTpl, corpusID, fmt.Errorf("bib. ID not defined for %s", corpusID)),
+			http.StatusNotFound,
+		)
+		return nil
+	}
+	return corpInfo
+}
+
+// CreateSubcorpus godoc
+// @Summary      Save a named subcorpus
+// @Description  Save a subcorpus defined either by a text type selection or by a subcmixer result. The current list of matching documents is frozen along with the definition.
+// @Accept  	 json
+// @Produce      json
+// @Param        corpusId path string true "An ID of a corpus"
+// @Param 		 args body createSubcorpusArgs true "Subcorpus definition"
+// @Success      201 {object} subcorpus.Subcorpus
+// @Router       /liveAttributes/{corpusId}/subcorpora [post]
+func (a *Actions) CreateSubcorpus(ctx *gin.Context) {
+	corpusID := ctx.Param("corpusId")
+	baseErrTpl := "failed to create subcorpus of %s: %w"
+	var args createSubcorpusArgs
+	if err := json.NewDecoder(ctx.Request.Body).Decode(&args); err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusBadRequest)
+		return
+	}
+	if err := args.validate(); err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusBadRequest)
+		return
+	}
+	corpInfo := a.loadSubcCorpusInfo(ctx, baseErrTpl)
+	if corpInfo == nil {
+		return
+	}
+	subc := &subcorpus.Subcorpus{
+		Name:     strings.TrimSpace(args.Name),
+		Owner:    args.Owner,
+		CorpusID: corpusID,
+	}
+	var docIDs []string
+	if args.Selection != nil {
+		subc.Kind = subcorpus.KindSelection
+		subc.Definition = subcorpus.Definition{
+			Aligned: args.Selection.Aligned,
+			Attrs:   args.Selection.Attrs,
+			Filter:  args.Selection.Filter,
+		}
+		var err error
+		docIDs, err = a.selectionDocIDs(corpInfo, subc.Definition)
+		if err != nil {
+			uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
+			return
+		}
+
+	} else {
+		subc.Kind = subcorpus.KindMixer
+		subc.Definition = subcorpus.Definition{
+			CategorySizes: args.MixerResult.CategorySizes,
+		}
+		docIDs = args.MixerResult.DocIDs
+	}
+	err := a.subcRegistry.Create(corpInfo, subc, docIDs)
+	if err == subcorpus.ErrorAlreadyExists {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusConflict)
+		return
+
+	} else if err != nil {
+		log.Error().Str("corpusId", corpusID).Err(err).Msg("failed to create subcorpus")
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
+		return
+	}
+	uniresp.WriteJSONResponseWithStatus(ctx.Writer, http.StatusCreated, subc)
+}
+
+// ListSubcorpora godoc
+// @Summary      List saved subcorpora of a corpus
+// @Produce      json
+// @Param        corpusId path string true "An ID of a corpus"
+// @Param        owner query string false "Return only subcorpora of the owner"
+// @Success      200 {array} subcorpus.Subcorpus
+// @Router       /liveAttributes/{corpusId}/subcorpora [get]
+func (a *Actions) ListSubcorpora(ctx *gin.Context) {
+	corpusID := ctx.Param("corpusId")
+	baseErrTpl := "failed to list subcorpora of %s: %w"
+	ans, err := a.subcRegistry.List(corpusID, ctx.Query("owner"))
+	if err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
+		return
+	}
+	uniresp.WriteJSONResponse(ctx.Writer, ans)
+}
+
+// GetSubcorpus godoc
+// @Summary      Get a saved subcorpus
+// @Description  Get a saved subcorpus including a report on membership changes caused by the last liveattrs rebuild
+// @Produce      json
+// @Param        corpusId path string true "An ID of a corpus"
+// @Param        subcId path string true "An ID of a subcorpus"
+// @Success      200 {object} subcorpus.Subcorpus
+// @Router       /liveAttributes/{corpusId}/subcorpora/{subcId} [get]
+func (a *Actions) GetSubcorpus(ctx *gin.Context) {
+	subc := a.loadSubcorpus(ctx, "failed to get subcorpus of %s: %w")
+	if subc == nil {
+		return
+	}
+	uniresp.WriteJSONResponse(ctx.Writer, subc)
+}
+
+// PatchSubcorpus godoc
+// @Summary      Rename a saved subcorpus and/or refreeze its document list
+// @Accept  	 json
+// @Produce      json
+// @Param        corpusId path string true "An ID of a corpus"
+// @Param        subcId path string true "An ID of a subcorpus"
+// @Param 		 args body patchSubcorpusArgs true "Changes"
+// @Success      200 {object} subcorpus.Subcorpus
+// @Router       /liveAttributes/{corpusId}/subcorpora/{subcId} [patch]
+func (a *Actions) PatchSubcorpus(ctx *gin.Context) {
+	corpusID := ctx.Param("corpusId")
+	baseErrTpl := "failed to update subcorpus of %s: %w"
+	var args patchSubcorpusArgs
+	if err := json.NewDecoder(ctx.Request.Body).Decode(&args); err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusBadRequest)
+		return
+	}
+	if args.Name != nil && strings.TrimSpace(*args.Name) == "" {
+		uniresp.WriteJSONErrorResponse(
+			ctx.Writer,
+			uniresp.NewActionError(baseErrTpl, corpusID, fmt.Errorf("%w: missing name", ErrorInvalidSubcorpus)),
+			http.StatusBadRequest,
+		)
+		return
+	}
+	subc := a.loadSubcorpus(ctx, baseErrTpl)
+	if subc == nil {
+		return
+	}
+	if args.Refreeze && subc.Kind != subcorpus.KindSelection {
+		uniresp.WriteJSONErrorResponse(
+			ctx.Writer,
+			uniresp.NewActionError(
+				baseErrTpl, corpusID, fmt.Errorf("%w: only selection-based subcorpora can be refrozen", ErrorInvalidSubcorpus)),
+			http.StatusBadRequest,
+		)
+		return
+	}
+	if args.Name != nil {
+		err := a.subcRegistry.Rename(subc.ID, strings.TrimSpace(*args.Name))
+		if err == subcorpus.ErrorAlreadyExists {
+			uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusConflict)
+			return
+
+		} else if err != nil {
+			uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
+			return
+		}
+	}
+	if args.Refreeze {
+		corpInfo := a.loadSubcCorpusInfo(ctx, baseErrTpl)
+		if corpInfo == nil {
+			return
+		}
+		docIDs, err := a.selectionDocIDs(corpInfo, subc.Definition)
+		if err != nil {
+			uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
+			return
+		}
+		if err := a.subcRegistry.Refreeze(corpInfo, subc.ID, docIDs); err != nil {
+			uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
+			return
+		}
+	}
+	ans, err := a.subcRegistry.Get(subc.ID)
+	if err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
+		return
+	}
+	uniresp.WriteJSONResponse(ctx.Writer, ans)
+}
+
+// DeleteSubcorpus godoc
+// @Summary      Delete a saved subcorpus
+// @Produce      json
+// @Param        corpusId path string true "An ID of a corpus"
+// @Param        subcId path string true "An ID of a subcorpus"
+// @Success      200 {object} map[string]bool
+// @Router       /liveAttributes/{corpusId}/subcorpora/{subcId} [delete]
+func (a *Actions) DeleteSubcorpus(ctx *gin.Context) {
+	corpusID := ctx.Param("corpusId")
+	baseErrTpl := "failed to delete subcorpus of %s: %w"
+	subc := a.loadSubcorpus(ctx, baseErrTpl)
+	if subc == nil {
+		return
+	}
+	err := a.subcRegistry.Delete(subc.ID)
+	if err == subcorpus.ErrorNotFound {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusNotFound)
+		return
+
+	} else if err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
+		return
+	}
+	uniresp.WriteJSONResponse(ctx.Writer, map[string]bool{"ok": true})
+}
+
+// checkSubcorpora compares saved subcorpora of a corpus with freshly
+// rebuilt liveattrs data, recalculates their sizes and stores the
+// membership diff reports. Failures are only logged as the rebuild
+// itself is already finished at this point.
+func (a *Actions) checkSubcorpora(corpusID string) {
+	subcorpora, err := a.subcRegistry.List(corpusID, "")
+	if err != nil {
+		log.Error().Err(err).Str("corpusId", corpusID).Msg("failed to check saved subcorpora")
+		return
+	}
+	if len(subcorpora) == 0 {
+		return
+	}
+	corpInfo, err := a.corpusMeta.LoadInfo(corpusID)
+	if err != nil {
+		log.Error().Err(err).Str("corpusId", corpusID).Msg("failed to check saved subcorpora")
+		return
+	}
+	if corpInfo.BibIDAttr == "" {
+		log.Warn().Str("corpusId", corpusID).Msg("cannot check saved subcorpora - bib. ID not defined")
+		return
+	}
+	for _, subc := range subcorpora {
+		frozen, err := a.subcRegistry.DocIDs(subc.ID)
+		if err != nil {
+			log.Error().Err(err).Str("corpusId", corpusID).Str("subcId", subc.ID).Msg("failed to check saved subcorpus")
+			continue
+		}
+		var current []string
+		if subc.Kind == subcorpus.KindSelection {
+			current, err = a.selectionDocIDs(corpInfo, subc.Definition)
+
+		} else {
+			current, err = a.subcRegistry.ExistingDocIDs(corpInfo, subc.ID)
+		}
+		if err != nil {
+			log.Error().Err(err).Str("corpusId", corpusID).Str("subcId", subc.ID).Msg("failed to check saved subcorpus")
+			continue
+		}
+		diff := subcorpus.DiffMembership(frozen, current)
+		if err := a.subcRegistry.UpdateAfterRebuild(corpInfo, subc.ID, diff); err != nil {
+			log.Error().Err(err).Str("corpusId", corpusID).Str("subcId", subc.ID).Msg("failed to check saved subcorpus")
+			continue
+		}
+		if diff.HasChanges() {
+			log.Warn().
+				Str("corpusId", corpusID).
+				Str("subcId", subc.ID).
+				Str("name", subc.Name).
+				Int("added", len(diff.Added)).
+				Int("removed", len(diff.Removed)).
+				Msg("liveattrs rebuild changed membership of a saved subcorpus")
+		}
+	}
+}
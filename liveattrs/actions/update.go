@@ -0,0 +1,258 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"frodo/jobs"
+	"frodo/liveattrs"
+	"frodo/liveattrs/db"
+	"frodo/liveattrs/laconf"
+	"frodo/liveattrs/utils"
+	"net/http"
+
+	"github.com/czcorpus/cnc-gokit/uniresp"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+
+	vteCnf "github.com/czcorpus/vert-tagextract/v3/cnf"
+	vteDB "github.com/czcorpus/vert-tagextract/v3/db"
+	"github.com/czcorpus/vert-tagextract/v3/fs"
+	vteLib "github.com/czcorpus/vert-tagextract/v3/library"
+)
+
+var (
+	ErrorInvalidUpdate = errors.New("invalid liveattrs update")
+)
+
+type updateDataArgs struct {
+
+	// VerticalFiles contain only new and changed documents
+	VerticalFiles []string `json:"verticalFiles"`
+
+	// DeleteIDs contains bib. IDs of documents to be removed
+	DeleteIDs []string `json:"deleteIds"`
+
+	// DeleteOrphaned allows removing rows of aligned corpora whose
+	// items are no longer present in the updated corpus
+	DeleteOrphaned bool `json:"deleteOrphaned"`
+}
+
+func (args *updateDataArgs) validate() error {
+	if len(args.VerticalFiles) == 0 && len(args.DeleteIDs) == 0 {
+		return fmt.Errorf("%w: no vertical files and no documents to delete", ErrorInvalidUpdate)
+	}
+	for _, vert := range args.VerticalFiles {
+		if !fs.IsFile(vert) {
+			return fmt.Errorf("%w: vertical not found: %s", ErrorInvalidUpdate, vert)
+		}
+	}
+	return nil
+}
+
+// stagingName returns a name of tables the updated documents
+// are extracted into before they are merged with the current data
+func stagingName(corpusID string) string {
+	return corpusID + "_upd"
+}
+
+// stagingVteConf derives an extraction configuration writing just
+// the data of documents into staging tables. Everything what works
+// with whole data (n-grams, bib. view, data window etc.) is disabled.
+func stagingVteConf(conf vteCnf.VTEConf, corpusID string) *vteCnf.VTEConf {
+	conf.ParallelCorpus = stagingName(corpusID)
+	conf.Ngrams = vteCnf.NgramConf{}
+	conf.LiveTokens = nil
+	conf.BibView = vteDB.BibViewConf{}
+	conf.IndexedCols = []string{}
+	conf.RemoveEntriesBeforeDate = nil
+	conf.DateAttr = nil
+	return &conf
+}
+
+// updateJobFunc creates a job function for an incremental update
+// of liveattrs data. New versions of documents are first extracted
+// into staging tables and then merged with the current data within
+// a single transaction.
+func (a *Actions) updateJobFunc(initialStatus *liveattrs.LiveAttrsJobInfo) *jobs.JobFunc {
+	fn := func(jctx context.Context, updateJobChan chan<- jobs.GeneralJobInfo) {
+		defer close(updateJobChan)
+		jobStatus := *initialStatus
+		jobStatus.Update = jobs.CurrentDatetime()
+		vteConf := &jobStatus.Args.VteConf
+		staging := stagingName(jobStatus.CorpusID)
+
+		if vteConf.HasConfiguredVertical() {
+			procStatus, err := vteLib.ExtractData(jctx, stagingVteConf(*vteConf, jobStatus.CorpusID), false)
+			if err != nil {
+				updateJobChan <- jobStatus.WithError(
+					fmt.Errorf("failed to start vert-tagextract: %s", err))
+				return
+			}
+			var lastErr error
+			for upd := range procStatus {
+				lastErr = upd.Error
+				jobStatus.ProcessedAtoms = upd.ProcessedAtoms
+				jobStatus.ProcessedLines = upd.ProcessedLines
+				jobStatus.ProcessedTokens = upd.ProcessedTokens
+				updateJobChan <- jobStatus
+				if upd.Error != nil {
+					log.Error().Str("corpusId", jobStatus.CorpusID).Err(upd.Error).Msg("(just registered)")
+				}
+			}
+			if jctx.Err() != nil || lastErr != nil {
+				if err := db.DropStagingTables(a.laDB.DB(), staging); err != nil {
+					log.Error().Err(err).Str("corpusId", jobStatus.CorpusID).Msg("failed to clean up unfinished job")
+				}
+				if jctx.Err() != nil {
+					log.Info().Str("corpusId", jobStatus.CorpusID).Msg("live attributes update cancelled")
+
+				} else {
+					updateJobChan <- jobStatus.WithError(
+						fmt.Errorf("live attributes extraction failed: %w", lastErr))
+				}
+				return
+			}
+
+		} else {
+			staging = ""
+		}
+
+		result, err := db.MergeDocuments(
+			a.laDB.DB(),
+			db.MergeArgs{
+				GroupedName:    vteGroupedName(vteConf),
+				StagingName:    staging,
+				CorpusID:       jobStatus.CorpusID,
+				BibIDColumn:    utils.ImportKey(vteConf.BibView.IDAttr),
+				DeleteIDs:      jobStatus.Args.Update.DeleteIDs,
+				HasItemID:      vteConf.SelfJoin.IsConfigured(),
+				DeleteOrphaned: jobStatus.Args.Update.DeleteOrphaned,
+			},
+		)
+		if staging != "" {
+			if err := db.DropStagingTables(a.laDB.DB(), staging); err != nil {
+				log.Error().Err(err).Str("corpusId", jobStatus.CorpusID).Msg("failed to remove staging tables")
+			}
+		}
+		result.StaleColcounts = !vteConf.Ngrams.IsZero()
+		jobStatus.UpdateResult = &result
+		if err != nil {
+			updateJobChan <- jobStatus.WithError(err)
+			return
+		}
+		if result.NumOrphanedDeleted > 0 {
+			log.Warn().
+				Str("corpusId", jobStatus.CorpusID).
+				Int("numOrphaned", len(result.OrphanedItemIDs)).
+				Int("numDeletedRows", result.NumOrphanedDeleted).
+				Msg("liveattrs update removed aligned items without counterparts")
+		}
+		if result.StaleColcounts {
+			log.Warn().
+				Str("corpusId", jobStatus.CorpusID).
+				Msg("liveattrs update does not refresh colcounts, a full rebuild is needed to update n-grams")
+		}
+		a.invalidateDataCaches(jobStatus.CorpusID)
+		for _, aligned := range result.OrphanedCorpora {
+			a.invalidateDataCaches(aligned)
+		}
+		updateJobChan <- jobStatus.AsFinished()
+		a.checkSubcorpora(jobStatus.CorpusID)
+	}
+	return &fn
+}
+
+// UpdateData godoc
+// @Summary      Incrementally update liveattrs data of a corpus
+// @Description  Replace documents found in provided vertical files (= new and changed documents only) and remove documents with specified IDs. Documents are identified by the bib. ID attribute (bibView.idAttr). The update runs as a background job. In case the update leaves self-join items of aligned corpora without counterparts, it fails unless deleteOrphaned is set (then the aligned rows are removed as well). The colcounts table (n-gram source data) cannot be updated incrementally, a full rebuild is needed to refresh it.
+// @Accept  	 json
+// @Produce      json
+// @Param        corpusId path string true "Used corpus"
+// @Param 		 args body updateDataArgs true "Changed documents"
+// @Param        parentJobId query []string false "Run the job once the specified jobs finish" collectionFormat(multi)
+// @Success      201 {object} any
+// @Router       /liveAttributes/{corpusId}/data [patch]
+func (a *Actions) UpdateData(ctx *gin.Context) {
+	corpusID := ctx.Param("corpusId")
+	baseErrTpl := "failed to update liveattrs of %s: %w"
+	var args updateDataArgs
+	if err := json.NewDecoder(ctx.Request.Body).Decode(&args); err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusBadRequest)
+		return
+	}
+	if err := args.validate(); err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusBadRequest)
+		return
+	}
+	conf, err := a.laConfCache.Get(corpusID)
+	if err == laconf.ErrorNoSuchConfig {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusNotFound)
+		return
+
+	} else if err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
+		return
+	}
+	if conf.BibView.IDAttr == "" {
+		err := fmt.Errorf("%w: bibView.idAttr not configured", ErrorInvalidUpdate)
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusConflict)
+		return
+	}
+	if conf.DB.Type != "mysql" {
+		err := fmt.Errorf("%w: only mysql liveattrs backend is supported in Frodo", ErrorInvalidUpdate)
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusConflict)
+		return
+	}
+	parentJobIDs := ctx.QueryArray("parentJobId")
+	unlock := a.lockCorpus(corpusID)
+	defer unlock()
+	if prev, ok := a.jobActions.LastUnfinishedJobOfType(corpusID, liveattrs.JobType); ok {
+		err := fmt.Errorf("the previous job %s not finished yet", prev.GetID())
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusConflict)
+		return
+	}
+	confVersion, err := a.laConfCache.CurrentVersion(corpusID)
+	if err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
+		return
+	}
+	runtimeConf := *conf
+	runtimeConf.VerticalFile = ""
+	runtimeConf.VerticalFiles = args.VerticalFiles
+	status := &liveattrs.LiveAttrsJobInfo{
+		ID:       uuid.New().String(),
+		Type:     liveattrs.JobType,
+		CorpusID: corpusID,
+		Start:    jobs.CurrentDatetime(),
+		Update:   jobs.CurrentDatetime(),
+		Args: liveattrs.JobInfoArgs{
+			VteConf:          runtimeConf,
+			NoCorpusDBUpdate: true,
+			Update: &liveattrs.UpdateArgs{
+				DeleteIDs:      args.DeleteIDs,
+				DeleteOrphaned: args.DeleteOrphaned,
+			},
+			ConfVersion: confVersion,
+		},
+	}
+	a.jobActions.EnqueueJobAfterAll(a.updateJobFunc(status), status, parentJobIDs)
+	uniresp.WriteJSONResponseWithStatus(ctx.Writer, http.StatusCreated, status.FullInfo())
+}
@@ -0,0 +1,230 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package actions
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"frodo/corpus"
+	"frodo/liveattrs/db"
+	"frodo/liveattrs/laconf"
+	"frodo/liveattrs/vertcheck"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/czcorpus/cnc-gokit/uniresp"
+	"github.com/gin-gonic/gin"
+
+	vteCnf "github.com/czcorpus/vert-tagextract/v3/cnf"
+	"github.com/czcorpus/vert-tagextract/v3/fs"
+)
+
+var (
+	ErrorUnsupportedVertical = errors.New("unsupported vertical source")
+)
+
+type validationResponse struct {
+	vertcheck.Report
+
+	VerticalFiles []string `json:"verticalFiles"`
+
+	// EstimatedNumLines is extrapolated from the size of the scanned
+	// part of the vertical in case only a sample has been validated
+	EstimatedNumLines int `json:"estimatedNumLines"`
+
+	// EstimatedProcTimeSecs is based on previous liveattrs runs
+	// (-1 if not available)
+	EstimatedProcTimeSecs int `json:"estimatedProcTimeSecs"`
+}
+
+// countingReader counts bytes read from the underlying reader
+type countingReader struct {
+	rd    io.Reader
+	count int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.rd.Read(p)
+	cr.count += int64(n)
+	return n, err
+}
+
+// listVerticalFiles expands configured verticals into a list of files
+// in the same way vert-tagextract does
+func listVerticalFiles(conf *vteCnf.VTEConf) ([]string, error) {
+	ans := make([]string, 0, len(conf.GetDefinedVerticals()))
+	for _, path := range conf.GetDefinedVerticals() {
+		if strings.HasPrefix(path, "|") {
+			return nil, fmt.Errorf("%w: dynamically generated vertical %s", ErrorUnsupportedVertical, path)
+
+		} else if fs.IsDir(path) {
+			files, err := fs.ListFilesInDir(path)
+			if err != nil {
+				return nil, err
+			}
+			ans = append(ans, files...)
+
+		} else if fs.IsFile(path) {
+			ans = append(ans, path)
+
+		} else {
+			return nil, fmt.Errorf("%w: vertical not found: %s", ErrorUnsupportedVertical, path)
+		}
+	}
+	if len(ans) == 0 {
+		return nil, ErrorMissingVertical
+	}
+	return ans, nil
+}
+
+// validateVerticalFile scans a single (possibly gzipped) vertical file
+// and returns the number of (compressed) bytes read
+func validateVerticalFile(validator *vertcheck.Validator, path string) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	crd := &countingReader{rd: f}
+	var rd io.Reader = crd
+	if strings.HasSuffix(path, ".gz") {
+		gzrd, err := gzip.NewReader(crd)
+		if err != nil {
+			return crd.count, fmt.Errorf("failed to open vertical %s: %w", path, err)
+		}
+		defer gzrd.Close()
+		rd = gzrd
+	}
+	err = validator.Process(path, rd)
+	return crd.count, err
+}
+
+// validationConf returns the stored liveattrs config patched by
+// provided arguments. In case there is no stored config, a new one
+// is created (but not saved).
+func (a *Actions) validationConf(corpusID string, jsonArgs *laconf.PatchArgs) (*vteCnf.VTEConf, error) {
+	conf, err := a.laConfCache.Get(corpusID)
+	if err == laconf.ErrorNoSuchConfig {
+		return a.createConf(corpusID, "", jsonArgs)
+
+	} else if err != nil {
+		return nil, err
+	}
+	runtimeConf := *conf
+	if err := a.applyPatchArgs(&runtimeConf, jsonArgs); err != nil {
+		return nil, err
+	}
+	if !runtimeConf.HasConfiguredVertical() {
+		corpusInfo, err := corpus.GetCorpusInfo(corpusID, a.conf.Corp, false)
+		if err != nil {
+			return nil, err
+		}
+		if err := a.ensureVerticalFile(&runtimeConf, corpusInfo); err != nil {
+			return nil, err
+		}
+	}
+	return &runtimeConf, nil
+}
+
+// Validate godoc
+// @Summary      Validate a vertical against liveattrs configuration
+// @Description  Validate performs a dry run of liveattrs data extraction. It scans the configured vertical (or its first N lines) and reports unknown and missing structures and attributes, value cardinalities, malformed lines, a recommended atom structure and an estimated processing time. The stored config can be patched for the validation (the patched config is not saved). No database is modified.
+// @Accept  	 json
+// @Produce      json
+// @Param        corpusId path string true "Used corpus"
+// @Param 		 patchArgs body laconf.PatchArgs false "Config data"
+// @Param 		 maxLines query int false "Validate only the first N lines" default(0)
+// @Success      200 {object} validationResponse
+// @Router       /liveAttributes/{corpusId}/validate [post]
+func (a *Actions) Validate(ctx *gin.Context) {
+	corpusID := ctx.Param("corpusId")
+	baseErrTpl := "failed to validate vertical of %s: %w"
+	var maxLines int
+	if v := ctx.Query("maxLines"); v != "" {
+		var err error
+		maxLines, err = strconv.Atoi(v)
+		if err != nil || maxLines < 0 {
+			err := fmt.Errorf("invalid maxLines value: %s", v)
+			uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusBadRequest)
+			return
+		}
+	}
+	jsonArgs, err := a.getPatchArgs(ctx.Request)
+	if err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusBadRequest)
+		return
+	}
+	conf, err := a.validationConf(corpusID, jsonArgs)
+	if errors.Is(err, ErrorMissingVertical) {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusConflict)
+		return
+
+	} else if err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusBadRequest)
+		return
+	}
+	files, err := listVerticalFiles(conf)
+	if errors.Is(err, ErrorUnsupportedVertical) || err == ErrorMissingVertical {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusConflict)
+		return
+
+	} else if err != nil {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
+		return
+	}
+
+	validator := vertcheck.NewValidator(conf.Structures, conf.AtomStructure, maxLines)
+	var bytesRead, totalSize int64
+	for _, file := range files {
+		totalSize += fs.FileSize(file)
+		if validator.LimitReached() {
+			continue
+		}
+		n, err := validateVerticalFile(validator, file)
+		bytesRead += n
+		if err != nil {
+			uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusUnprocessableEntity)
+			return
+		}
+		if ctx.Request.Context().Err() != nil {
+			return
+		}
+	}
+
+	ans := validationResponse{
+		Report:                validator.Report(),
+		VerticalFiles:         files,
+		EstimatedProcTimeSecs: -1,
+	}
+	ans.EstimatedNumLines = ans.NumLines
+	if !ans.Complete && bytesRead > 0 {
+		ans.EstimatedNumLines = int(float64(ans.NumLines) * float64(totalSize) / float64(bytesRead))
+	}
+	speed, err := db.EstimateProcSpeed(a.laDB.DB(), procTimeType, ans.EstimatedNumLines)
+	if err != nil && err != db.ErrorEstimationNotAvail {
+		uniresp.WriteJSONErrorResponse(ctx.Writer, uniresp.NewActionError(baseErrTpl, corpusID, err), http.StatusInternalServerError)
+		return
+
+	} else if err == nil && speed > 0 {
+		ans.EstimatedProcTimeSecs = int(math.Ceil(float64(ans.EstimatedNumLines) / speed))
+	}
+	uniresp.WriteJSONResponse(ctx.Writer, &ans)
+}
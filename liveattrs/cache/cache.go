@@ -19,6 +19,7 @@ package cache
 import (
 	"frodo/liveattrs/request/query"
 	"frodo/liveattrs/request/response"
+	"frodo/metrics"
 	"strings"
 	"sync"
 
@@ -48,10 +49,12 @@ type EmptyQueryCache struct {
 // Get returns a cached result based on provided corpus (and possible aligned corpora)
 // In case nothing is found, nil is returned
 func (qc *EmptyQueryCache) Get(corpusID string, qry query.Payload) *response.QueryAns {
-	if len(qry.Attrs) > 0 {
+	if qry.HasSelection() {
 		return nil
 	}
-	return qc.data[mkKey(corpusID, qry.Aligned)]
+	ans := qc.data[mkKey(corpusID, qry.Aligned)]
+	metrics.CountEmptyQueryCacheRequest(ans != nil)
+	return ans
 }
 
 // setKeyCorpusDependency create a dependency between corpus and cache key
@@ -71,7 +74,7 @@ func (qc *EmptyQueryCache) setKeyCorpusDependency(corpusID, key string) {
 }
 
 func (qc *EmptyQueryCache) Set(corpusID string, qry query.Payload, value *response.QueryAns) {
-	if len(qry.Attrs) > 0 {
+	if qry.HasSelection() {
 		return
 	}
 	qc.lock.Lock()
@@ -0,0 +1,270 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"frodo/liveattrs/request/query"
+	"frodo/liveattrs/request/response"
+	"frodo/metrics"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	dfltQueryCacheMaxEntries   = 10000
+	dfltQueryCacheMaxSizeBytes = 256 * 1024 * 1024
+	dfltQueryCacheTTLSecs      = 3600
+)
+
+// QueryCacheConf specifies bounds of QueryCache. Zero values
+// mean default values.
+type QueryCacheConf struct {
+	MaxEntries   int   `json:"maxEntries"`
+	MaxSizeBytes int64 `json:"maxSizeBytes"`
+	TTLSecs      int   `json:"ttlSecs"`
+}
+
+func (conf QueryCacheConf) maxEntries() int {
+	if conf.MaxEntries == 0 {
+		return dfltQueryCacheMaxEntries
+	}
+	return conf.MaxEntries
+}
+
+func (conf QueryCacheConf) maxSizeBytes() int64 {
+	if conf.MaxSizeBytes == 0 {
+		return dfltQueryCacheMaxSizeBytes
+	}
+	return conf.MaxSizeBytes
+}
+
+func (conf QueryCacheConf) ttl() time.Duration {
+	if conf.TTLSecs == 0 {
+		return time.Duration(dfltQueryCacheTTLSecs) * time.Second
+	}
+	return time.Duration(conf.TTLSecs) * time.Second
+}
+
+// canonicalQuery is a normalized form of a query used
+// to create cache keys
+type canonicalQuery struct {
+	Corpus           string        `json:"corpus"`
+	Aligned          []string      `json:"aligned"`
+	Attrs            query.Attrs   `json:"attrs"`
+	Filter           *query.Filter `json:"filter"`
+	AutocompleteAttr string        `json:"autocompleteAttr"`
+	MaxAttrListSize  int           `json:"maxAttrListSize"`
+	ApplyCutoff      bool          `json:"applyCutoff"`
+}
+
+// sortedListing returns a sorted copy of a value listing. In case
+// the value is not a listing of strings, it is returned as is.
+func sortedListing(v any) any {
+	tv, ok := v.([]any)
+	if !ok {
+		return v
+	}
+	ans := make([]string, len(tv))
+	for i, item := range tv {
+		s, ok := item.(string)
+		if !ok {
+			return v
+		}
+		ans[i] = s
+	}
+	slices.Sort(ans)
+	return ans
+}
+
+// QueryKey creates a cache key of a query. Queries differing
+// only in the order of aligned corpora, attributes or selected
+// values produce the same key.
+func QueryKey(corpusID string, qry query.Payload) string {
+	cq := canonicalQuery{
+		Corpus:           corpusID,
+		Aligned:          slices.Sorted(slices.Values(qry.Aligned)),
+		Attrs:            make(query.Attrs, len(qry.Attrs)),
+		Filter:           qry.Filter,
+		AutocompleteAttr: qry.AutocompleteAttr,
+		MaxAttrListSize:  qry.MaxAttrListSize,
+		ApplyCutoff:      qry.ApplyCutoff,
+	}
+	for k, v := range qry.Attrs {
+		cq.Attrs[k] = sortedListing(v)
+	}
+	// map keys are sorted by the encoder
+	data, err := json.Marshal(cq)
+	if err != nil {
+		// this should not happen with decoded JSON data
+		log.Error().Err(err).Msg("failed to encode query cache key")
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+type queryCacheEntry struct {
+	key     string
+	corpora []string
+	value   *response.QueryAns
+	size    int64
+	expires time.Time
+}
+
+// QueryCache is an LRU cache for results of liveattrs queries with
+// a text type selection (i.e. the ones not handled by EmptyQueryCache).
+// The cache is bounded by the number of entries and by an estimated size
+// of stored results. Entries also expire after a configured time.
+type QueryCache struct {
+	conf    QueryCacheConf
+	entries map[string]*list.Element
+
+	// lru contains *queryCacheEntry items, the most recently
+	// used ones first
+	lru *list.List
+
+	size int64
+
+	// corpKeyDeps maps corpus ID to cache keys it is involved in
+	// (see EmptyQueryCache)
+	corpKeyDeps map[string]map[string]bool
+
+	now  func() time.Time
+	lock sync.Mutex
+}
+
+// removeElement removes an entry from the cache. The method
+// expects the lock to be held.
+func (qc *QueryCache) removeElement(elm *list.Element) {
+	entry := qc.lru.Remove(elm).(*queryCacheEntry)
+	delete(qc.entries, entry.key)
+	qc.size -= entry.size
+	for _, corpusID := range entry.corpora {
+		delete(qc.corpKeyDeps[corpusID], entry.key)
+		if len(qc.corpKeyDeps[corpusID]) == 0 {
+			delete(qc.corpKeyDeps, corpusID)
+		}
+	}
+}
+
+// Get returns a cached result of a query. In case nothing
+// is found (or the query has no text type selection), nil is returned.
+func (qc *QueryCache) Get(corpusID string, qry query.Payload) *response.QueryAns {
+	if !qry.HasSelection() {
+		return nil
+	}
+	key := QueryKey(corpusID, qry)
+	qc.lock.Lock()
+	defer qc.lock.Unlock()
+	elm, ok := qc.entries[key]
+	if !ok {
+		metrics.CountQueryCacheRequest(false)
+		return nil
+	}
+	entry := elm.Value.(*queryCacheEntry)
+	if qc.now().After(entry.expires) {
+		qc.removeElement(elm)
+		metrics.CountQueryCacheRequest(false)
+		return nil
+	}
+	qc.lru.MoveToFront(elm)
+	metrics.CountQueryCacheRequest(true)
+	return entry.value
+}
+
+// Set stores a query result. Least recently used entries
+// are evicted in case the cache bounds are exceeded.
+func (qc *QueryCache) Set(corpusID string, qry query.Payload, value *response.QueryAns) {
+	if !qry.HasSelection() {
+		return
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to estimate size of a cached query result")
+		return
+	}
+	size := int64(len(data))
+	if size > qc.conf.maxSizeBytes() {
+		return
+	}
+	key := QueryKey(corpusID, qry)
+	qc.lock.Lock()
+	defer qc.lock.Unlock()
+	if elm, ok := qc.entries[key]; ok {
+		qc.removeElement(elm)
+	}
+	entry := &queryCacheEntry{
+		key:     key,
+		corpora: append([]string{corpusID}, qry.Aligned...),
+		value:   value,
+		size:    size,
+		expires: qc.now().Add(qc.conf.ttl()),
+	}
+	qc.entries[key] = qc.lru.PushFront(entry)
+	qc.size += size
+	for _, c := range entry.corpora {
+		if _, ok := qc.corpKeyDeps[c]; !ok {
+			qc.corpKeyDeps[c] = make(map[string]bool)
+		}
+		qc.corpKeyDeps[c][key] = true
+	}
+	for qc.lru.Len() > qc.conf.maxEntries() || qc.size > qc.conf.maxSizeBytes() {
+		qc.removeElement(qc.lru.Back())
+	}
+}
+
+// Del removes all the results of queries involving the corpus
+// (either as the main or as an aligned corpus)
+func (qc *QueryCache) Del(corpusID string) {
+	qc.lock.Lock()
+	defer qc.lock.Unlock()
+	var numRemoved int
+	for key := range qc.corpKeyDeps[corpusID] {
+		if elm, ok := qc.entries[key]; ok {
+			qc.removeElement(elm)
+			numRemoved++
+		}
+	}
+	delete(qc.corpKeyDeps, corpusID)
+	log.Info().
+		Str("corpusId", corpusID).
+		Int("numRemoved", numRemoved).
+		Msg("Deleting liveattrs query cache entries")
+}
+
+// Len returns the number of cached entries
+func (qc *QueryCache) Len() int {
+	qc.lock.Lock()
+	defer qc.lock.Unlock()
+	return qc.lru.Len()
+}
+
+func NewQueryCache(conf QueryCacheConf) *QueryCache {
+	return &QueryCache{
+		conf:        conf,
+		entries:     make(map[string]*list.Element),
+		lru:         list.New(),
+		corpKeyDeps: make(map[string]map[string]bool),
+		now:         time.Now,
+	}
+}
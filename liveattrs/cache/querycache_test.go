@@ -0,0 +1,86 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"frodo/liveattrs/request/query"
+	"frodo/liveattrs/request/response"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mkQuery(aligned []string, values ...any) query.Payload {
+	return query.Payload{
+		Aligned: aligned,
+		Attrs:   query.Attrs{"doc.genre": values},
+	}
+}
+
+func TestQueryKeyIsCanonical(t *testing.T) {
+	qry1 := mkQuery([]string{"corp2", "corp3"}, "fiction", "poetry")
+	qry2 := mkQuery([]string{"corp3", "corp2"}, "poetry", "fiction")
+	assert.Equal(t, QueryKey("corp1", qry1), QueryKey("corp1", qry2))
+	assert.NotEqual(t, QueryKey("corp1", qry1), QueryKey("corpX", qry1))
+
+	qry2.AutocompleteAttr = "doc.title"
+	assert.NotEqual(t, QueryKey("corp1", qry1), QueryKey("corp1", qry2))
+}
+
+func TestQueryCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	qcache := NewQueryCache(QueryCacheConf{MaxEntries: 2})
+	value := &response.QueryAns{Poscount: 10}
+	qcache.Set("corp1", mkQuery(nil, "a"), value)
+	qcache.Set("corp1", mkQuery(nil, "b"), value)
+	assert.NotNil(t, qcache.Get("corp1", mkQuery(nil, "a")))
+	qcache.Set("corp1", mkQuery(nil, "c"), value)
+	assert.Equal(t, 2, qcache.Len())
+	assert.NotNil(t, qcache.Get("corp1", mkQuery(nil, "a")))
+	assert.Nil(t, qcache.Get("corp1", mkQuery(nil, "b")))
+	assert.NotNil(t, qcache.Get("corp1", mkQuery(nil, "c")))
+}
+
+func TestQueryCacheRespectsSizeAndTTL(t *testing.T) {
+	now := time.Now()
+	qcache := NewQueryCache(QueryCacheConf{MaxSizeBytes: 60, TTLSecs: 10})
+	qcache.now = func() time.Time { return now }
+	qcache.Set("corp1", mkQuery(nil, "a"), &response.QueryAns{Poscount: 10})
+	qcache.Set("corp1", mkQuery(nil, "b"), &response.QueryAns{Poscount: 10})
+	assert.Equal(t, 1, qcache.Len())
+	assert.Nil(t, qcache.Get("corp1", mkQuery(nil, "a")))
+	assert.NotNil(t, qcache.Get("corp1", mkQuery(nil, "b")))
+
+	now = now.Add(11 * time.Second)
+	assert.Nil(t, qcache.Get("corp1", mkQuery(nil, "b")))
+	assert.Equal(t, 0, qcache.Len())
+	assert.Equal(t, int64(0), qcache.size)
+}
+
+func TestQueryCacheDelByAlignedCorpus(t *testing.T) {
+	qcache := NewQueryCache(QueryCacheConf{})
+	value := &response.QueryAns{Poscount: 10}
+	qcache.Set("corp1", mkQuery([]string{"corp2"}, "a"), value)
+	qcache.Set("corp3", mkQuery(nil, "a"), value)
+	qcache.Set("corp1", query.Payload{}, value)
+	assert.Equal(t, 2, qcache.Len())
+
+	qcache.Del("corp2")
+	assert.Nil(t, qcache.Get("corp1", mkQuery([]string{"corp2"}, "a")))
+	assert.NotNil(t, qcache.Get("corp3", mkQuery(nil, "a")))
+	assert.NotContains(t, qcache.corpKeyDeps, "corp1")
+	assert.Contains(t, qcache.corpKeyDeps, "corp3")
+}
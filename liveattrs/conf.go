@@ -17,13 +17,80 @@
 package liveattrs
 
 import (
+	"frodo/liveattrs/cache"
+
 	vtedb "github.com/czcorpus/vert-tagextract/v3/db"
 )
 
+const (
+	dfltAutoIndexesMaxColumns = 5
+	dfltAutoIndexesMinUsage   = 1
+	dfltSwapMaxShrinkRatio    = 0.5
+)
+
+// AutoIndexesConf configures indexes of liveattrs tables
+// created based on how often individual attributes are queried.
+// Zero values mean default values.
+type AutoIndexesConf struct {
+
+	// Disabled turns off updating indexes after each liveattrs build.
+	// The indexes can still be updated via the API.
+	Disabled bool `json:"disabled"`
+
+	// MaxColumns is a max. number of automatically indexed columns
+	MaxColumns int `json:"maxColumns"`
+
+	// MinUsage is a min. number of queries involving an attribute
+	// for the attribute to be indexed
+	MinUsage int `json:"minUsage"`
+}
+
+func (conf AutoIndexesConf) GetMaxColumns() int {
+	if conf.MaxColumns == 0 {
+		return dfltAutoIndexesMaxColumns
+	}
+	return conf.MaxColumns
+}
+
+func (conf AutoIndexesConf) GetMinUsage() int {
+	if conf.MinUsage == 0 {
+		return dfltAutoIndexesMinUsage
+	}
+	return conf.MinUsage
+}
+
+// SwapConf configures checks of rebuilt liveattrs data performed
+// before the data replace the current ones. Zero values mean
+// default values.
+type SwapConf struct {
+
+	// MaxShrinkRatio is a max. allowed relative decrease of the number
+	// of rows compared with the current data (e.g. 0.5 means the rebuilt
+	// data must contain at least half of the current rows). The ratio
+	// applies to the rebuilt corpus, to each aligned corpus sharing
+	// the table and to the whole table. A negative value disables the check.
+	MaxShrinkRatio float64 `json:"maxShrinkRatio"`
+}
+
+func (conf SwapConf) GetMaxShrinkRatio() float64 {
+	if conf.MaxShrinkRatio == 0 {
+		return dfltSwapMaxShrinkRatio
+	}
+	return conf.MaxShrinkRatio
+}
+
 type Conf struct {
 	DB                       *vtedb.Conf `json:"db"`
 	CustomNgramTablesDataDir string      `json:"customNgramTablesDataDir"`
 	ConfDirPath              string      `json:"confDirPath"`
 	VertMaxNumErrors         int         `json:"vertMaxNumErrors"`
 	VerticalFilesDirPath     string      `json:"verticalFilesDirPath"`
+
+	// QueryCache specifies bounds of the cache for liveattrs
+	// queries with selected attributes
+	QueryCache cache.QueryCacheConf `json:"queryCache"`
+
+	AutoIndexes AutoIndexesConf `json:"autoIndexes"`
+
+	Swap SwapConf `json:"swap"`
 }
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"frodo/corpus"
 	"frodo/liveattrs/db/qbuilder/adhoc"
+	"frodo/liveattrs/laconf"
 	"frodo/liveattrs/request/query"
 )
 
@@ -36,18 +37,50 @@ func DeleteTable(tx *sql.Tx, groupedName string, corpusName string) error {
 		_, err = tx.Exec(
 			fmt.Sprintf("DROP TABLE %s_liveattrs_entry", groupedName),
 		)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(
+			fmt.Sprintf("DROP TABLE IF EXISTS %s_liveattrs_entry%s", groupedName, prevTableSuffix),
+		)
 	}
 	return err
 }
 
-func GetSubcSize(laDB *sql.DB, corpusInfo *corpus.DBInfo, corpora []string, attrMap query.Attrs) (int, error) {
+// DropTmpTables removes temporary tables ([grouped name]_[data type]_new)
+// created by a data extraction process which has not finished
+// (e.g. because it was cancelled).
+func DropTmpTables(db *sql.DB, groupedName string) error {
+	for _, tbl := range []string{"liveattrs_entry", "colcounts"} {
+		if _, err := db.Exec(
+			fmt.Sprintf("DROP TABLE IF EXISTS %s_%s_new", groupedName, tbl),
+		); err != nil {
+			return fmt.Errorf("failed to drop temporary %s table: %w", tbl, err)
+		}
+	}
+	return nil
+}
+
+func GetSubcSize(
+	laDB *sql.DB,
+	corpusInfo *corpus.DBInfo,
+	corpora []string,
+	attrMap query.Attrs,
+	attrTypes laconf.AttrTypes,
+	filter *query.Filter,
+) (int, error) {
 	sizeCalc := adhoc.SubcSize{
 		CorpusInfo:          corpusInfo,
 		AttrMap:             attrMap,
 		AlignedCorpora:      corpora[1:],
 		EmptyValPlaceholder: "", // TODO !!!!
+		AttrTypes:           attrTypes,
+		Filter:              filter,
+	}
+	sqlq, args, err := sizeCalc.Query()
+	if err != nil {
+		return 0, err
 	}
-	sqlq, args := sizeCalc.Query()
 	cur := laDB.QueryRow(sqlq, args...)
 	var ans sql.NullInt64
 	if err := cur.Scan(&ans); err != nil {
@@ -20,6 +20,7 @@ import (
 	"database/sql"
 	"fmt"
 	"frodo/corpus"
+	"frodo/liveattrs/db/qbuilder"
 	"frodo/liveattrs/laconf"
 	"frodo/liveattrs/request/biblio"
 	"frodo/liveattrs/request/query"
@@ -180,9 +181,9 @@ func extractRegexp(v map[string]any) string {
 	return ""
 }
 
-func attrsToSQL(attrs query.Attrs) (string, []any) {
+func attrsToSQL(attrs query.Attrs, attrTypes laconf.AttrTypes) (string, []any, error) {
 	if len(attrs) == 0 {
-		return "1", []any{}
+		return "1", []any{}, nil
 	}
 	sql := make([]string, 0, len(attrs))
 	sqlValues := make([]any, 0, len(attrs)*2)
@@ -224,6 +225,20 @@ func attrsToSQL(attrs query.Attrs) (string, []any) {
 				}
 				sqlValues = append(sqlValues, v)
 
+			} else if from, to, ok := attrs.GetRangeAttrVal(attr); ok {
+				rangeSQL, rangeVals, err := qbuilder.RangeSQL(
+					"t1."+utils.ImportKey(attr),
+					attrTypes.TypeOf(strings.TrimPrefix(attr, "!")),
+					from, to, exclude,
+				)
+				if err != nil {
+					return "", []any{}, err
+				}
+				sql = append(sql, rangeSQL)
+				for _, v := range rangeVals {
+					sqlValues = append(sqlValues, v)
+				}
+
 			} else {
 				log.Error().Msgf("Incorrect value passed as attribute value filter - map[string]any should contain only 'regexp'")
 			}
@@ -231,7 +246,7 @@ func attrsToSQL(attrs query.Attrs) (string, []any) {
 			panic(fmt.Sprintf("cannot process non-list attribute values; found: %s", reflect.TypeOf(values)))
 		}
 	}
-	return strings.Join(sql, " AND "), sqlValues
+	return strings.Join(sql, " AND "), sqlValues, nil
 }
 
 func buildQuery(
@@ -239,7 +254,9 @@ func buildQuery(
 	corpusInfo *corpus.DBInfo,
 	alignedCorpora []string,
 	filterAttrs query.Attrs,
-) (string, []any) {
+	attrTypes laconf.AttrTypes,
+	filter *query.Filter,
+) (string, []any, error) {
 	sql := strings.Builder{}
 	sql.WriteString(fmt.Sprintf(
 		"SELECT %s FROM `%s_liveattrs_entry` AS t1 ",
@@ -259,11 +276,24 @@ func buildQuery(
 	for _, w := range whereSQL {
 		sql.WriteString(" AND " + w)
 	}
-	aSql, aValues := attrsToSQL(filterAttrs)
+	aSql, aValues, err := attrsToSQL(filterAttrs, attrTypes)
+	if err != nil {
+		return "", []any{}, err
+	}
 	sql.WriteString(" AND " + aSql)
 	queryArgs = append(queryArgs, aValues...)
+	if filter != nil {
+		fSql, fValues, err := qbuilder.FilterSQL(filter, "t1", "")
+		if err != nil {
+			return "", []any{}, err
+		}
+		sql.WriteString(" AND " + fSql)
+		for _, v := range fValues {
+			queryArgs = append(queryArgs, v)
+		}
+	}
 	sql.WriteString(fmt.Sprintf(" GROUP BY t1.%s", utils.ImportKey(corpusInfo.BibIDAttr)))
-	return sql.String(), queryArgs
+	return sql.String(), queryArgs, nil
 }
 
 func GetNumOfDocuments(
@@ -271,12 +301,17 @@ func GetNumOfDocuments(
 	corpusInfo *corpus.DBInfo,
 	alignedCorpora []string,
 	attrs query.Attrs,
+	attrTypes laconf.AttrTypes,
+	filter *query.Filter,
 ) (int, error) {
-	sql, args := buildQuery([]string{"t1.*"}, corpusInfo, alignedCorpora, attrs)
+	sql, args, err := buildQuery([]string{"t1.*"}, corpusInfo, alignedCorpora, attrs, attrTypes, filter)
+	if err != nil {
+		return 0, err
+	}
 	wsql := fmt.Sprintf("SELECT COUNT(*) FROM (%s) AS docitems", sql)
 	row := db.QueryRow(wsql, args...)
 	var ans int
-	err := row.Scan(&ans)
+	err = row.Scan(&ans)
 	if err != nil {
 		return 0, err
 	}
@@ -291,14 +326,17 @@ func mkAttrs(attrs []string) map[string]string {
 	return ans
 }
 
-func GetDocuments(
+// queryDocuments runs a query for documents matching provided
+// text type selection
+func queryDocuments(
 	db *sql.DB,
 	corpusInfo *corpus.DBInfo,
 	viewAttrs []string,
 	alignedCorpora []string,
 	filterAttrs query.Attrs,
-	page PageInfo,
-) ([]*DocumentRow, error) {
+	attrTypes laconf.AttrTypes,
+	filter *query.Filter,
+) (*sql.Rows, error) {
 	wpAttrs := attrsWithPrefix(viewAttrs)
 	selAttrs := make([]string, 0, len(wpAttrs)+2)
 	selAttrs = append(
@@ -311,9 +349,51 @@ func GetDocuments(
 	)
 	selAttrs = append(selAttrs, "SUM(t1.poscount)")
 	selAttrs = append(selAttrs, wpAttrs...)
-	sqlq, args := buildQuery(selAttrs, corpusInfo, alignedCorpora, filterAttrs)
+	sqlq, args, err := buildQuery(selAttrs, corpusInfo, alignedCorpora, filterAttrs, attrTypes, filter)
+	if err != nil {
+		return nil, err
+	}
+	return db.Query(sqlq, args...)
+}
+
+func scanDocumentRow(rows *sql.Rows, viewAttrs []string, idx int) (*DocumentRow, error) {
+	docEntryLabel := sql.NullString{}
+	docEntry := &DocumentRow{Idx: idx}
+	docEntry.Attrs = mkAttrs(viewAttrs)
+	attrVals := make([]sql.NullString, len(viewAttrs))
+	scanVals := make([]any, 3+len(viewAttrs))
+	scanVals[0] = &docEntry.ID
+	scanVals[1] = &docEntryLabel
+	scanVals[2] = &docEntry.NumPos
+	for i := range attrVals {
+		scanVals[3+i] = &attrVals[i]
+	}
+	if err := rows.Scan(scanVals...); err != nil {
+		return nil, err
+	}
+	if docEntryLabel.Valid {
+		docEntry.Label = docEntryLabel.String
+	}
+	for i, v := range attrVals {
+		if v.Valid {
+			docEntry.Attrs[viewAttrs[i]] = v.String
+		}
+	}
+	return docEntry, nil
+}
+
+func GetDocuments(
+	db *sql.DB,
+	corpusInfo *corpus.DBInfo,
+	viewAttrs []string,
+	alignedCorpora []string,
+	filterAttrs query.Attrs,
+	attrTypes laconf.AttrTypes,
+	filter *query.Filter,
+	page PageInfo,
+) ([]*DocumentRow, error) {
 	//page.ToSQL(), TODO
-	rows, err := db.Query(sqlq, args...)
+	rows, err := queryDocuments(db, corpusInfo, viewAttrs, alignedCorpora, filterAttrs, attrTypes, filter)
 	if err == sql.ErrNoRows {
 		return []*DocumentRow{}, nil
 
@@ -323,44 +403,54 @@ func GetDocuments(
 	defer rows.Close()
 	if page.MaxItems == 0 {
 		var err error
-		page.MaxItems, err = GetNumOfDocuments(db, corpusInfo, alignedCorpora, filterAttrs)
+		page.MaxItems, err = GetNumOfDocuments(db, corpusInfo, alignedCorpora, filterAttrs, attrTypes, filter)
 		if err != nil {
 			return []*DocumentRow{}, err
 		}
 	}
 	ans := make([]*DocumentRow, 0, page.NumItems())
-	attrVals := make([]sql.NullString, len(viewAttrs))
-	scanVals := make([]any, 3+len(viewAttrs))
-
 	i := page.Offset()
 	for rows.Next() {
-		docEntryLabel := sql.NullString{}
-		docEntry := &DocumentRow{Idx: i}
-		docEntry.Attrs = mkAttrs(viewAttrs)
-		scanVals[0] = &docEntry.ID
-		scanVals[1] = &docEntryLabel
-		scanVals[2] = &docEntry.NumPos
-
-		if docEntryLabel.Valid {
-			docEntry.Label = docEntryLabel.String
-		}
-
-		for i := range attrVals {
-			scanVals[3+i] = &attrVals[i]
-		}
-		err := rows.Scan(scanVals...)
+		docEntry, err := scanDocumentRow(rows, viewAttrs, i)
 		if err != nil {
 			return []*DocumentRow{}, err
 		}
-		for i := 3; i < len(scanVals); i++ {
-			if v, ok := scanVals[i].(*sql.NullString); ok {
-				if v.Valid {
-					docEntry.Attrs[viewAttrs[i-3]] = v.String
-				}
-			}
-		}
 		ans = append(ans, docEntry)
 		i++
 	}
 	return ans, nil
 }
+
+// IterDocuments calls fn for each document matching provided
+// text type selection. The rows are read directly from a database
+// cursor so even huge document lists can be processed in constant memory.
+// In case fn returns an error, the iteration stops and the error
+// is returned.
+func IterDocuments(
+	db *sql.DB,
+	corpusInfo *corpus.DBInfo,
+	viewAttrs []string,
+	alignedCorpora []string,
+	filterAttrs query.Attrs,
+	attrTypes laconf.AttrTypes,
+	filter *query.Filter,
+	fn func(row *DocumentRow) error,
+) error {
+	rows, err := queryDocuments(db, corpusInfo, viewAttrs, alignedCorpora, filterAttrs, attrTypes, filter)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	var i int
+	for rows.Next() {
+		docEntry, err := scanDocumentRow(rows, viewAttrs, i)
+		if err != nil {
+			return err
+		}
+		if err := fn(docEntry); err != nil {
+			return err
+		}
+		i++
+	}
+	return rows.Err()
+}
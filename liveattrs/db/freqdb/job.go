@@ -17,26 +17,43 @@
 package freqdb
 
 import (
+	"frodo/corpus"
 	"frodo/jobs"
+	"slices"
 	"time"
+
+	"github.com/czcorpus/mquery-common/corp"
+	vteCnf "github.com/czcorpus/vert-tagextract/v3/cnf"
 )
 
+// NgramJobInfoArgs contains all the arguments needed
+// to run the job again (e.g. after a server restart)
 type NgramJobInfoArgs struct {
+	GroupedName         string               `json:"groupedName"`
+	UsePartitionedTable bool                 `json:"usePartitionedTable"`
+	AppendExisting      bool                 `json:"appendExisting"`
+	NgramSize           int                  `json:"ngramSize"`
+	NgramConf           vteCnf.NgramConf     `json:"ngramConf"`
+	PosTagset           corp.SupportedTagset `json:"posTagset"`
+	ColMapping          corpus.QSAttributes  `json:"colMapping"`
+	MinFreq             int                  `json:"minFreq"`
 }
 
 // NgramJobInfo
 type NgramJobInfo struct {
-	ID              string           `json:"id"`
-	Type            string           `json:"type"`
-	CorpusID        string           `json:"corpusId"`
-	AliasedCorpusID string           `json:"aliasedCorpusId"`
-	Start           jobs.JSONTime    `json:"start"`
-	Update          jobs.JSONTime    `json:"update"`
-	Finished        bool             `json:"finished"`
-	Error           error            `json:"error,omitempty"`
-	NumRestarts     int              `json:"numRestarts"`
-	Args            NgramJobInfoArgs `json:"args"`
-	Result          genNgramsStatus  `json:"result"`
+	ID              string            `json:"id"`
+	Type            string            `json:"type"`
+	CorpusID        string            `json:"corpusId"`
+	AliasedCorpusID string            `json:"aliasedCorpusId"`
+	Start           jobs.JSONTime     `json:"start"`
+	Update          jobs.JSONTime     `json:"update"`
+	Finished        bool              `json:"finished"`
+	Cancelled       bool              `json:"cancelled"`
+	Error           error             `json:"error,omitempty"`
+	NumRestarts     int               `json:"numRestarts"`
+	Attempts        []jobs.JobAttempt `json:"attempts,omitempty"`
+	Args            NgramJobInfoArgs  `json:"args"`
+	Result          genNgramsStatus   `json:"result"`
 }
 
 func (j NgramJobInfo) GetID() string {
@@ -76,19 +93,68 @@ func (j NgramJobInfo) IsFinished() bool {
 	return j.Finished
 }
 
+func (j NgramJobInfo) GetProgress() jobs.JobProgress {
+	return jobs.JobProgress{
+		ProcessedLines: j.Result.NumProcLines,
+		TotalLines:     j.Result.TotalLines,
+	}
+}
+
+// IsIdempotent tells whether the job can be run again without
+// duplicating data. In the append mode, data written by a failed
+// run would be appended again.
+func (j NgramJobInfo) IsIdempotent() bool {
+	return !j.Args.AppendExisting
+}
+
+func (j NgramJobInfo) IsCancelled() bool {
+	return j.Cancelled
+}
+
+func (j NgramJobInfo) AsCancelled() jobs.GeneralJobInfo {
+	j.Update = jobs.CurrentDatetime()
+	j.Finished = true
+	j.Cancelled = true
+	j.Error = nil
+	return j
+}
+
+func (j NgramJobInfo) GetAttempts() []jobs.JobAttempt {
+	return j.Attempts
+}
+
+func (j NgramJobInfo) AsRetry() jobs.GeneralJobInfo {
+	j.Attempts = append(slices.Clone(j.Attempts), jobs.NewJobAttempt(j))
+	j.NumRestarts++
+	j.Start = jobs.CurrentDatetime()
+	j.Update = j.Start
+	j.Finished = false
+	j.Error = nil
+	return j
+}
+
+func (j NgramJobInfo) WithRetryState(prev jobs.GeneralJobInfo) jobs.GeneralJobInfo {
+	j.Start = prev.GetStartDT()
+	j.NumRestarts = prev.GetNumRestarts()
+	j.Attempts = prev.GetAttempts()
+	return j
+}
+
 func (j NgramJobInfo) FullInfo() any {
 	return struct {
-		ID          string           `json:"id"`
-		Type        string           `json:"type"`
-		CorpusID    string           `json:"corpusId"`
-		Start       jobs.JSONTime    `json:"start"`
-		Update      jobs.JSONTime    `json:"update"`
-		Finished    bool             `json:"finished"`
-		Error       string           `json:"error,omitempty"`
-		OK          bool             `json:"ok"`
-		NumRestarts int              `json:"numRestarts"`
-		Args        NgramJobInfoArgs `json:"args"`
-		Result      genNgramsStatus  `json:"result"`
+		ID          string            `json:"id"`
+		Type        string            `json:"type"`
+		CorpusID    string            `json:"corpusId"`
+		Start       jobs.JSONTime     `json:"start"`
+		Update      jobs.JSONTime     `json:"update"`
+		Finished    bool              `json:"finished"`
+		Cancelled   bool              `json:"cancelled"`
+		Error       string            `json:"error,omitempty"`
+		OK          bool              `json:"ok"`
+		NumRestarts int               `json:"numRestarts"`
+		Attempts    []jobs.JobAttempt `json:"attempts,omitempty"`
+		Args        NgramJobInfoArgs  `json:"args"`
+		Result      genNgramsStatus   `json:"result"`
 	}{
 		ID:          j.ID,
 		Type:        j.Type,
@@ -96,9 +162,11 @@ func (j NgramJobInfo) FullInfo() any {
 		Start:       j.Start,
 		Update:      j.Update,
 		Finished:    j.Finished,
+		Cancelled:   j.Cancelled,
 		Error:       jobs.ErrorToString(j.Error),
-		OK:          j.Error == nil,
+		OK:          j.Error == nil && !j.Cancelled,
 		NumRestarts: j.NumRestarts,
+		Attempts:    j.Attempts,
 		Args:        j.Args,
 		Result:      j.Result,
 	}
@@ -106,15 +174,16 @@ func (j NgramJobInfo) FullInfo() any {
 
 func (j NgramJobInfo) CompactVersion() jobs.JobInfoCompact {
 	item := jobs.JobInfoCompact{
-		ID:       j.ID,
-		Type:     j.Type,
-		CorpusID: j.CorpusID,
-		Start:    j.Start,
-		Update:   j.Update,
-		Finished: j.Finished,
-		OK:       true,
+		ID:        j.ID,
+		Type:      j.Type,
+		CorpusID:  j.CorpusID,
+		Start:     j.Start,
+		Update:    j.Update,
+		Finished:  j.Finished,
+		Cancelled: j.Cancelled,
+		OK:        true,
 	}
-	item.OK = j.Error == nil
+	item.OK = j.Error == nil && !j.Cancelled
 	return item
 }
 
@@ -131,7 +200,9 @@ func (j NgramJobInfo) WithError(err error) jobs.GeneralJobInfo {
 		Update:      jobs.JSONTime(time.Now()),
 		Finished:    true,
 		Error:       err,
+		Args:        j.Args,
 		Result:      j.Result,
 		NumRestarts: j.NumRestarts,
+		Attempts:    j.Attempts,
 	}
 }
@@ -30,12 +30,15 @@ import (
 	"frodo/db/mysql"
 	"frodo/jobs"
 	"frodo/liveattrs/db"
+	"frodo/metrics"
 	"math"
 	"strings"
 	"time"
 
 	"github.com/czcorpus/cnc-gokit/collections"
 	"github.com/czcorpus/cnc-gokit/util"
+	"github.com/czcorpus/mquery-common/corp"
+	vteCnf "github.com/czcorpus/vert-tagextract/v3/cnf"
 	"github.com/czcorpus/vert-tagextract/v3/ptcount/modders"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
@@ -60,6 +63,8 @@ type NgramFreqGenerator struct {
 	appendExisting       bool
 	ngramSize            int
 	posFn                *modders.StringTransformerChain
+	ngramConf            vteCnf.NgramConf
+	posTagset            corp.SupportedTagset
 	jobActions           *jobs.Actions
 	qsaAttrs             corpus.QSAttributes
 	minFreq              int
@@ -456,7 +461,7 @@ func (nfg *NgramFreqGenerator) preloadCols(
 			log.Warn().Err(err).Msg("failed to read colcounts record, skipping")
 			continue
 		}
-		if isStopNgram(rec.lemma) || rec.abs < nfg.minFreq {
+		if nfg.isIgnored(rec) {
 			numIgnored++
 			continue
 		}
@@ -516,6 +521,10 @@ func (nfg *NgramFreqGenerator) procChunk(
 				Int("numInserted", numInserted).
 				AnErr("lastError", lastErr).
 				Msg("performed alternative per-line insert")
+			metrics.AddNgramImportedItems(nfg.corpusName, numInserted)
+
+		} else {
+			metrics.AddNgramImportedItems(nfg.corpusName, len(batch))
 		}
 
 		procTime := time.Since(t0).Seconds()
@@ -549,6 +558,9 @@ func (nfg *NgramFreqGenerator) procChunk(
 		}
 		select {
 		case <-ctx.Done():
+			if err := tx.Rollback(); err != nil {
+				log.Error().Err(err).Msg("failed to roll back cancelled chunk")
+			}
 			baseStatus.Error = fmt.Errorf("action cancelled")
 			statusCh <- baseStatus
 			return false
@@ -653,6 +665,34 @@ func (nfg *NgramFreqGenerator) tablesExist() (bool, error) {
 	return ans, nil
 }
 
+// dropTables removes n-gram tables. It is used to clean up
+// partial output of a cancelled job.
+func (nfg *NgramFreqGenerator) dropTables() error {
+	for _, tbl := range []string{"term_search", "word"} {
+		if _, err := nfg.db.DB().Exec(
+			fmt.Sprintf("DROP TABLE IF EXISTS %s_%s", nfg.groupedName, tbl),
+		); err != nil {
+			return fmt.Errorf("failed to drop table %s_%s: %w", nfg.groupedName, tbl, err)
+		}
+	}
+	return nil
+}
+
+// cleanUpCancelled removes partial output of a cancelled job. In the append
+// mode, already committed chunks cannot be distinguished from the original
+// data so they are kept.
+func (nfg *NgramFreqGenerator) cleanUpCancelled() {
+	if nfg.appendExisting {
+		log.Warn().
+			Str("corpusId", nfg.corpusName).
+			Msg("n-gram job cancelled in append mode, already committed chunks are kept")
+		return
+	}
+	if err := nfg.dropTables(); err != nil {
+		log.Error().Err(err).Str("corpusId", nfg.corpusName).Msg("failed to clean up cancelled n-gram job")
+	}
+}
+
 // generateSync (synchronously) generates n-grams from raw liveattrs data
 // provided statusChan is closed by the method once
 // the operation finishes
@@ -701,9 +741,9 @@ func (nfg *NgramFreqGenerator) generateSync(
 }
 
 // GenerateAfter creates a new job to generate ngrams. In case
-// parentJobID is not empty, the new job will start after the parent
-// finishes.
-func (nfg *NgramFreqGenerator) GenerateAfter(parentJobID string) (NgramJobInfo, error) {
+// parentJobIDs are not empty, the new job will start after all
+// the parents finish.
+func (nfg *NgramFreqGenerator) GenerateAfter(parentJobIDs []string) (NgramJobInfo, error) {
 	jobID, err := uuid.NewUUID()
 	if err != nil {
 		return NgramJobInfo{}, err
@@ -715,12 +755,40 @@ func (nfg *NgramFreqGenerator) GenerateAfter(parentJobID string) (NgramJobInfo,
 		Start:    jobs.CurrentDatetime(),
 		Update:   jobs.CurrentDatetime(),
 		Finished: false,
-		Args:     NgramJobInfoArgs{},
-	}
-	fn := func(updateJobChan chan<- jobs.GeneralJobInfo) {
+		Args: NgramJobInfoArgs{
+			GroupedName:         nfg.groupedName,
+			UsePartitionedTable: nfg.useTablePartitioning,
+			AppendExisting:      nfg.appendExisting,
+			NgramSize:           nfg.ngramSize,
+			NgramConf:           nfg.ngramConf,
+			PosTagset:           nfg.posTagset,
+			ColMapping:          nfg.qsaAttrs,
+			MinFreq:             nfg.minFreq,
+		},
+	}
+	nfg.jobActions.EnqueueJobAfterAll(nfg.JobFunc(jobStatus), &jobStatus, parentJobIDs)
+	return jobStatus, nil
+}
+
+// JobFunc creates a function generating ngrams for the provided job.
+// The function is also used to restart detached jobs.
+func (nfg *NgramFreqGenerator) JobFunc(jobStatus NgramJobInfo) *jobs.JobFunc {
+	var numRuns int
+	fn := func(jctx context.Context, updateJobChan chan<- jobs.GeneralJobInfo) {
+		if numRuns > 0 {
+			// a retried job - the previous run closed the import-tuned connection
+			tunedDB, err := mysql.OpenImportTunedDB(nfg.db.Conf())
+			if err != nil {
+				updateJobChan <- jobStatus.WithError(
+					fmt.Errorf("failed to reopen import-tuned connection: %w", err))
+				close(updateJobChan)
+				return
+			}
+			nfg.db = tunedDB
+		}
+		numRuns++
 		statusChan := make(chan genNgramsStatus)
-		ctx := context.Background()
-		ctx, cancel := context.WithCancel(ctx)
+		ctx, cancel := context.WithCancel(jctx)
 		go func(runStatus NgramJobInfo) {
 			defer close(updateJobChan)
 			for statUpd := range statusChan {
@@ -774,18 +842,21 @@ func (nfg *NgramFreqGenerator) GenerateAfter(parentJobID string) (NgramJobInfo,
 			updateJobChan <- runStatus
 		}(jobStatus)
 		nfg.generateSync(ctx, statusChan)
+		if jctx.Err() != nil {
+			nfg.cleanUpCancelled()
+		}
 		close(statusChan)
 		if err := nfg.db.Close(); err != nil {
 			log.Error().Err(err).Msg("failed to close import-tuned connection")
 		}
 	}
-	if parentJobID != "" {
-		nfg.jobActions.EqueueJobAfter(&fn, &jobStatus, parentJobID)
+	return &fn
+}
 
-	} else {
-		nfg.jobActions.EnqueueJob(&fn, &jobStatus)
-	}
-	return jobStatus, nil
+// isIgnored tells whether an n-gram record should be left out
+// of the generated data (stop n-grams and n-grams below minFreq)
+func (nfg *NgramFreqGenerator) isIgnored(rec *ngRecord) bool {
+	return isStopNgram(rec.lemma) || rec.abs < nfg.minFreq
 }
 
 // NewNgramFreqGenerator
@@ -801,6 +872,8 @@ func NewNgramFreqGenerator(
 	usePartitionedTable bool,
 	appendExisting bool,
 	ngramSize int,
+	ngramConf vteCnf.NgramConf,
+	posTagset corp.SupportedTagset,
 	posFn *modders.StringTransformerChain,
 	qsaAttrs corpus.QSAttributes,
 	minFreq int,
@@ -813,8 +886,11 @@ func NewNgramFreqGenerator(
 		customDBDataDir:      customDBDataDir,
 		useTablePartitioning: usePartitionedTable,
 		ngramSize:            ngramSize,
+		ngramConf:            ngramConf,
+		posTagset:            posTagset,
 		posFn:                posFn,
 		qsaAttrs:             qsaAttrs,
 		appendExisting:       appendExisting,
+		minFreq:              minFreq,
 	}
 }
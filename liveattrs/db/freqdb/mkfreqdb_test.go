@@ -17,8 +17,10 @@
 package freqdb
 
 import (
+	"frodo/corpus"
 	"testing"
 
+	vteCnf "github.com/czcorpus/vert-tagextract/v3/cnf"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -49,3 +51,14 @@ func TestDetermineSimFreqsScore(t *testing.T) {
 	assert.InDelta(t, 3000.0, items[8].simFreqsScore, 0.001)
 	assert.InDelta(t, 3000.0, items[9].simFreqsScore, 0.001)
 }
+
+func TestNgramFreqGeneratorAppliesMinFreq(t *testing.T) {
+	nfg := NewNgramFreqGenerator(
+		nil, nil, "syn", "syn", "", false, false, 1, vteCnf.NgramConf{}, "", nil, corpus.QSAttributes{}, 3)
+	assert.True(t, nfg.isIgnored(&ngRecord{lemma: "foo", abs: 2}))
+	assert.False(t, nfg.isIgnored(&ngRecord{lemma: "foo", abs: 3}))
+
+	nfg = NewNgramFreqGenerator(
+		nil, nil, "syn", "syn", "", false, false, 1, vteCnf.NgramConf{}, "", nil, corpus.QSAttributes{}, 0)
+	assert.False(t, nfg.isIgnored(&ngRecord{lemma: "foo", abs: 1}))
+}
@@ -0,0 +1,182 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import (
+	"frodo/jobs"
+	"slices"
+	"time"
+)
+
+const (
+	IndexJobType = "liveattrs-indexes"
+)
+
+// IndexJobInfoArgs contains all the arguments needed
+// to run the job again (e.g. after a server restart)
+type IndexJobInfoArgs struct {
+	MaxColumns int `json:"maxColumns"`
+	MinUsage   int `json:"minUsage"`
+}
+
+// IndexJobInfo collects information about a job updating
+// indexes of a liveattrs table (see UpdateIndexes)
+type IndexJobInfo struct {
+	ID          string             `json:"id"`
+	Type        string             `json:"type"`
+	CorpusID    string             `json:"corpusId"`
+	Start       jobs.JSONTime      `json:"start"`
+	Update      jobs.JSONTime      `json:"update"`
+	Finished    bool               `json:"finished"`
+	Cancelled   bool               `json:"cancelled"`
+	Error       error              `json:"error,omitempty"`
+	NumRestarts int                `json:"numRestarts"`
+	Attempts    []jobs.JobAttempt  `json:"attempts,omitempty"`
+	Args        IndexJobInfoArgs   `json:"args"`
+	Result      *IndexUpdateResult `json:"result"`
+}
+
+func (j IndexJobInfo) GetID() string {
+	return j.ID
+}
+
+func (j IndexJobInfo) GetType() string {
+	return j.Type
+}
+
+func (j IndexJobInfo) GetStartDT() jobs.JSONTime {
+	return j.Start
+}
+
+func (j IndexJobInfo) GetNumRestarts() int {
+	return j.NumRestarts
+}
+
+func (j IndexJobInfo) GetCorpus() string {
+	return j.CorpusID
+}
+
+func (j IndexJobInfo) GetDatasetID() string {
+	return j.CorpusID
+}
+
+func (j IndexJobInfo) AsFinished() jobs.GeneralJobInfo {
+	j.Update = jobs.CurrentDatetime()
+	j.Finished = true
+	return j
+}
+
+func (j IndexJobInfo) IsFinished() bool {
+	return j.Finished
+}
+
+func (j IndexJobInfo) IsCancelled() bool {
+	return j.Cancelled
+}
+
+func (j IndexJobInfo) AsCancelled() jobs.GeneralJobInfo {
+	j.Update = jobs.CurrentDatetime()
+	j.Finished = true
+	j.Cancelled = true
+	j.Error = nil
+	return j
+}
+
+func (j IndexJobInfo) GetAttempts() []jobs.JobAttempt {
+	return j.Attempts
+}
+
+func (j IndexJobInfo) AsRetry() jobs.GeneralJobInfo {
+	j.Attempts = append(slices.Clone(j.Attempts), jobs.NewJobAttempt(j))
+	j.NumRestarts++
+	j.Start = jobs.CurrentDatetime()
+	j.Update = j.Start
+	j.Finished = false
+	j.Error = nil
+	return j
+}
+
+func (j IndexJobInfo) WithRetryState(prev jobs.GeneralJobInfo) jobs.GeneralJobInfo {
+	j.Start = prev.GetStartDT()
+	j.NumRestarts = prev.GetNumRestarts()
+	j.Attempts = prev.GetAttempts()
+	return j
+}
+
+func (j IndexJobInfo) FullInfo() any {
+	return struct {
+		ID          string             `json:"id"`
+		Type        string             `json:"type"`
+		CorpusID    string             `json:"corpusId"`
+		Start       jobs.JSONTime      `json:"start"`
+		Update      jobs.JSONTime      `json:"update"`
+		Finished    bool               `json:"finished"`
+		Cancelled   bool               `json:"cancelled"`
+		Error       string             `json:"error,omitempty"`
+		OK          bool               `json:"ok"`
+		NumRestarts int                `json:"numRestarts"`
+		Attempts    []jobs.JobAttempt  `json:"attempts,omitempty"`
+		Args        IndexJobInfoArgs   `json:"args"`
+		Result      *IndexUpdateResult `json:"result"`
+	}{
+		ID:          j.ID,
+		Type:        j.Type,
+		CorpusID:    j.CorpusID,
+		Start:       j.Start,
+		Update:      j.Update,
+		Finished:    j.Finished,
+		Cancelled:   j.Cancelled,
+		Error:       jobs.ErrorToString(j.Error),
+		OK:          j.Error == nil && !j.Cancelled,
+		NumRestarts: j.NumRestarts,
+		Attempts:    j.Attempts,
+		Args:        j.Args,
+		Result:      j.Result,
+	}
+}
+
+func (j IndexJobInfo) CompactVersion() jobs.JobInfoCompact {
+	return jobs.JobInfoCompact{
+		ID:        j.ID,
+		Type:      j.Type,
+		CorpusID:  j.CorpusID,
+		Start:     j.Start,
+		Update:    j.Update,
+		Finished:  j.Finished,
+		Cancelled: j.Cancelled,
+		OK:        j.Error == nil && !j.Cancelled,
+	}
+}
+
+func (j IndexJobInfo) GetError() error {
+	return j.Error
+}
+
+func (j IndexJobInfo) WithError(err error) jobs.GeneralJobInfo {
+	return &IndexJobInfo{
+		ID:          j.ID,
+		Type:        j.Type,
+		CorpusID:    j.CorpusID,
+		Start:       j.Start,
+		Update:      jobs.JSONTime(time.Now()),
+		Finished:    true,
+		Error:       err,
+		NumRestarts: j.NumRestarts,
+		Attempts:    j.Attempts,
+		Args:        j.Args,
+		Result:      j.Result,
+	}
+}
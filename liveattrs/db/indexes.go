@@ -0,0 +1,267 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"frodo/corpus"
+	"slices"
+	"strings"
+)
+
+const (
+	autoIndexSuffix = "_autoindex"
+)
+
+// IndexInfo describes an existing index of a liveattrs table
+type IndexInfo struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+
+	// Auto is true for indexes managed by Frodo (see UpdateIndexes)
+	Auto bool `json:"auto"`
+}
+
+// UsageRank describes how often a column is queried
+type UsageRank struct {
+	Column  string `json:"column"`
+	NumUsed int    `json:"numUsed"`
+	Rank    int    `json:"rank"`
+}
+
+// IndexAdvice contains current state of indexes of a liveattrs table
+// along with recommended changes
+type IndexAdvice struct {
+	Table   string      `json:"table"`
+	Indexes []IndexInfo `json:"indexes"`
+	Usage   []UsageRank `json:"usage"`
+
+	// Create contains columns which should be indexed
+	Create []string `json:"create"`
+
+	// Drop contains automatic indexes which are no longer needed
+	Drop []string `json:"drop"`
+}
+
+// HasChanges tells whether the advice recommends any changes
+func (advice *IndexAdvice) HasChanges() bool {
+	return len(advice.Create) > 0 || len(advice.Drop) > 0
+}
+
+// IndexUpdateResult describes applied index changes
+type IndexUpdateResult struct {
+	CreatedIndexes []string `json:"createdIndexes"`
+	RemovedIndexes []string `json:"removedIndexes"`
+}
+
+func autoIndexName(column string) string {
+	return column + autoIndexSuffix
+}
+
+func liveattrsTable(corpusInfo *corpus.DBInfo) string {
+	return fmt.Sprintf("%s_liveattrs_entry", corpusInfo.GroupedName())
+}
+
+// LoadIndexes returns indexes of the liveattrs table of a corpus
+// (the primary key excluded)
+func LoadIndexes(ctx context.Context, laDB *sql.DB, corpusInfo *corpus.DBInfo) ([]IndexInfo, error) {
+	rows, err := laDB.QueryContext(
+		ctx,
+		"SELECT INDEX_NAME, COLUMN_NAME FROM information_schema.statistics "+
+			"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND INDEX_NAME <> 'PRIMARY' "+
+			"ORDER BY INDEX_NAME, SEQ_IN_INDEX",
+		liveattrsTable(corpusInfo),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load indexes: %w", err)
+	}
+	defer rows.Close()
+	ans := make([]IndexInfo, 0, 10)
+	for rows.Next() {
+		var indexName, columnName string
+		if err := rows.Scan(&indexName, &columnName); err != nil {
+			return nil, fmt.Errorf("failed to load indexes: %w", err)
+		}
+		if len(ans) == 0 || ans[len(ans)-1].Name != indexName {
+			ans = append(ans, IndexInfo{
+				Name: indexName,
+				Auto: strings.HasSuffix(indexName, autoIndexSuffix),
+			})
+		}
+		ans[len(ans)-1].Columns = append(ans[len(ans)-1].Columns, columnName)
+	}
+	return ans, rows.Err()
+}
+
+func loadTableColumns(ctx context.Context, laDB *sql.DB, tableName string) ([]string, error) {
+	rows, err := laDB.QueryContext(
+		ctx,
+		"SELECT COLUMN_NAME FROM information_schema.columns "+
+			"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?",
+		tableName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ans := make([]string, 0, 30)
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, err
+		}
+		ans = append(ans, column)
+	}
+	return ans, rows.Err()
+}
+
+// adviseIndexes recommends up to maxColumns of the most used columns
+// (used at least minUsage times) to be indexed. A column is considered
+// already indexed in case there is an index starting with the column.
+// Automatic indexes of columns not recommended are to be dropped.
+func adviseIndexes(
+	indexes []IndexInfo,
+	usage map[string]int,
+	tableColumns []string,
+	maxColumns int,
+	minUsage int,
+) ([]UsageRank, []string, []string) {
+	ranks := make([]UsageRank, 0, len(usage))
+	for column, numUsed := range usage {
+		if slices.Contains(tableColumns, column) {
+			ranks = append(ranks, UsageRank{Column: column, NumUsed: numUsed})
+		}
+	}
+	slices.SortFunc(ranks, func(a, b UsageRank) int {
+		if a.NumUsed != b.NumUsed {
+			return b.NumUsed - a.NumUsed
+		}
+		return strings.Compare(a.Column, b.Column)
+	})
+	recommended := make(map[string]bool)
+	for i := range ranks {
+		ranks[i].Rank = i + 1
+		if len(recommended) < maxColumns && ranks[i].NumUsed >= minUsage {
+			recommended[ranks[i].Column] = true
+		}
+	}
+	create := make([]string, 0, len(recommended))
+	for _, rank := range ranks {
+		if !recommended[rank.Column] {
+			continue
+		}
+		covered := slices.ContainsFunc(indexes, func(idx IndexInfo) bool {
+			return len(idx.Columns) > 0 && idx.Columns[0] == rank.Column
+		})
+		if !covered {
+			create = append(create, rank.Column)
+		}
+	}
+	drop := make([]string, 0, 5)
+	for _, idx := range indexes {
+		if idx.Auto && !recommended[strings.TrimSuffix(idx.Name, autoIndexSuffix)] {
+			drop = append(drop, idx.Name)
+		}
+	}
+	return ranks, create, drop
+}
+
+// AdviseIndexes compares existing indexes of the liveattrs table of a corpus
+// with usage statistics of the table's columns and recommends changes.
+func AdviseIndexes(
+	ctx context.Context,
+	laDB *sql.DB,
+	corpusInfo *corpus.DBInfo,
+	maxColumns int,
+	minUsage int,
+) (*IndexAdvice, error) {
+	table := liveattrsTable(corpusInfo)
+	indexes, err := LoadIndexes(ctx, laDB, corpusInfo)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := LoadUsage(laDB, corpusInfo.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load usage of %s: %w", corpusInfo.Name, err)
+	}
+	columns, err := loadTableColumns(ctx, laDB, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load columns of %s: %w", table, err)
+	}
+	ans := &IndexAdvice{
+		Table:   table,
+		Indexes: indexes,
+	}
+	ans.Usage, ans.Create, ans.Drop = adviseIndexes(indexes, usage, columns, maxColumns, minUsage)
+	return ans, nil
+}
+
+// ApplyIndexAdvice creates and drops indexes as recommended by the advice.
+// For tables shared by more corpora, the created indexes also contain
+// the corpus_id column.
+func ApplyIndexAdvice(
+	ctx context.Context,
+	laDB *sql.DB,
+	corpusInfo *corpus.DBInfo,
+	advice *IndexAdvice,
+) (IndexUpdateResult, error) {
+	ans := IndexUpdateResult{
+		CreatedIndexes: make([]string, 0, len(advice.Create)),
+		RemovedIndexes: make([]string, 0, len(advice.Drop)),
+	}
+	var sqlTemplate string
+	if corpusInfo.GroupedName() == corpusInfo.Name {
+		sqlTemplate = "CREATE INDEX IF NOT EXISTS `%s` ON `%s` (`%s`)"
+
+	} else {
+		sqlTemplate = "CREATE INDEX IF NOT EXISTS `%s` ON `%s` (`%s`, `corpus_id`)"
+	}
+	// note: DDL statements cause an implicit commit in MySQL/MariaDB
+	// so there is no point in using a transaction here
+	for _, column := range advice.Create {
+		_, err := laDB.ExecContext(ctx, fmt.Sprintf(sqlTemplate, autoIndexName(column), advice.Table, column))
+		if err != nil {
+			return ans, fmt.Errorf("failed to create index for %s: %w", column, err)
+		}
+		ans.CreatedIndexes = append(ans.CreatedIndexes, autoIndexName(column))
+	}
+	for _, index := range advice.Drop {
+		_, err := laDB.ExecContext(ctx, fmt.Sprintf("DROP INDEX `%s` ON `%s`", index, advice.Table))
+		if err != nil {
+			return ans, fmt.Errorf("failed to drop index %s: %w", index, err)
+		}
+		ans.RemovedIndexes = append(ans.RemovedIndexes, index)
+	}
+	return ans, nil
+}
+
+// UpdateIndexes makes sure the most used columns of the liveattrs table
+// of a corpus are indexed and that there are no unused automatic indexes.
+func UpdateIndexes(
+	ctx context.Context,
+	laDB *sql.DB,
+	corpusInfo *corpus.DBInfo,
+	maxColumns int,
+	minUsage int,
+) (IndexUpdateResult, error) {
+	advice, err := AdviseIndexes(ctx, laDB, corpusInfo, maxColumns, minUsage)
+	if err != nil {
+		return IndexUpdateResult{}, err
+	}
+	return ApplyIndexAdvice(ctx, laDB, corpusInfo, advice)
+}
@@ -0,0 +1,51 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdviseIndexes(t *testing.T) {
+	indexes := []IndexInfo{
+		{Name: "doc_title_autoindex", Columns: []string{"doc_title", "corpus_id"}, Auto: true},
+		{Name: "doc_pubyear_autoindex", Columns: []string{"doc_pubyear"}, Auto: true},
+		{Name: "manual_genre", Columns: []string{"doc_genre"}},
+	}
+	usage := map[string]int{
+		"doc_genre":   20,
+		"doc_title":   10,
+		"doc_author":  10,
+		"doc_pubyear": 1,
+		"doc_removed": 50,
+	}
+	columns := []string{"id", "corpus_id", "doc_genre", "doc_title", "doc_author", "doc_pubyear"}
+	ranks, create, drop := adviseIndexes(indexes, usage, columns, 3, 2)
+	assert.Equal(
+		t,
+		[]UsageRank{
+			{Column: "doc_genre", NumUsed: 20, Rank: 1},
+			{Column: "doc_author", NumUsed: 10, Rank: 2},
+			{Column: "doc_title", NumUsed: 10, Rank: 3},
+			{Column: "doc_pubyear", NumUsed: 1, Rank: 4},
+		},
+		ranks,
+	)
+	assert.Equal(t, []string{"doc_author"}, create)
+	assert.Equal(t, []string{"doc_pubyear_autoindex"}, drop)
+}
@@ -52,7 +52,9 @@ func AddProcTimeEntry(
 	return err
 }
 
-func EstimateProcTimeSecs(db *sql.DB, procType string, dataSize int) (int, error) {
+// EstimateProcSpeed returns an average processing speed (items per second)
+// of the recorded run with data size closest to the provided one.
+func EstimateProcSpeed(db *sql.DB, procType string, dataSize int) (float64, error) {
 	row := db.QueryRow(
 		"SELECT SUM(t2.num_items) AS total_items, SUM(t2.proc_time) AS total_time "+
 			"FROM "+
@@ -73,8 +75,16 @@ func EstimateProcTimeSecs(db *sql.DB, procType string, dataSize int) (int, error
 	if err != nil {
 		return -1, err
 	}
-	if !totalItems.Valid || !totalTime.Valid {
+	if !totalItems.Valid || !totalTime.Valid || totalTime.Float64 <= 0 {
 		return -1, ErrorEstimationNotAvail
 	}
-	return int(math.RoundToEven(float64(totalItems.Int64) / totalTime.Float64)), nil
+	return float64(totalItems.Int64) / totalTime.Float64, nil
+}
+
+func EstimateProcTimeSecs(db *sql.DB, procType string, dataSize int) (int, error) {
+	speed, err := EstimateProcSpeed(db, procType, dataSize)
+	if err != nil {
+		return -1, err
+	}
+	return int(math.RoundToEven(speed)), nil
 }
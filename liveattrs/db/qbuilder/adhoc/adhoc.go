@@ -19,6 +19,8 @@ package adhoc
 import (
 	"fmt"
 	"frodo/corpus"
+	"frodo/liveattrs/db/qbuilder"
+	"frodo/liveattrs/laconf"
 	"frodo/liveattrs/request/query"
 	"strings"
 )
@@ -30,11 +32,18 @@ type SubcSize struct {
 	AttrMap             query.Attrs
 	AlignedCorpora      []string
 	EmptyValPlaceholder string
+
+	// AttrTypes specifies how range values are compared
+	AttrTypes laconf.AttrTypes
+
+	// Filter is an optional boolean expression
+	// applied along with AttrMap
+	Filter *query.Filter
 }
 
 // Query generates the result
 // Please note that this is largely similar to laquery.AttrArgs.ExportSQL()
-func (ssize *SubcSize) Query() (ansSQL string, whereValues []any) {
+func (ssize *SubcSize) Query() (ansSQL string, whereValues []any, err error) {
 	joinSQL := make([]string, 0, 10)
 	whereSQL := []string{
 		"t1.corpus_id = ?",
@@ -61,10 +70,25 @@ func (ssize *SubcSize) Query() (ansSQL string, whereValues []any) {
 		data:                ssize.AttrMap,
 		emptyValPlaceholder: ssize.EmptyValPlaceholder,
 		bibLabel:            ssize.CorpusInfo.BibLabelAttr,
+		attrTypes:           ssize.AttrTypes,
+	}
+	where2, args2, err := aargs.ExportSQL("t1", ssize.CorpusInfo.Name)
+	if err != nil {
+		return
 	}
-	where2, args2 := aargs.ExportSQL("t1", ssize.CorpusInfo.Name)
 	whereSQL = append(whereSQL, where2)
 	whereValues = append(whereValues, args2...)
+	if ssize.Filter != nil {
+		filterSQL, filterValues, ferr := qbuilder.FilterSQL(ssize.Filter, "t1", ssize.EmptyValPlaceholder)
+		if ferr != nil {
+			err = ferr
+			return
+		}
+		whereSQL = append(whereSQL, filterSQL)
+		for _, v := range filterValues {
+			whereValues = append(whereValues, v)
+		}
+	}
 	ansSQL = fmt.Sprintf(
 		"SELECT SUM(t1.poscount) FROM `%s_liveattrs_entry` AS t1 %s WHERE %s",
 		ssize.CorpusInfo.GroupedName(),
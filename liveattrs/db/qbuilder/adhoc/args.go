@@ -19,6 +19,7 @@ package adhoc
 import (
 	"fmt"
 	"frodo/liveattrs/db/qbuilder"
+	"frodo/liveattrs/laconf"
 	"frodo/liveattrs/request/query"
 	"frodo/liveattrs/utils"
 	"strings"
@@ -32,6 +33,7 @@ import (
 type PredicateArgs struct {
 	data                query.Attrs
 	emptyValPlaceholder string
+	attrTypes           laconf.AttrTypes
 	bibLabel            string
 }
 
@@ -42,7 +44,7 @@ func (args *PredicateArgs) importValue(value string) string {
 	return value
 }
 
-func (args *PredicateArgs) ExportSQL(itemPrefix, corpusID string) (string, []any) {
+func (args *PredicateArgs) ExportSQL(itemPrefix, corpusID string) (string, []any, error) {
 	where := make([]string, 0, 20)
 	sqlValues := make([]any, 0, 20)
 	for dkey, values := range args.data {
@@ -107,6 +109,20 @@ func (args *PredicateArgs) ExportSQL(itemPrefix, corpusID string) (string, []any
 				sqlValues = append(sqlValues, args.importValue(regexpVal))
 
 				// TODO add support for this
+			} else if from, to, ok := args.data.GetRangeAttrVal(dkey); ok {
+				rangeSQL, rangeVals, err := qbuilder.RangeSQL(
+					itemPrefix+"."+key,
+					args.attrTypes.TypeOf(strings.TrimPrefix(dkey, "!")),
+					from, to, exclude,
+				)
+				if err != nil {
+					return "", []any{}, err
+				}
+				cnfItem = append(cnfItem, rangeSQL)
+				for _, v := range rangeVals {
+					sqlValues = append(sqlValues, v)
+				}
+
 			} else {
 				// TODO handle in a better way
 				log.Error().Msgf(
@@ -132,5 +148,5 @@ func (args *PredicateArgs) ExportSQL(itemPrefix, corpusID string) (string, []any
 	}
 	where = append(where, fmt.Sprintf("%s.corpus_id = ?", itemPrefix))
 	sqlValues = append(sqlValues, corpusID)
-	return strings.Join(where, " AND "), sqlValues
+	return strings.Join(where, " AND "), sqlValues, nil
 }
@@ -0,0 +1,118 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package qbuilder
+
+import (
+	"fmt"
+	"frodo/liveattrs/laconf"
+	"frodo/liveattrs/request/query"
+	"frodo/liveattrs/utils"
+	"strings"
+)
+
+// FilterSQL translates a filter expression into a parameterized
+// SQL condition. Columns are referenced with itemPrefix (e.g. "t1").
+// Values equal to emptyValPlaceholder are matched against empty strings.
+func FilterSQL(
+	filter *query.Filter,
+	itemPrefix string,
+	emptyValPlaceholder string,
+) (string, []string, error) {
+	if err := filter.Validate(); err != nil {
+		return "", []string{}, err
+	}
+	fsql := filterSQL{
+		itemPrefix:          itemPrefix,
+		emptyValPlaceholder: emptyValPlaceholder,
+		values:              make([]string, 0, 20),
+	}
+	sql := fsql.export(filter)
+	return sql, fsql.values, nil
+}
+
+type filterSQL struct {
+	itemPrefix          string
+	emptyValPlaceholder string
+	values              []string
+}
+
+func (fs *filterSQL) importValue(value string) string {
+	if value == fs.emptyValPlaceholder {
+		return ""
+	}
+	return value
+}
+
+func (fs *filterSQL) column(attr string) string {
+	return fmt.Sprintf("%s.%s", fs.itemPrefix, utils.ImportKey(attr))
+}
+
+func (fs *filterSQL) rangeColumn(f *query.Filter) string {
+	if f.RangeType() == query.RangeTypeDate {
+		return TypedColumn(fs.column(f.Attr), laconf.AttrTypeDate)
+	}
+	return TypedColumn(fs.column(f.Attr), laconf.AttrTypeFloat)
+}
+
+func (fs *filterSQL) export(f *query.Filter) string {
+	switch f.Op {
+	case query.FilterOpAnd, query.FilterOpOr:
+		items := make([]string, len(f.Args))
+		for i, arg := range f.Args {
+			items[i] = fs.export(arg)
+		}
+		return fmt.Sprintf("(%s)", strings.Join(items, " "+strings.ToUpper(f.Op)+" "))
+	case query.FilterOpNot:
+		return fmt.Sprintf("NOT %s", fs.export(f.Args[0]))
+	case query.FilterOpEq:
+		fs.values = append(fs.values, fs.importValue(f.Value))
+		return fmt.Sprintf("(%s = ?)", fs.column(f.Attr))
+	case query.FilterOpIn:
+		for _, v := range f.Values {
+			fs.values = append(fs.values, fs.importValue(v))
+		}
+		return fmt.Sprintf(
+			"(%s IN (%s))",
+			fs.column(f.Attr), strings.TrimSuffix(strings.Repeat("?, ", len(f.Values)), ", "),
+		)
+	case query.FilterOpRegexp:
+		fs.values = append(fs.values, f.Value)
+		return fmt.Sprintf("(%s REGEXP ?)", fs.column(f.Attr))
+	case query.FilterOpPrefix:
+		fs.values = append(fs.values, EscapeLike(f.Value)+"%")
+		return fmt.Sprintf("(%s LIKE ?)", fs.column(f.Attr))
+	case query.FilterOpRange:
+		col := fs.rangeColumn(f)
+		items := make([]string, 0, 2)
+		for _, bound := range []struct {
+			op    string
+			value *query.Bound
+		}{{">", f.Gt}, {">=", f.Gte}, {"<", f.Lt}, {"<=", f.Lte}} {
+			if bound.value != nil {
+				items = append(items, fmt.Sprintf("%s %s ?", col, bound.op))
+				fs.values = append(fs.values, string(*bound.value))
+			}
+		}
+		return fmt.Sprintf("(%s)", strings.Join(items, " AND "))
+	}
+	// Validate() makes sure this cannot happen
+	panic(fmt.Sprintf("unknown filter operator %s", f.Op))
+}
+
+// EscapeLike escapes wildcard characters of the LIKE operator
+func EscapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
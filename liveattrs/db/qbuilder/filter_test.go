@@ -0,0 +1,89 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package qbuilder
+
+import (
+	"encoding/json"
+	"frodo/liveattrs/request/query"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func decodeFilter(t *testing.T, src string) *query.Filter {
+	var ans query.Filter
+	assert.NoError(t, json.Unmarshal([]byte(src), &ans))
+	return &ans
+}
+
+func TestFilterSQLNestedExpression(t *testing.T) {
+	filter := decodeFilter(t, `{"op": "or", "args": [
+		{"op": "and", "args": [
+			{"op": "eq", "attr": "doc.genre", "value": "fiction"},
+			{"op": "range", "attr": "doc.year", "gt": 2000}]},
+		{"op": "not", "args": [{"op": "in", "attr": "doc.author", "values": ["X", "?"]}]}]}`)
+	sql, values, err := FilterSQL(filter, "t1", "?")
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		"(((t1.doc_genre = ?) AND (CAST(t1.doc_year AS DECIMAL(65,10)) > ?)) OR NOT (t1.doc_author IN (?, ?)))",
+		sql,
+	)
+	assert.Equal(t, []string{"fiction", "2000", "X", ""}, values)
+}
+
+func TestFilterSQLLeafPredicates(t *testing.T) {
+	sql, values, err := FilterSQL(
+		decodeFilter(t, `{"op": "prefix", "attr": "doc.title", "value": "100%_"}`), "t1", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "(t1.doc_title LIKE ?)", sql)
+	assert.Equal(t, []string{`100\%\_%`}, values)
+
+	sql, values, err = FilterSQL(
+		decodeFilter(t, `{"op": "regexp", "attr": "doc.title", "value": "^A.*"}`), "t2", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "(t2.doc_title REGEXP ?)", sql)
+	assert.Equal(t, []string{"^A.*"}, values)
+
+	sql, values, err = FilterSQL(
+		decodeFilter(t, `{"op": "range", "attr": "doc.published", "type": "date", "gte": "2001-01-01", "lt": "2002-01-01"}`),
+		"t1", "",
+	)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		"(CAST(t1.doc_published AS DATE) >= ? AND CAST(t1.doc_published AS DATE) < ?)",
+		sql,
+	)
+	assert.Equal(t, []string{"2001-01-01", "2002-01-01"}, values)
+}
+
+func TestFilterSQLRejectsInvalidExpressions(t *testing.T) {
+	for _, src := range []string{
+		`{"op": "xor", "args": []}`,
+		`{"op": "and", "args": []}`,
+		`{"op": "not", "args": [{"op": "eq", "attr": "doc.a"}, {"op": "eq", "attr": "doc.b"}]}`,
+		`{"op": "eq", "attr": "doc.genre = 1 OR 1", "value": "x"}`,
+		`{"op": "in", "attr": "doc.genre"}`,
+		`{"op": "range", "attr": "doc.year"}`,
+		`{"op": "range", "attr": "doc.year", "gt": "1990; DROP TABLE x"}`,
+		`{"op": "range", "attr": "doc.year", "type": "date", "gt": "yesterday"}`,
+		`{"op": "range", "attr": "doc.year", "gt": 1, "gte": 2}`,
+	} {
+		_, _, err := FilterSQL(decodeFilter(t, src), "t1", "")
+		assert.ErrorIs(t, err, query.ErrorInvalidFilter, src)
+	}
+}
@@ -19,6 +19,7 @@ package laquery
 import (
 	"fmt"
 	"frodo/liveattrs/db/qbuilder"
+	"frodo/liveattrs/laconf"
 	"frodo/liveattrs/request/query"
 	"frodo/liveattrs/utils"
 	"strings"
@@ -32,6 +33,7 @@ type PredicateArgs struct {
 	bibLabel            string
 	autocompleteAttr    string
 	emptyValPlaceholder string
+	attrTypes           laconf.AttrTypes
 }
 
 func (args *PredicateArgs) Len() int {
@@ -45,7 +47,7 @@ func (args *PredicateArgs) importValue(value string) string {
 	return value
 }
 
-func (args *PredicateArgs) ExportSQL(itemPrefix, corpusID string) (string, []string) {
+func (args *PredicateArgs) ExportSQL(itemPrefix, corpusID string) (string, []string, error) {
 	where := make([]string, 0, 20)
 	sqlValues := make([]string, 0, 20)
 	for dkey, values := range args.data {
@@ -113,6 +115,18 @@ func (args *PredicateArgs) ExportSQL(itemPrefix, corpusID string) (string, []str
 				sqlValues = append(sqlValues, args.importValue(regexpVal))
 
 				// TODO add support for this
+			} else if from, to, ok := args.data.GetRangeAttrVal(dkey); ok {
+				rangeSQL, rangeVals, err := qbuilder.RangeSQL(
+					itemPrefix+"."+key,
+					args.attrTypes.TypeOf(strings.TrimPrefix(dkey, "!")),
+					from, to, exclude,
+				)
+				if err != nil {
+					return "", []string{}, err
+				}
+				cnfItem = append(cnfItem, rangeSQL)
+				sqlValues = append(sqlValues, rangeVals...)
+
 			} else {
 				// TODO handle in a better way
 				log.Error().Msgf(
@@ -139,7 +153,7 @@ func (args *PredicateArgs) ExportSQL(itemPrefix, corpusID string) (string, []str
 	}
 	where = append(where, fmt.Sprintf("%s.corpus_id = ?", itemPrefix))
 	sqlValues = append(sqlValues, corpusID)
-	return strings.Join(where, " AND "), sqlValues
+	return strings.Join(where, " AND "), sqlValues, nil
 }
 
 type QueryComponents struct {
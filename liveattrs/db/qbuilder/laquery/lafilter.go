@@ -20,6 +20,8 @@ import (
 	"database/sql"
 	"fmt"
 	"frodo/corpus"
+	"frodo/liveattrs/db/qbuilder"
+	"frodo/liveattrs/laconf"
 	"frodo/liveattrs/request/query"
 	"frodo/liveattrs/utils"
 	"strings"
@@ -34,6 +36,13 @@ type LAFilter struct {
 	AlignedCorpora      []string
 	AutocompleteAttr    string
 	EmptyValPlaceholder string
+
+	// AttrTypes specifies how range values are compared
+	AttrTypes laconf.AttrTypes
+
+	// Filter is an optional boolean expression
+	// applied along with AttrMap
+	Filter *query.Filter
 }
 
 func (b *LAFilter) attrToSQL(values []string, prefix string) []string {
@@ -44,7 +53,7 @@ func (b *LAFilter) attrToSQL(values []string, prefix string) []string {
 	return ans
 }
 
-func (b *LAFilter) CreateSQL() QueryComponents {
+func (b *LAFilter) CreateSQL() (QueryComponents, error) {
 	bibID := utils.ImportKey(b.CorpusInfo.BibIDAttr)
 	bibLabel := utils.ImportKey(b.CorpusInfo.BibLabelAttr)
 	attrItems := PredicateArgs{
@@ -53,12 +62,24 @@ func (b *LAFilter) CreateSQL() QueryComponents {
 		bibLabel:            bibLabel,
 		autocompleteAttr:    b.AutocompleteAttr,
 		emptyValPlaceholder: b.EmptyValPlaceholder,
+		attrTypes:           b.AttrTypes,
+	}
+	whereSQL0, whereValues0, err := attrItems.ExportSQL("t1", b.CorpusInfo.Name) // TODO py uses 'info.id' here
+	if err != nil {
+		return QueryComponents{}, err
 	}
-	whereSQL0, whereValues0 := attrItems.ExportSQL("t1", b.CorpusInfo.Name) // TODO py uses 'info.id' here
 	whereSQL := make([]string, 0, 20)
 	whereSQL = append(whereSQL, whereSQL0)
 	whereValues := make([]string, 0, 20+len(whereValues0))
 	whereValues = append(whereValues, whereValues0...)
+	if b.Filter != nil {
+		filterSQL, filterValues, err := qbuilder.FilterSQL(b.Filter, "t1", b.EmptyValPlaceholder)
+		if err != nil {
+			return QueryComponents{}, err
+		}
+		whereSQL = append(whereSQL, " AND "+filterSQL)
+		whereValues = append(whereValues, filterValues...)
+	}
 	joinSQL := make([]string, 0, 20)
 	for i, item := range b.AlignedCorpora {
 		joinSQL = append(
@@ -99,7 +120,7 @@ func (b *LAFilter) CreateSQL() QueryComponents {
 		selectedAttrs: selectedAttrs.ToOrderedSlice(),
 		hiddenAttrs:   hiddenAttrs.ToOrderedSlice(),
 		whereValues:   whereValues,
-	}
+	}, nil
 }
 
 type ResultRow struct {
@@ -114,7 +135,10 @@ type DataIterator struct {
 }
 
 func (di *DataIterator) Iterate(fn func(row ResultRow) error) error {
-	qc := di.Builder.CreateSQL()
+	qc, err := di.Builder.CreateSQL()
+	if err != nil {
+		return err
+	}
 	args := make([]any, len(qc.whereValues))
 	for i, v := range qc.whereValues {
 		args[i] = v
@@ -0,0 +1,85 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package qbuilder
+
+import (
+	"fmt"
+	"frodo/liveattrs/laconf"
+	"frodo/liveattrs/request/query"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TypedColumn returns an SQL expression converting a column
+// to a type suitable for comparison of attrType values
+func TypedColumn(column, attrType string) string {
+	switch attrType {
+	case laconf.AttrTypeInt:
+		return fmt.Sprintf("CAST(%s AS SIGNED)", column)
+	case laconf.AttrTypeFloat:
+		return fmt.Sprintf("CAST(%s AS DECIMAL(65,10))", column)
+	case laconf.AttrTypeDate:
+		return fmt.Sprintf("CAST(%s AS DATE)", column)
+	default:
+		return column
+	}
+}
+
+func validateTypedValue(value, attrType string) error {
+	var err error
+	switch attrType {
+	case laconf.AttrTypeInt:
+		_, err = strconv.ParseInt(value, 10, 64)
+	case laconf.AttrTypeFloat:
+		_, err = strconv.ParseFloat(value, 64)
+	case laconf.AttrTypeDate:
+		_, err = time.Parse(query.RangeDateLayout, value)
+	}
+	if err != nil {
+		return fmt.Errorf("value %s is not of type %s", value, attrType)
+	}
+	return nil
+}
+
+// RangeSQL creates a parameterized SQL condition testing whether
+// a column value is within [from, to]. An empty bound means
+// "unbounded". The values are compared according to attrType.
+func RangeSQL(column, attrType, from, to string, exclude bool) (string, []string, error) {
+	col := TypedColumn(column, attrType)
+	items := make([]string, 0, 2)
+	values := make([]string, 0, 2)
+	for _, bound := range []struct {
+		op    string
+		value string
+	}{{">=", from}, {"<=", to}} {
+		if bound.value == "" {
+			continue
+		}
+		if err := validateTypedValue(bound.value, attrType); err != nil {
+			return "", []string{}, fmt.Errorf("invalid range of %s: %w", column, err)
+		}
+		items = append(items, fmt.Sprintf("%s %s ?", col, bound.op))
+		values = append(values, bound.value)
+	}
+	if len(items) == 0 {
+		return "", []string{}, fmt.Errorf("invalid range of %s: no bounds specified", column)
+	}
+	if exclude {
+		return fmt.Sprintf("NOT (%s)", strings.Join(items, " AND ")), values, nil
+	}
+	return fmt.Sprintf("(%s)", strings.Join(items, " AND ")), values, nil
+}
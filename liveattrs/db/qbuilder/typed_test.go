@@ -0,0 +1,48 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package qbuilder
+
+import (
+	"frodo/liveattrs/laconf"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRangeSQLTypedComparison(t *testing.T) {
+	sql, values, err := RangeSQL("t1.doc_year", laconf.AttrTypeInt, "1990", "2000", false)
+	assert.NoError(t, err)
+	assert.Equal(t, "(CAST(t1.doc_year AS SIGNED) >= ? AND CAST(t1.doc_year AS SIGNED) <= ?)", sql)
+	assert.Equal(t, []string{"1990", "2000"}, values)
+
+	sql, values, err = RangeSQL("t1.doc_pubdate", laconf.AttrTypeDate, "", "2001-12-31", true)
+	assert.NoError(t, err)
+	assert.Equal(t, "NOT (CAST(t1.doc_pubdate AS DATE) <= ?)", sql)
+	assert.Equal(t, []string{"2001-12-31"}, values)
+
+	sql, _, err = RangeSQL("t1.doc_title", laconf.AttrTypeString, "A", "", false)
+	assert.NoError(t, err)
+	assert.Equal(t, "(t1.doc_title >= ?)", sql)
+}
+
+func TestRangeSQLRejectsInvalidValues(t *testing.T) {
+	_, _, err := RangeSQL("t1.doc_year", laconf.AttrTypeInt, "1990.5", "", false)
+	assert.Error(t, err)
+	_, _, err = RangeSQL("t1.doc_score", laconf.AttrTypeFloat, "", "high", false)
+	assert.Error(t, err)
+	_, _, err = RangeSQL("t1.doc_year", laconf.AttrTypeInt, "", "", false)
+	assert.Error(t, err)
+}
@@ -0,0 +1,415 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"frodo/liveattrs"
+	"strings"
+	"time"
+
+	vteDB "github.com/czcorpus/vert-tagextract/v3/db"
+)
+
+const (
+	shadowSuffix      = "_shadow"
+	prevTableSuffix   = "_prev"
+	rollbackTmpSuffix = "_rb"
+)
+
+var (
+	ErrorInvalidShadowData = errors.New("rebuilt liveattrs data failed validation")
+	ErrorNoPrevVersion     = errors.New("no previous version of liveattrs data")
+
+	// swappedTables lists data types ([grouped name]_[data type])
+	// replaced by a rebuild
+	swappedTables = []string{"liveattrs_entry", "colcounts"}
+)
+
+// ShadowName returns a name of tables ([shadow name]_[data type])
+// a rebuild of liveattrs data is written into before the data
+// replace the current ones
+func ShadowName(groupedName string) string {
+	return groupedName + shadowSuffix
+}
+
+// existingTables returns names of the provided tables
+// which exist in the current database
+func existingTables(laDB *sql.DB, names ...string) (map[string]bool, error) {
+	args := make([]any, len(names))
+	for i, name := range names {
+		args[i] = name
+	}
+	rows, err := laDB.Query(
+		"SELECT TABLE_NAME FROM information_schema.TABLES "+
+			"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME IN ("+placeholders(len(names))+")",
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to test tables existence: %w", err)
+	}
+	defer rows.Close()
+	ans := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to test tables existence: %w", err)
+		}
+		ans[name] = true
+	}
+	return ans, rows.Err()
+}
+
+// swapCandidates returns all the tables involved in swapping
+// and rollback of the data identified by groupedName
+func swapCandidates(groupedName string) []string {
+	ans := make([]string, 0, len(swappedTables)*3)
+	for _, tbl := range swappedTables {
+		curr := fmt.Sprintf("%s_%s", groupedName, tbl)
+		ans = append(
+			ans,
+			curr,
+			curr+prevTableSuffix,
+			fmt.Sprintf("%s_%s", ShadowName(groupedName), tbl),
+		)
+	}
+	return ans
+}
+
+// swapClauses creates RENAME TABLE clauses replacing the current
+// tables with the shadow ones. The current tables are kept as
+// previous versions. Data types without a shadow table are left
+// untouched.
+func swapClauses(groupedName string, exists map[string]bool) []string {
+	ans := make([]string, 0, len(swappedTables)*2)
+	for _, tbl := range swappedTables {
+		shadow := fmt.Sprintf("%s_%s", ShadowName(groupedName), tbl)
+		if !exists[shadow] {
+			continue
+		}
+		curr := fmt.Sprintf("%s_%s", groupedName, tbl)
+		if exists[curr] {
+			ans = append(ans, fmt.Sprintf("`%s` TO `%s%s`", curr, curr, prevTableSuffix))
+		}
+		ans = append(ans, fmt.Sprintf("`%s` TO `%s`", shadow, curr))
+	}
+	return ans
+}
+
+// rollbackClauses creates RENAME TABLE clauses exchanging the current
+// tables and their previous versions (so a rollback can be reverted
+// by another rollback)
+func rollbackClauses(groupedName string, exists map[string]bool) []string {
+	ans := make([]string, 0, len(swappedTables)*3)
+	for _, tbl := range swappedTables {
+		curr := fmt.Sprintf("%s_%s", groupedName, tbl)
+		prev := curr + prevTableSuffix
+		if !exists[prev] {
+			continue
+		}
+		if exists[curr] {
+			tmp := curr + rollbackTmpSuffix
+			ans = append(
+				ans,
+				fmt.Sprintf("`%s` TO `%s`", curr, tmp),
+				fmt.Sprintf("`%s` TO `%s`", prev, curr),
+				fmt.Sprintf("`%s` TO `%s`", tmp, prev),
+			)
+
+		} else {
+			ans = append(ans, fmt.Sprintf("`%s` TO `%s`", prev, curr))
+		}
+	}
+	return ans
+}
+
+// PrepareShadow creates an empty shadow copy of the current liveattrs
+// table (if any) so vert-tagextract can work with the shadow table in
+// the append mode (or when moving a data window). With copyData, the current
+// rows are copied too.
+func PrepareShadow(laDB *sql.DB, groupedName string, copyData bool) error {
+	curr := groupedName + "_liveattrs_entry"
+	shadow := ShadowName(groupedName) + "_liveattrs_entry"
+	if _, err := laDB.Exec(fmt.Sprintf("DROP TABLE IF EXISTS `%s`", shadow)); err != nil {
+		return fmt.Errorf("failed to prepare shadow table: %w", err)
+	}
+	exists, err := existingTables(laDB, curr)
+	if err != nil {
+		return fmt.Errorf("failed to prepare shadow table: %w", err)
+	}
+	if !exists[curr] {
+		return nil
+	}
+	if _, err := laDB.Exec(fmt.Sprintf("CREATE TABLE `%s` LIKE `%s`", shadow, curr)); err != nil {
+		return fmt.Errorf("failed to prepare shadow table: %w", err)
+	}
+	if copyData {
+		if _, err := laDB.Exec(fmt.Sprintf("INSERT INTO `%s` SELECT * FROM `%s`", shadow, curr)); err != nil {
+			return fmt.Errorf("failed to copy current data to shadow table: %w", err)
+		}
+	}
+	return nil
+}
+
+func tableSize(laDB *sql.DB, table string) (int64, error) {
+	row := laDB.QueryRow(
+		"SELECT COALESCE(DATA_LENGTH + INDEX_LENGTH, 0) FROM information_schema.TABLES "+
+			"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?",
+		table,
+	)
+	var ans int64
+	if err := row.Scan(&ans); err != nil {
+		return 0, fmt.Errorf("failed to get size of table %s: %w", table, err)
+	}
+	return ans, nil
+}
+
+// corporaNumRows counts rows of each corpus
+// (a table can be shared by more aligned corpora)
+func corporaNumRows(laDB *sql.DB, table string) (map[string]int, int, error) {
+	rows, err := laDB.Query(
+		fmt.Sprintf("SELECT corpus_id, COUNT(*) FROM `%s` GROUP BY corpus_id", table))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count rows of table %s: %w", table, err)
+	}
+	defer rows.Close()
+	ans := make(map[string]int)
+	var total int
+	for rows.Next() {
+		var corpusID string
+		var cnt int
+		if err := rows.Scan(&corpusID, &cnt); err != nil {
+			return nil, 0, fmt.Errorf("failed to count rows of table %s: %w", table, err)
+		}
+		ans[corpusID] = cnt
+		total += cnt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to count rows of table %s: %w", table, err)
+	}
+	return ans, total, nil
+}
+
+func shrinksTooMuch(prev, curr int, maxShrinkRatio float64) bool {
+	return maxShrinkRatio >= 0 && float64(curr) < float64(prev)*(1-maxShrinkRatio)
+}
+
+// checkSwapResult tests whether rebuilt data can replace the current ones.
+// Besides the rebuilt corpus, all the other corpora sharing the table
+// and the table as a whole are checked as the whole table is swapped.
+func checkSwapResult(res liveattrs.SwapResult, maxShrinkRatio float64) error {
+	if res.NumRows == 0 {
+		return fmt.Errorf("%w: no rows", ErrorInvalidShadowData)
+	}
+	if shrinksTooMuch(res.PrevNumRows, res.NumRows, maxShrinkRatio) {
+		return fmt.Errorf(
+			"%w: number of rows decreased from %d to %d", ErrorInvalidShadowData, res.PrevNumRows, res.NumRows)
+	}
+	for corpusID, prev := range res.PrevCorporaNumRows {
+		if curr := res.CorporaNumRows[corpusID]; shrinksTooMuch(prev, curr, maxShrinkRatio) {
+			return fmt.Errorf(
+				"%w: number of rows of aligned corpus %s decreased from %d to %d",
+				ErrorInvalidShadowData, corpusID, prev, curr)
+		}
+	}
+	if shrinksTooMuch(res.PrevTotalNumRows, res.TotalNumRows, maxShrinkRatio) {
+		return fmt.Errorf(
+			"%w: total number of rows decreased from %d to %d",
+			ErrorInvalidShadowData, res.PrevTotalNumRows, res.TotalNumRows)
+	}
+	return nil
+}
+
+// ValidateShadow compares rebuilt liveattrs data of a corpus with
+// the current ones. Rows are counted for each corpus sharing the table
+// (aligned corpora) and for the whole table, sizes are of whole tables.
+// The maxShrinkRatio argument specifies a max. allowed relative
+// decrease of the number of rows (a negative value disables the check).
+func ValidateShadow(
+	laDB *sql.DB,
+	groupedName, corpusID string,
+	maxShrinkRatio float64,
+) (liveattrs.SwapResult, error) {
+	var ans liveattrs.SwapResult
+	curr := groupedName + "_liveattrs_entry"
+	shadow := ShadowName(groupedName) + "_liveattrs_entry"
+	exists, err := existingTables(laDB, curr, shadow)
+	if err != nil {
+		return ans, err
+	}
+	if !exists[shadow] {
+		return ans, fmt.Errorf("%w: table %s not found", ErrorInvalidShadowData, shadow)
+	}
+	if ans.CorporaNumRows, ans.TotalNumRows, err = corporaNumRows(laDB, shadow); err != nil {
+		return ans, err
+	}
+	ans.NumRows = ans.CorporaNumRows[corpusID]
+	if ans.SizeBytes, err = tableSize(laDB, shadow); err != nil {
+		return ans, err
+	}
+	if exists[curr] {
+		if ans.PrevCorporaNumRows, ans.PrevTotalNumRows, err = corporaNumRows(laDB, curr); err != nil {
+			return ans, err
+		}
+		ans.PrevNumRows = ans.PrevCorporaNumRows[corpusID]
+		if ans.PrevSizeBytes, err = tableSize(laDB, curr); err != nil {
+			return ans, err
+		}
+	}
+	return ans, checkSwapResult(ans, maxShrinkRatio)
+}
+
+// SwapShadowTables atomically replaces the current liveattrs tables
+// with the shadow ones. The current tables are kept as the previous
+// version (replacing an older previous version, if any).
+func SwapShadowTables(laDB *sql.DB, groupedName string) error {
+	exists, err := existingTables(laDB, swapCandidates(groupedName)...)
+	if err != nil {
+		return fmt.Errorf("failed to swap liveattrs tables: %w", err)
+	}
+	clauses := swapClauses(groupedName, exists)
+	if len(clauses) == 0 {
+		return fmt.Errorf("failed to swap liveattrs tables: no shadow tables found")
+	}
+	for _, tbl := range swappedTables {
+		if !exists[fmt.Sprintf("%s_%s", ShadowName(groupedName), tbl)] {
+			continue
+		}
+		if _, err := laDB.Exec(
+			fmt.Sprintf("DROP TABLE IF EXISTS `%s_%s%s`", groupedName, tbl, prevTableSuffix),
+		); err != nil {
+			return fmt.Errorf("failed to remove previous version of %s: %w", tbl, err)
+		}
+	}
+	if _, err := laDB.Exec("RENAME TABLE " + strings.Join(clauses, ", ")); err != nil {
+		return fmt.Errorf("failed to swap liveattrs tables: %w", err)
+	}
+	return nil
+}
+
+// RollbackSwap atomically exchanges the current liveattrs tables
+// with their previous version
+func RollbackSwap(laDB *sql.DB, groupedName string) error {
+	exists, err := existingTables(laDB, swapCandidates(groupedName)...)
+	if err != nil {
+		return fmt.Errorf("failed to roll back liveattrs tables: %w", err)
+	}
+	if !exists[groupedName+"_liveattrs_entry"+prevTableSuffix] {
+		return ErrorNoPrevVersion
+	}
+	if _, err := laDB.Exec(
+		"RENAME TABLE " + strings.Join(rollbackClauses(groupedName, exists), ", "),
+	); err != nil {
+		return fmt.Errorf("failed to roll back liveattrs tables: %w", err)
+	}
+	return nil
+}
+
+// DataVersion identifies a build of liveattrs data. It is stored
+// as a comment of the entry table so it follows the table when
+// the tables are swapped or rolled back.
+type DataVersion struct {
+
+	// JobID is an ID of the liveattrs job which built the data
+	JobID string `json:"jobId"`
+
+	// ConfVersion is a version of the liveattrs configuration
+	// the data were built with (0 = unknown)
+	ConfVersion int `json:"confVersion"`
+
+	Created time.Time `json:"created"`
+}
+
+// SetDataVersion stores data version info of the liveattrs tables
+// of the provided grouped name (use ShadowName for a rebuild in progress)
+func SetDataVersion(laDB *sql.DB, groupedName string, v DataVersion) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to set liveattrs data version: %w", err)
+	}
+	comment := strings.ReplaceAll(strings.ReplaceAll(string(data), `\`, `\\`), "'", "''")
+	if _, err := laDB.Exec(
+		fmt.Sprintf("ALTER TABLE %s_liveattrs_entry COMMENT = '%s'", groupedName, comment),
+	); err != nil {
+		return fmt.Errorf("failed to set liveattrs data version: %w", err)
+	}
+	return nil
+}
+
+// GetDataVersion returns data version info of the liveattrs tables.
+// Data built before the versions were recorded return zero DataVersion.
+func GetDataVersion(laDB *sql.DB, groupedName string) (DataVersion, error) {
+	var ans DataVersion
+	row := laDB.QueryRow(
+		"SELECT TABLE_COMMENT FROM information_schema.TABLES "+
+			"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?",
+		groupedName+"_liveattrs_entry",
+	)
+	var comment string
+	if err := row.Scan(&comment); err != nil {
+		return ans, fmt.Errorf("failed to get liveattrs data version: %w", err)
+	}
+	if !strings.HasPrefix(comment, "{") {
+		return ans, nil
+	}
+	if err := json.Unmarshal([]byte(comment), &ans); err != nil {
+		return ans, fmt.Errorf("failed to get liveattrs data version: %w", err)
+	}
+	return ans, nil
+}
+
+// EnsureBibView creates the bibliography view of liveattrs data
+// in case it does not exist yet. This is normally done by vert-tagextract
+// but a view created over shadow tables would not follow their renaming.
+func EnsureBibView(laDB *sql.DB, groupedName string, conf vteDB.BibViewConf) error {
+	if !conf.IsConfigured() {
+		return nil
+	}
+	view := groupedName + "_bibliography"
+	row := laDB.QueryRow(
+		"SELECT COUNT(*) FROM information_schema.VIEWS "+
+			"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?",
+		view,
+	)
+	var cnt int
+	if err := row.Scan(&cnt); err != nil {
+		return fmt.Errorf("failed to test bib. view existence: %w", err)
+	}
+	if cnt > 0 {
+		return nil
+	}
+	cols := make([]string, len(conf.Cols))
+	for i, col := range conf.Cols {
+		if col == conf.IDAttr {
+			cols[i] = col + " AS id"
+
+		} else {
+			cols[i] = col
+		}
+	}
+	if _, err := laDB.Exec(
+		fmt.Sprintf(
+			"CREATE VIEW `%s` AS SELECT %s FROM `%s_liveattrs_entry`",
+			view, strings.Join(cols, ", "), groupedName,
+		),
+	); err != nil {
+		return fmt.Errorf("failed to create bib. view: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import (
+	"frodo/liveattrs"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSwapClauses(t *testing.T) {
+	exists := map[string]bool{
+		"syn_liveattrs_entry":        true,
+		"syn_liveattrs_entry_prev":   true,
+		"syn_colcounts":              true,
+		"syn_shadow_liveattrs_entry": true,
+	}
+	assert.Equal(
+		t,
+		[]string{
+			"`syn_liveattrs_entry` TO `syn_liveattrs_entry_prev`",
+			"`syn_shadow_liveattrs_entry` TO `syn_liveattrs_entry`",
+		},
+		swapClauses("syn", exists),
+	)
+	assert.Equal(
+		t,
+		[]string{"`syn_shadow_liveattrs_entry` TO `syn_liveattrs_entry`"},
+		swapClauses("syn", map[string]bool{"syn_shadow_liveattrs_entry": true}),
+	)
+}
+
+func TestRollbackClauses(t *testing.T) {
+	exists := map[string]bool{
+		"syn_liveattrs_entry":      true,
+		"syn_liveattrs_entry_prev": true,
+		"syn_colcounts":            true,
+		"syn_colcounts_prev":       true,
+	}
+	assert.Equal(
+		t,
+		[]string{
+			"`syn_liveattrs_entry` TO `syn_liveattrs_entry_rb`",
+			"`syn_liveattrs_entry_prev` TO `syn_liveattrs_entry`",
+			"`syn_liveattrs_entry_rb` TO `syn_liveattrs_entry_prev`",
+			"`syn_colcounts` TO `syn_colcounts_rb`",
+			"`syn_colcounts_prev` TO `syn_colcounts`",
+			"`syn_colcounts_rb` TO `syn_colcounts_prev`",
+		},
+		rollbackClauses("syn", exists),
+	)
+}
+
+func TestCheckSwapResult(t *testing.T) {
+	assert.ErrorIs(t, checkSwapResult(liveattrs.SwapResult{}, 0.5), ErrorInvalidShadowData)
+	assert.NoError(t, checkSwapResult(liveattrs.SwapResult{NumRows: 10}, 0.5))
+	assert.NoError(t, checkSwapResult(liveattrs.SwapResult{PrevNumRows: 100, NumRows: 50}, 0.5))
+	assert.ErrorIs(
+		t, checkSwapResult(liveattrs.SwapResult{PrevNumRows: 100, NumRows: 49}, 0.5), ErrorInvalidShadowData)
+	assert.NoError(t, checkSwapResult(liveattrs.SwapResult{PrevNumRows: 100, NumRows: 1}, -1))
+}
+
+func TestCheckSwapResultAlignedCorpora(t *testing.T) {
+	res := liveattrs.SwapResult{
+		PrevNumRows:        100,
+		NumRows:            100,
+		PrevTotalNumRows:   200,
+		TotalNumRows:       200,
+		PrevCorporaNumRows: map[string]int{"intercorp_cs": 100, "intercorp_en": 100},
+		CorporaNumRows:     map[string]int{"intercorp_cs": 100, "intercorp_en": 100},
+	}
+	assert.NoError(t, checkSwapResult(res, 0.5))
+
+	// the rebuilt table lacks an aligned corpus
+	res.TotalNumRows = 100
+	res.CorporaNumRows = map[string]int{"intercorp_cs": 100}
+	assert.ErrorIs(t, checkSwapResult(res, 0.5), ErrorInvalidShadowData)
+	assert.NoError(t, checkSwapResult(res, -1))
+
+	res.CorporaNumRows = nil
+	res.PrevCorporaNumRows = nil
+	assert.ErrorIs(t, checkSwapResult(res, 0.4), ErrorInvalidShadowData)
+}
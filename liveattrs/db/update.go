@@ -0,0 +1,335 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"frodo/liveattrs"
+	"slices"
+	"strings"
+)
+
+const (
+	mergeBatchSize = 1000
+)
+
+// MergeArgs specifies an incremental update of a liveattrs table
+type MergeArgs struct {
+
+	// GroupedName identifies the production table
+	// (`[GroupedName]_liveattrs_entry`)
+	GroupedName string
+
+	// StagingName identifies a table (`[StagingName]_liveattrs_entry`)
+	// containing new versions of documents. Empty value means there
+	// are no documents to upsert.
+	StagingName string
+
+	// CorpusID is a corpus whose documents are updated
+	// (a production table can be shared by more aligned corpora)
+	CorpusID string
+
+	// BibIDColumn is a column identifying documents (e.g. doc_id)
+	BibIDColumn string
+
+	// DeleteIDs contains documents to be removed
+	DeleteIDs []string
+
+	// HasItemID tells whether the table contains the self-join
+	// `item_id` column
+	HasItemID bool
+
+	// DeleteOrphaned specifies that rows of aligned corpora with items
+	// no longer present in the updated corpus are removed. Otherwise,
+	// ErrorOrphanedItems is returned and nothing is changed.
+	DeleteOrphaned bool
+}
+
+var (
+	ErrorOrphanedItems = errors.New("update would leave aligned items without counterparts")
+)
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+func queryStrings(tx *sql.Tx, query string, args ...any) ([]string, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ans := make([]string, 0, 100)
+	for rows.Next() {
+		var v sql.NullString
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		if v.Valid {
+			ans = append(ans, v.String)
+		}
+	}
+	return ans, rows.Err()
+}
+
+// deleteDocs removes rows of the specified documents and returns
+// number of removed rows along with item IDs of the rows
+func deleteDocs(tx *sql.Tx, args MergeArgs, table string, docIDs []string) (int, []string, error) {
+	var numDeleted int
+	var itemIDs []string
+	for chunk := range slices.Chunk(docIDs, mergeBatchSize) {
+		values := make([]any, 0, len(chunk)+1)
+		values = append(values, args.CorpusID)
+		for _, v := range chunk {
+			values = append(values, v)
+		}
+		where := fmt.Sprintf("corpus_id = ? AND `%s` IN (%s)", args.BibIDColumn, placeholders(len(chunk)))
+		if args.HasItemID {
+			tmp, err := queryStrings(
+				tx, fmt.Sprintf("SELECT DISTINCT item_id FROM `%s` WHERE %s", table, where), values...)
+			if err != nil {
+				return 0, nil, err
+			}
+			itemIDs = append(itemIDs, tmp...)
+		}
+		res, err := tx.Exec(fmt.Sprintf("DELETE FROM `%s` WHERE %s", table, where), values...)
+		if err != nil {
+			return 0, nil, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return 0, nil, err
+		}
+		numDeleted += int(n)
+	}
+	return numDeleted, itemIDs, nil
+}
+
+// findOrphanedItems returns item IDs no longer used by the updated
+// corpus but still used by some other (aligned) corpus
+func findOrphanedItems(tx *sql.Tx, args MergeArgs, table string, itemIDs []string) ([]string, error) {
+	slices.Sort(itemIDs)
+	itemIDs = slices.Compact(itemIDs)
+	ans := make([]string, 0, 10)
+	for chunk := range slices.Chunk(itemIDs, mergeBatchSize) {
+		values := make([]any, 0, 2*len(chunk)+2)
+		values = append(values, args.CorpusID)
+		for _, v := range chunk {
+			values = append(values, v)
+		}
+		values = append(values, args.CorpusID)
+		for _, v := range chunk {
+			values = append(values, v)
+		}
+		tmp, err := queryStrings(
+			tx,
+			fmt.Sprintf(
+				"SELECT DISTINCT item_id FROM `%s` WHERE corpus_id <> ? AND item_id IN (%s) "+
+					"AND item_id NOT IN (SELECT item_id FROM `%s` WHERE corpus_id = ? "+
+					"AND item_id IS NOT NULL AND item_id IN (%s))",
+				table, placeholders(len(chunk)), table, placeholders(len(chunk)),
+			),
+			values...,
+		)
+		if err != nil {
+			return nil, err
+		}
+		ans = append(ans, tmp...)
+	}
+	return ans, nil
+}
+
+// deleteOrphanedItems removes rows of aligned corpora with the provided
+// items and returns number of removed rows along with the affected corpora
+func deleteOrphanedItems(tx *sql.Tx, args MergeArgs, table string, itemIDs []string) (int, []string, error) {
+	var ans int
+	var corpora []string
+	for chunk := range slices.Chunk(itemIDs, mergeBatchSize) {
+		values := make([]any, 0, len(chunk)+1)
+		values = append(values, args.CorpusID)
+		for _, v := range chunk {
+			values = append(values, v)
+		}
+		tmp, err := queryStrings(
+			tx,
+			fmt.Sprintf(
+				"SELECT DISTINCT corpus_id FROM `%s` WHERE corpus_id <> ? AND item_id IN (%s)",
+				table, placeholders(len(chunk)),
+			),
+			values...,
+		)
+		if err != nil {
+			return 0, nil, err
+		}
+		corpora = append(corpora, tmp...)
+		res, err := tx.Exec(
+			fmt.Sprintf(
+				"DELETE FROM `%s` WHERE corpus_id <> ? AND item_id IN (%s)",
+				table, placeholders(len(chunk)),
+			),
+			values...,
+		)
+		if err != nil {
+			return 0, nil, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return 0, nil, err
+		}
+		ans += int(n)
+	}
+	slices.Sort(corpora)
+	return ans, slices.Compact(corpora), nil
+}
+
+// stagingColumns returns columns of the staging table to be copied
+// to the production one. All of them must exist in the production table.
+func stagingColumns(tx *sql.Tx, table, stagingTable string) ([]string, error) {
+	prodCols, err := queryStrings(
+		tx,
+		"SELECT COLUMN_NAME FROM information_schema.columns "+
+			"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?",
+		table,
+	)
+	if err != nil {
+		return nil, err
+	}
+	stagingCols, err := queryStrings(
+		tx,
+		"SELECT COLUMN_NAME FROM information_schema.columns "+
+			"WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? ORDER BY ORDINAL_POSITION",
+		stagingTable,
+	)
+	if err != nil {
+		return nil, err
+	}
+	ans := make([]string, 0, len(stagingCols))
+	for _, col := range stagingCols {
+		if col == "id" {
+			continue
+		}
+		if !slices.Contains(prodCols, col) {
+			return nil, fmt.Errorf(
+				"column %s not found in %s, the data must be rebuilt from scratch", col, table)
+		}
+		ans = append(ans, "`"+col+"`")
+	}
+	return ans, nil
+}
+
+// MergeDocuments replaces documents of a corpus in a production liveattrs
+// table by their versions from a staging table and removes documents
+// listed in args.DeleteIDs. Everything is performed within a single
+// transaction so readers see either the original or the updated data.
+// In case the update leaves items of aligned corpora without counterparts,
+// the rows are either removed too (args.DeleteOrphaned) or the whole
+// update is rolled back with ErrorOrphanedItems.
+// The staging table is left intact.
+func MergeDocuments(laDB *sql.DB, args MergeArgs) (liveattrs.UpdateResult, error) {
+	var ans liveattrs.UpdateResult
+	table := fmt.Sprintf("%s_liveattrs_entry", args.GroupedName)
+	tx, err := laDB.Begin()
+	if err != nil {
+		return ans, fmt.Errorf("failed to merge documents: %w", err)
+	}
+	var itemIDs []string
+	if len(args.DeleteIDs) > 0 {
+		var err error
+		ans.NumDeleted, itemIDs, err = deleteDocs(tx, args, table, args.DeleteIDs)
+		if err != nil {
+			tx.Rollback()
+			return ans, fmt.Errorf("failed to delete documents: %w", err)
+		}
+	}
+	if args.StagingName != "" {
+		stagingTable := fmt.Sprintf("%s_liveattrs_entry", args.StagingName)
+		upserted, err := queryStrings(
+			tx,
+			fmt.Sprintf("SELECT DISTINCT `%s` FROM `%s`", args.BibIDColumn, stagingTable),
+		)
+		if err != nil {
+			tx.Rollback()
+			return ans, fmt.Errorf("failed to read staged documents: %w", err)
+		}
+		ans.NumUpserted = len(upserted)
+		var replacedItems []string
+		ans.NumReplaced, replacedItems, err = deleteDocs(tx, args, table, upserted)
+		if err != nil {
+			tx.Rollback()
+			return ans, fmt.Errorf("failed to replace documents: %w", err)
+		}
+		itemIDs = append(itemIDs, replacedItems...)
+		columns, err := stagingColumns(tx, table, stagingTable)
+		if err != nil {
+			tx.Rollback()
+			return ans, fmt.Errorf("failed to insert documents: %w", err)
+		}
+		_, err = tx.Exec(
+			fmt.Sprintf(
+				"INSERT INTO `%s` (%s) SELECT %s FROM `%s`",
+				table, strings.Join(columns, ", "), strings.Join(columns, ", "), stagingTable,
+			),
+		)
+		if err != nil {
+			// note: this also covers item IDs colliding with other
+			// documents (there is a unique index on item_id, corpus_id)
+			tx.Rollback()
+			return ans, fmt.Errorf("failed to insert documents: %w", err)
+		}
+	}
+	if args.HasItemID && len(itemIDs) > 0 {
+		ans.OrphanedItemIDs, err = findOrphanedItems(tx, args, table, itemIDs)
+		if err != nil {
+			tx.Rollback()
+			return ans, fmt.Errorf("failed to check aligned items: %w", err)
+		}
+		if len(ans.OrphanedItemIDs) > 0 && !args.DeleteOrphaned {
+			tx.Rollback()
+			return ans, fmt.Errorf(
+				"failed to merge documents: %w (%d items)", ErrorOrphanedItems, len(ans.OrphanedItemIDs))
+		}
+		if len(ans.OrphanedItemIDs) > 0 {
+			ans.NumOrphanedDeleted, ans.OrphanedCorpora, err = deleteOrphanedItems(
+				tx, args, table, ans.OrphanedItemIDs)
+			if err != nil {
+				tx.Rollback()
+				return ans, fmt.Errorf("failed to delete aligned items: %w", err)
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return ans, fmt.Errorf("failed to merge documents: %w", err)
+	}
+	return ans, nil
+}
+
+// DropStagingTables removes all the tables created
+// when extracting data into a staging (or shadow) table
+func DropStagingTables(laDB *sql.DB, stagingName string) error {
+	if err := DropTmpTables(laDB, stagingName); err != nil {
+		return err
+	}
+	for _, tbl := range []string{"liveattrs_entry", "liveattrs_entry_old", "colcounts", "colcounts_old"} {
+		if _, err := laDB.Exec(
+			fmt.Sprintf("DROP TABLE IF EXISTS `%s_%s`", stagingName, tbl),
+		); err != nil {
+			return fmt.Errorf("failed to drop staging %s table: %w", tbl, err)
+		}
+	}
+	return nil
+}
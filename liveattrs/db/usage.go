@@ -21,12 +21,9 @@ package db
 
 import (
 	"database/sql"
-	"encoding/json"
-	"fmt"
-	"frodo/corpus"
 	"frodo/liveattrs/request/query"
 	"frodo/liveattrs/utils"
-	"strings"
+	"frodo/metrics"
 	"time"
 
 	"github.com/rs/zerolog"
@@ -60,6 +57,7 @@ type StructAttrUsage struct {
 func (sau *StructAttrUsage) RunHandler() {
 	for data := range sau.channel {
 		data.toZeroLog(log.Info())
+		metrics.ObserveLiveAttrsQuery(data.CorpusID, data.IsCached, data.ProcTime)
 		if !data.IsCached {
 			err := sau.save(data)
 			if err != nil {
@@ -112,119 +110,3 @@ func LoadUsage(laDB *sql.DB, corpusId string) (map[string]int, error) {
 	}
 	return ans, nil
 }
-
-// --
-
-type updIdxResult struct {
-	UsedIndexes    []string
-	RemovedIndexes []string
-	Error          error
-}
-
-func (res *updIdxResult) MarshalJSON() ([]byte, error) {
-	var errStr string
-	if res.Error != nil {
-		errStr = res.Error.Error()
-	}
-	return json.Marshal(struct {
-		UsedIndexes    []string `json:"usedIndexes"`
-		RemovedIndexes []string `json:"removedIndexes"`
-		Error          string   `json:"error,omitempty"`
-	}{
-		UsedIndexes:    res.UsedIndexes,
-		RemovedIndexes: res.RemovedIndexes,
-		Error:          errStr,
-	})
-}
-
-// --
-
-func UpdateIndexes(laDB *sql.DB, corpusInfo *corpus.DBInfo, maxColumns int) updIdxResult {
-	// get most used columns
-	rows, err := laDB.Query(
-		"SELECT structattr_name "+
-			"FROM `usage` "+
-			"WHERE corpus_id = ? AND num_used > 0 ORDER BY num_used DESC LIMIT ?",
-		corpusInfo.Name, maxColumns,
-	)
-	if err != nil && err != sql.ErrNoRows {
-		return updIdxResult{Error: err}
-	}
-	defer rows.Close()
-	columns := make([]string, 0, maxColumns)
-	for rows.Next() {
-		var structattrName string
-		if err := rows.Scan(&structattrName); err != nil {
-			return updIdxResult{Error: err}
-		}
-		columns = append(columns, structattrName)
-	}
-
-	// create indexes if necessary with `_autoindex` appendix
-	var sqlTemplate string
-	if corpusInfo.GroupedName() == corpusInfo.Name {
-		sqlTemplate = "CREATE INDEX IF NOT EXISTS `%s` ON `%s_liveattrs_entry` (`%s`)"
-	} else {
-		sqlTemplate = "CREATE INDEX IF NOT EXISTS `%s` ON `%s_liveattrs_entry` (`%s`, `corpus_id`)"
-	}
-	usedIndexes := make([]any, len(columns))
-	context, err := laDB.Begin()
-	if err != nil {
-		return updIdxResult{Error: err}
-	}
-	for i, column := range columns {
-		usedIndexes[i] = fmt.Sprintf("%s_autoindex", column)
-		_, err := context.Exec(fmt.Sprintf(sqlTemplate, usedIndexes[i], corpusInfo.GroupedName(), column))
-		if err != nil {
-			return updIdxResult{Error: err}
-		}
-	}
-	context.Commit()
-
-	// get remaining unused indexes with `_autoindex` appendix
-	valuesPlaceholders := make([]string, len(usedIndexes))
-	for i := 0; i < len(valuesPlaceholders); i++ {
-		valuesPlaceholders[i] = "?"
-	}
-	sqlTemplate = fmt.Sprintf(
-		"SELECT INDEX_NAME FROM information_schema.statistics where TABLE_NAME = ? AND INDEX_NAME LIKE '%%_autoindex' AND INDEX_NAME NOT IN (%s)",
-		strings.Join(valuesPlaceholders, ", "),
-	)
-	values := append([]any{fmt.Sprintf("`%s_liveattrs_entry`", corpusInfo.GroupedName())}, usedIndexes...)
-	rows, err = laDB.Query(sqlTemplate, values...)
-	if err != nil && err != sql.ErrNoRows {
-		return updIdxResult{Error: err}
-	}
-	defer rows.Close()
-	unusedIndexes := make([]string, 0, 10)
-	for rows.Next() {
-		var indexName string
-		if err := rows.Scan(&indexName); err != nil {
-			return updIdxResult{Error: err}
-		}
-		unusedIndexes = append(unusedIndexes, indexName)
-	}
-
-	// drop unused indexes
-	sqlTemplate = "DROP INDEX %s ON `%s_liveattrs_entry`"
-	context, err = laDB.Begin()
-	if err != nil {
-		return updIdxResult{Error: err}
-	}
-	for _, index := range unusedIndexes {
-		_, err := context.Exec(fmt.Sprintf(sqlTemplate, index, corpusInfo.GroupedName()))
-		if err != nil {
-			return updIdxResult{Error: err}
-		}
-	}
-	context.Commit()
-
-	ans := updIdxResult{
-		UsedIndexes:    make([]string, len(usedIndexes)),
-		RemovedIndexes: unusedIndexes,
-	}
-	for i, v := range usedIndexes {
-		ans.UsedIndexes[i] = v.(string)
-	}
-	return ans
-}
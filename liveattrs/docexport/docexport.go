@@ -0,0 +1,223 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package docexport provides writers for exporting document lists
+// in different file formats. All the writers process rows one by one
+// so a document list can be streamed directly from a database cursor.
+package docexport
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"frodo/liveattrs/db"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	FormatCSV   = "csv"
+	FormatTSV   = "tsv"
+	FormatJSONL = "jsonl"
+	FormatXLSX  = "xlsx"
+
+	// flushInterval specifies after how many rows the written
+	// data are flushed to the client
+	flushInterval = 1000
+
+	xlsxSheetName = "Sheet1"
+)
+
+var (
+	ErrorUnsupportedFormat = errors.New("unsupported export format")
+)
+
+// Writer writes document rows in a specific format.
+// Close must be called once all the rows are written.
+type Writer interface {
+	WriteRow(row *db.DocumentRow) error
+	Close() error
+}
+
+// ContentType returns a MIME type of a format
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatTSV:
+		return "text/tab-separated-values; charset=utf-8"
+	case FormatJSONL:
+		return "application/jsonl; charset=utf-8"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "application/octet-stream"
+}
+
+func header(attrs []string) []string {
+	ans := make([]string, 0, len(attrs)+3)
+	ans = append(ans, "id", "label")
+	ans = append(ans, attrs...)
+	return append(ans, "tokens")
+}
+
+func flushHTTP(w io.Writer) {
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// ---------------- CSV, TSV -------------------
+
+type csvWriter struct {
+	w       io.Writer
+	cw      *csv.Writer
+	attrs   []string
+	numRows int
+}
+
+func (cw *csvWriter) WriteRow(row *db.DocumentRow) error {
+	rec := make([]string, 0, len(cw.attrs)+3)
+	rec = append(rec, row.ID, row.Label)
+	for _, attr := range cw.attrs {
+		rec = append(rec, row.Attrs[attr])
+	}
+	rec = append(rec, strconv.Itoa(row.NumPos))
+	if err := cw.cw.Write(rec); err != nil {
+		return err
+	}
+	cw.numRows++
+	if cw.numRows%flushInterval == 0 {
+		cw.cw.Flush()
+		flushHTTP(cw.w)
+		return cw.cw.Error()
+	}
+	return nil
+}
+
+func (cw *csvWriter) Close() error {
+	cw.cw.Flush()
+	return cw.cw.Error()
+}
+
+// ---------------- JSONL -------------------
+
+type jsonlWriter struct {
+	w       io.Writer
+	enc     *json.Encoder
+	numRows int
+}
+
+func (jw *jsonlWriter) WriteRow(row *db.DocumentRow) error {
+	if err := jw.enc.Encode(row); err != nil {
+		return err
+	}
+	jw.numRows++
+	if jw.numRows%flushInterval == 0 {
+		flushHTTP(jw.w)
+	}
+	return nil
+}
+
+func (jw *jsonlWriter) Close() error {
+	return nil
+}
+
+// ---------------- XLSX -------------------
+
+// xlsxWriter uses excelize's stream writer which keeps
+// only a limited number of rows in memory. Please note that
+// the XLSX format (a zip archive) cannot be sent before
+// all the rows are written.
+type xlsxWriter struct {
+	w      io.Writer
+	file   *excelize.File
+	sw     *excelize.StreamWriter
+	attrs  []string
+	rowIdx int
+}
+
+func (xw *xlsxWriter) writeCells(cells []any) error {
+	xw.rowIdx++
+	cell, err := excelize.CoordinatesToCellName(1, xw.rowIdx)
+	if err != nil {
+		return err
+	}
+	return xw.sw.SetRow(cell, cells)
+}
+
+func (xw *xlsxWriter) WriteRow(row *db.DocumentRow) error {
+	cells := make([]any, 0, len(xw.attrs)+3)
+	cells = append(cells, row.ID, row.Label)
+	for _, attr := range xw.attrs {
+		cells = append(cells, row.Attrs[attr])
+	}
+	cells = append(cells, row.NumPos)
+	return xw.writeCells(cells)
+}
+
+func (xw *xlsxWriter) Close() error {
+	defer xw.file.Close()
+	if err := xw.sw.Flush(); err != nil {
+		return err
+	}
+	return xw.file.Write(xw.w)
+}
+
+func newXLSXWriter(w io.Writer, attrs []string) (*xlsxWriter, error) {
+	file := excelize.NewFile()
+	sw, err := file.NewStreamWriter(xlsxSheetName)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	ans := &xlsxWriter{w: w, file: file, sw: sw, attrs: attrs}
+	hdr := header(attrs)
+	cells := make([]any, len(hdr))
+	for i, v := range hdr {
+		cells[i] = v
+	}
+	if err := ans.writeCells(cells); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return ans, nil
+}
+
+// NewWriter creates a writer for a specified format. Tabular formats
+// contain a header with the "id" and "label" columns followed by
+// the attrs and by a number of tokens.
+func NewWriter(format string, w io.Writer, attrs []string) (Writer, error) {
+	switch format {
+	case FormatCSV, FormatTSV:
+		cw := csv.NewWriter(w)
+		if format == FormatTSV {
+			cw.Comma = '\t'
+		}
+		if err := cw.Write(header(attrs)); err != nil {
+			return nil, err
+		}
+		return &csvWriter{w: w, cw: cw, attrs: attrs}, nil
+	case FormatJSONL:
+		return &jsonlWriter{w: w, enc: json.NewEncoder(w)}, nil
+	case FormatXLSX:
+		return newXLSXWriter(w, attrs)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrorUnsupportedFormat, format)
+}
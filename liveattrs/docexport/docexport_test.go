@@ -0,0 +1,81 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package docexport
+
+import (
+	"bytes"
+	"frodo/liveattrs/db"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+)
+
+func writeTestRows(t *testing.T, format string) *bytes.Buffer {
+	var buff bytes.Buffer
+	w, err := NewWriter(format, &buff, []string{"doc.author", "doc.year"})
+	assert.NoError(t, err)
+	assert.NoError(t, w.WriteRow(&db.DocumentRow{
+		ID: "d1", Label: "First, \"quoted\"", NumPos: 120,
+		Attrs: map[string]string{"doc.author": "Čapek", "doc.year": "1920"},
+	}))
+	assert.NoError(t, w.WriteRow(&db.DocumentRow{
+		ID: "d2", Label: "Second", NumPos: 80,
+		Attrs: map[string]string{"doc.author": "Hašek"},
+	}))
+	assert.NoError(t, w.Close())
+	return &buff
+}
+
+func TestCSVAndTSVExport(t *testing.T) {
+	assert.Equal(
+		t,
+		"id,label,doc.author,doc.year,tokens\n"+
+			"d1,\"First, \"\"quoted\"\"\",Čapek,1920,120\n"+
+			"d2,Second,Hašek,,80\n",
+		writeTestRows(t, FormatCSV).String(),
+	)
+	lines := strings.Split(writeTestRows(t, FormatTSV).String(), "\n")
+	assert.Equal(t, "id\tlabel\tdoc.author\tdoc.year\ttokens", lines[0])
+	assert.Equal(t, "d2\tSecond\tHašek\t\t80", lines[2])
+}
+
+func TestJSONLExport(t *testing.T) {
+	lines := strings.Split(strings.TrimSpace(writeTestRows(t, FormatJSONL).String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.JSONEq(
+		t,
+		`{"idx": 0, "id": "d2", "label": "Second", "attrs": {"doc.author": "Hašek"}, "numOfPos": 80}`,
+		lines[1],
+	)
+}
+
+func TestXLSXExport(t *testing.T) {
+	file, err := excelize.OpenReader(writeTestRows(t, FormatXLSX))
+	assert.NoError(t, err)
+	defer file.Close()
+	rows, err := file.GetRows(xlsxSheetName)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"id", "label", "doc.author", "doc.year", "tokens"}, rows[0])
+	assert.Equal(t, []string{"d1", "First, \"quoted\"", "Čapek", "1920", "120"}, rows[1])
+	assert.Len(t, rows, 3)
+}
+
+func TestUnsupportedFormat(t *testing.T) {
+	_, err := NewWriter("pdf", &bytes.Buffer{}, []string{})
+	assert.ErrorIs(t, err, ErrorUnsupportedFormat)
+}
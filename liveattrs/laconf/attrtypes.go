@@ -0,0 +1,70 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package laconf
+
+import (
+	"fmt"
+
+	vteconf "github.com/czcorpus/vert-tagextract/v3/cnf"
+)
+
+const (
+	AttrTypeString = "string"
+	AttrTypeInt    = "int"
+	AttrTypeFloat  = "float"
+	AttrTypeDate   = "date"
+)
+
+// AttrTypes maps structural attributes (in dot notation, e.g. "doc.year")
+// to their value types. Attributes without a declared type are strings.
+// As liveattrs store all the values as strings, the types only affect
+// how the values are compared in range queries and how they are
+// summarized in query responses.
+type AttrTypes map[string]string
+
+// TypeOf returns a declared type of an attribute
+func (at AttrTypes) TypeOf(attr string) string {
+	if v, ok := at[attr]; ok && v != "" {
+		return v
+	}
+	return AttrTypeString
+}
+
+// IsNumeric tells whether an attribute is declared as int or float
+func (at AttrTypes) IsNumeric(attr string) bool {
+	t := at.TypeOf(attr)
+	return t == AttrTypeInt || t == AttrTypeFloat
+}
+
+// Validate tests whether all the types are known and whether
+// the attributes are part of the liveattrs configuration
+func (at AttrTypes) Validate(conf *vteconf.VTEConf) error {
+	availAttrs := make(map[string]bool)
+	for _, attr := range GetSubcorpAttrs(conf) {
+		availAttrs[attr] = true
+	}
+	for attr, tp := range at {
+		switch tp {
+		case AttrTypeString, AttrTypeInt, AttrTypeFloat, AttrTypeDate:
+		default:
+			return fmt.Errorf("unknown type %s of attribute %s", tp, attr)
+		}
+		if !availAttrs[attr] {
+			return fmt.Errorf("cannot set type of attribute %s - not configured in liveattrs", attr)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,373 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package laconf
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	vteconf "github.com/czcorpus/vert-tagextract/v3/cnf"
+)
+
+const (
+	// AuthorHeader is an HTTP header identifying a user
+	// who changes a liveattrs configuration
+	AuthorHeader = "X-Frodo-User"
+
+	historyDirSuffix        = ".history"
+	maxVersionWriteAttempts = 10
+)
+
+var (
+	ErrorNoSuchVersion = errors.New("no such configuration version")
+)
+
+// ConfChange is a single changed value of a configuration.
+// Nested values are identified by a dot-separated path
+// (e.g. bibView.idAttr), lists are compared as whole values.
+type ConfChange struct {
+	Path string `json:"path"`
+	Old  any    `json:"old,omitempty"`
+	New  any    `json:"new,omitempty"`
+}
+
+// ConfVersion is a stored version of a liveattrs configuration
+type ConfVersion struct {
+	Version int       `json:"version"`
+	Created time.Time `json:"created"`
+
+	// Author identifies a user who saved the version. It is empty
+	// for versions found in a configuration file changed outside
+	// of Frodo (e.g. by a manual edit).
+	Author string `json:"author"`
+
+	// RestoredFrom is a version the configuration
+	// was rolled back to (if any)
+	RestoredFrom int `json:"restoredFrom,omitempty"`
+
+	// Diff contains changes compared with the previous version
+	Diff []ConfChange `json:"diff"`
+
+	// Conf is the configuration with passwords removed
+	Conf *vteconf.VTEConf `json:"conf,omitempty"`
+
+	// AttrTypes are attribute types valid along with the configuration.
+	// The value is nil for versions stored before the types were versioned.
+	AttrTypes AttrTypes `json:"attrTypes"`
+}
+
+// flattenConf converts a configuration into a map of paths
+// and values. DB connection is omitted as it is always replaced
+// by the Frodo's own configuration.
+func flattenConf(conf *vteconf.VTEConf) (map[string]any, error) {
+	ans := make(map[string]any)
+	if conf == nil {
+		return ans, nil
+	}
+	rawData, err := json.Marshal(conf.WithoutPasswords())
+	if err != nil {
+		return nil, err
+	}
+	var data map[string]any
+	if err := json.Unmarshal(rawData, &data); err != nil {
+		return nil, err
+	}
+	delete(data, "db")
+	var flatten func(prefix string, v map[string]any)
+	flatten = func(prefix string, v map[string]any) {
+		for k, item := range v {
+			if obj, ok := item.(map[string]any); ok && len(obj) > 0 {
+				flatten(prefix+k+".", obj)
+
+			} else {
+				ans[prefix+k] = item
+			}
+		}
+	}
+	flatten("", data)
+	return ans, nil
+}
+
+// DiffConfs returns changes between two configurations sorted by path.
+// A nil configuration is considered empty.
+func DiffConfs(oldConf, newConf *vteconf.VTEConf) ([]ConfChange, error) {
+	oldItems, err := flattenConf(oldConf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare configurations: %w", err)
+	}
+	newItems, err := flattenConf(newConf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare configurations: %w", err)
+	}
+	ans := make([]ConfChange, 0, 10)
+	for k, v := range newItems {
+		if ov, ok := oldItems[k]; !ok || !reflect.DeepEqual(ov, v) {
+			ans = append(ans, ConfChange{Path: k, Old: oldItems[k], New: v})
+		}
+	}
+	for k, v := range oldItems {
+		if _, ok := newItems[k]; !ok {
+			ans = append(ans, ConfChange{Path: k, Old: v})
+		}
+	}
+	slices.SortFunc(ans, func(a, b ConfChange) int { return strings.Compare(a.Path, b.Path) })
+	return ans, nil
+}
+
+// DiffAttrTypes returns changes between two attribute type declarations
+// sorted by path (attrTypes.[attribute name]).
+func DiffAttrTypes(oldTypes, newTypes AttrTypes) []ConfChange {
+	ans := make([]ConfChange, 0, len(newTypes))
+	for k, v := range newTypes {
+		if ov, ok := oldTypes[k]; !ok {
+			ans = append(ans, ConfChange{Path: "attrTypes." + k, New: v})
+
+		} else if ov != v {
+			ans = append(ans, ConfChange{Path: "attrTypes." + k, Old: ov, New: v})
+		}
+	}
+	for k, v := range oldTypes {
+		if _, ok := newTypes[k]; !ok {
+			ans = append(ans, ConfChange{Path: "attrTypes." + k, Old: v})
+		}
+	}
+	slices.SortFunc(ans, func(a, b ConfChange) int { return strings.Compare(a.Path, b.Path) })
+	return ans
+}
+
+// diffVersions returns changes of both a configuration and attribute types
+func diffVersions(
+	oldConf *vteconf.VTEConf,
+	oldTypes AttrTypes,
+	newConf *vteconf.VTEConf,
+	newTypes AttrTypes,
+) ([]ConfChange, error) {
+	ans, err := DiffConfs(oldConf, newConf)
+	if err != nil {
+		return nil, err
+	}
+	ans = append(ans, DiffAttrTypes(oldTypes, newTypes)...)
+	slices.SortFunc(ans, func(a, b ConfChange) int { return strings.Compare(a.Path, b.Path) })
+	return ans, nil
+}
+
+func (lcache *LiveAttrsBuildConfProvider) historyDir(corpname string) string {
+	return filepath.Join(lcache.confDirPath, corpname+historyDirSuffix)
+}
+
+func (lcache *LiveAttrsBuildConfProvider) versionPath(corpname string, version int) string {
+	return filepath.Join(lcache.historyDir(corpname), strconv.Itoa(version)+".json")
+}
+
+// versionNumbers returns sorted numbers of stored versions of a configuration
+func (lcache *LiveAttrsBuildConfProvider) versionNumbers(corpname string) ([]int, error) {
+	entries, err := os.ReadDir(lcache.historyDir(corpname))
+	if os.IsNotExist(err) {
+		return []int{}, nil
+
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to list configuration versions: %w", err)
+	}
+	ans := make([]int, 0, len(entries))
+	for _, entry := range entries {
+		v, err := strconv.Atoi(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil || entry.IsDir() {
+			continue
+		}
+		ans = append(ans, v)
+	}
+	slices.Sort(ans)
+	return ans, nil
+}
+
+func (lcache *LiveAttrsBuildConfProvider) loadVersion(corpname string, version int) (*ConfVersion, error) {
+	rawData, err := os.ReadFile(lcache.versionPath(corpname, version))
+	if os.IsNotExist(err) {
+		return nil, ErrorNoSuchVersion
+
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load configuration version %d: %w", version, err)
+	}
+	var ans ConfVersion
+	if err := json.Unmarshal(rawData, &ans); err != nil {
+		return nil, fmt.Errorf("failed to load configuration version %d: %w", version, err)
+	}
+	return &ans, nil
+}
+
+// latestVersion returns the latest stored version of a configuration
+// or nil if there is none
+func (lcache *LiveAttrsBuildConfProvider) latestVersion(corpname string) (*ConfVersion, error) {
+	versions, err := lcache.versionNumbers(corpname)
+	if err != nil || len(versions) == 0 {
+		return nil, err
+	}
+	return lcache.loadVersion(corpname, versions[len(versions)-1])
+}
+
+// storeVersion writes a new version following the provided previous one.
+// Versions are created exclusively so concurrent writers (e.g. more service
+// instances sharing the configuration directory) cannot overwrite each other.
+func (lcache *LiveAttrsBuildConfProvider) storeVersion(
+	corpname string,
+	prev *ConfVersion,
+	item ConfVersion,
+) (*ConfVersion, error) {
+	if err := os.MkdirAll(lcache.historyDir(corpname), 0777); err != nil {
+		return nil, fmt.Errorf("failed to store configuration version: %w", err)
+	}
+	item.Version = 1
+	if prev != nil {
+		item.Version = prev.Version + 1
+	}
+	for i := 0; i < maxVersionWriteAttempts; i++ {
+		rawData, err := json.MarshalIndent(item, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to store configuration version: %w", err)
+		}
+		f, err := os.OpenFile(
+			lcache.versionPath(corpname, item.Version), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0777)
+		if os.IsExist(err) {
+			item.Version++
+			continue
+
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to store configuration version: %w", err)
+		}
+		_, err = f.Write(rawData)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to store configuration version: %w", err)
+		}
+		return &item, nil
+	}
+	return nil, fmt.Errorf("failed to store configuration version: too many concurrent writes")
+}
+
+// syncFileVersion stores the current configuration file (along with
+// attribute types) as a new version in case it differs from the latest
+// stored version (e.g. the file has been created before versioning was
+// introduced or edited manually).
+// The latest version (possibly the new one) is returned.
+func (lcache *LiveAttrsBuildConfProvider) syncFileVersion(corpname string) (*ConfVersion, error) {
+	latest, err := lcache.latestVersion(corpname)
+	if err != nil {
+		return nil, err
+	}
+	finfo, err := os.Stat(lcache.confPath(corpname))
+	if os.IsNotExist(err) {
+		return latest, nil
+
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to sync configuration version: %w", err)
+	}
+	fileConf, err := LoadConf(lcache.confPath(corpname))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sync configuration version: %w", err)
+	}
+	fileTypes, err := lcache.readAttrTypes(corpname)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sync configuration version: %w", err)
+	}
+	var latestConf *vteconf.VTEConf
+	latestTypes := fileTypes // versions without types are not considered different
+	if latest != nil {
+		latestConf = latest.Conf
+		if latest.AttrTypes != nil {
+			latestTypes = latest.AttrTypes
+		}
+	}
+	diff, err := diffVersions(latestConf, latestTypes, fileConf, fileTypes)
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil && len(diff) == 0 {
+		return latest, nil
+	}
+	expConf := fileConf.WithoutPasswords()
+	return lcache.storeVersion(
+		corpname,
+		latest,
+		ConfVersion{Created: finfo.ModTime(), Diff: diff, Conf: &expConf, AttrTypes: fileTypes},
+	)
+}
+
+// History returns all the stored versions of a configuration
+// (without the configurations themselves) sorted from the oldest one
+func (lcache *LiveAttrsBuildConfProvider) History(corpname string) ([]ConfVersion, error) {
+	versions, err := lcache.versionNumbers(corpname)
+	if err != nil {
+		return nil, err
+	}
+	ans := make([]ConfVersion, 0, len(versions))
+	for _, v := range versions {
+		item, err := lcache.loadVersion(corpname, v)
+		if err != nil {
+			return nil, err
+		}
+		item.Conf = nil
+		ans = append(ans, *item)
+	}
+	return ans, nil
+}
+
+// GetVersion returns a stored version of a configuration
+// or ErrorNoSuchVersion
+func (lcache *LiveAttrsBuildConfProvider) GetVersion(corpname string, version int) (*ConfVersion, error) {
+	return lcache.loadVersion(corpname, version)
+}
+
+// CurrentVersion returns the version number of the current configuration.
+// In case the configuration file differs from the latest stored version
+// (or it is not versioned yet), it is stored as a new version first.
+// Zero is returned if there is no configuration and no stored version.
+func (lcache *LiveAttrsBuildConfProvider) CurrentVersion(corpname string) (int, error) {
+	lcache.saveLock.Lock()
+	defer lcache.saveLock.Unlock()
+	latest, err := lcache.syncFileVersion(corpname)
+	if err != nil || latest == nil {
+		return 0, err
+	}
+	return latest.Version, nil
+}
+
+// Rollback saves a stored version of a configuration (including
+// attribute types) as a new current version. For versions stored
+// without attribute types, the current types are kept.
+func (lcache *LiveAttrsBuildConfProvider) Rollback(corpname string, version int, author string) (*ConfVersion, error) {
+	item, err := lcache.loadVersion(corpname, version)
+	if err != nil {
+		return nil, err
+	}
+	if item.Conf == nil {
+		return nil, fmt.Errorf("configuration version %d contains no data", version)
+	}
+	conf := *item.Conf
+	if lcache.globalDBConf.Type == "mysql" {
+		conf.DB = *lcache.globalDBConf
+	}
+	return lcache.save(&conf, item.AttrTypes, author, version)
+}
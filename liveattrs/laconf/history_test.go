@@ -0,0 +1,127 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package laconf
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	vteconf "github.com/czcorpus/vert-tagextract/v3/cnf"
+	vtedb "github.com/czcorpus/vert-tagextract/v3/db"
+)
+
+func TestDiffConfs(t *testing.T) {
+	conf1 := &vteconf.VTEConf{
+		Corpus:        "syn",
+		AtomStructure: "doc",
+		Structures:    map[string][]string{"doc": {"id"}},
+		DB:            vtedb.Conf{Type: "mysql", Password: "secret"},
+	}
+	conf2 := *conf1
+	conf2.AtomStructure = "p"
+	conf2.Structures = map[string][]string{"doc": {"id", "title"}}
+	conf2.DB.Password = "other"
+	diff, err := DiffConfs(conf1, &conf2)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		[]ConfChange{
+			{Path: "atomStructure", Old: "doc", New: "p"},
+			{Path: "structures.doc", Old: []any{"id"}, New: []any{"id", "title"}},
+		},
+		diff,
+	)
+}
+
+func TestSaveCreatesVersions(t *testing.T) {
+	provider := NewLiveAttrsBuildConfProvider(t.TempDir(), &vtedb.Conf{Type: "mysql", Password: "secret"})
+	conf := &vteconf.VTEConf{Corpus: "syn", AtomStructure: "doc", DB: vtedb.Conf{Type: "mysql"}}
+	assert.NoError(t, provider.Save(conf, "alice"))
+	conf.AtomStructure = "p"
+	assert.NoError(t, provider.Save(conf, "bob"))
+
+	// a manual edit is detected and stored as a version without an author
+	rawData, err := os.ReadFile(provider.confPath("syn"))
+	assert.NoError(t, err)
+	rawData = []byte(string(rawData[:len(rawData)-1]) + `, "maxNumErrors": 10}`)
+	assert.NoError(t, os.WriteFile(provider.confPath("syn"), rawData, 0666))
+	version, err := provider.CurrentVersion("syn")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, version)
+
+	history, err := provider.History("syn")
+	assert.NoError(t, err)
+	assert.Len(t, history, 3)
+	assert.Equal(t, "bob", history[1].Author)
+	assert.Equal(t, []ConfChange{{Path: "atomStructure", Old: "doc", New: "p"}}, history[1].Diff)
+	assert.Equal(t, "", history[2].Author)
+	assert.Nil(t, history[2].Conf)
+
+	restored, err := provider.Rollback("syn", 1, "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, 4, restored.Version)
+	assert.Equal(t, 1, restored.RestoredFrom)
+	curr, err := provider.Get("syn")
+	assert.NoError(t, err)
+	assert.Equal(t, "doc", curr.AtomStructure)
+	assert.Equal(t, 0, curr.MaxNumErrors)
+
+	_, err = provider.GetVersion("syn", 10)
+	assert.ErrorIs(t, err, ErrorNoSuchVersion)
+}
+
+func TestAttrTypesAreVersioned(t *testing.T) {
+	provider := NewLiveAttrsBuildConfProvider(t.TempDir(), &vtedb.Conf{Type: "mysql"})
+	conf := &vteconf.VTEConf{Corpus: "syn", AtomStructure: "doc", DB: vtedb.Conf{Type: "mysql"}}
+	assert.NoError(t, provider.Save(conf, "alice"))
+	assert.NoError(t, provider.SaveAttrTypes("syn", AttrTypes{"doc.year": "int"}, "bob"))
+	// unchanged types do not create a new version
+	assert.NoError(t, provider.SaveAttrTypes("syn", AttrTypes{"doc.year": "int"}, "bob"))
+
+	history, err := provider.History("syn")
+	assert.NoError(t, err)
+	assert.Len(t, history, 2)
+	assert.Equal(t, []ConfChange{{Path: "attrTypes.doc.year", New: "int"}}, history[1].Diff)
+
+	restored, err := provider.Rollback("syn", 1, "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, []ConfChange{{Path: "attrTypes.doc.year", Old: "int"}}, restored.Diff)
+	types, err := provider.GetAttrTypes("syn")
+	assert.NoError(t, err)
+	assert.Empty(t, types)
+}
+
+func TestSaveWithAttrTypesCreatesSingleVersion(t *testing.T) {
+	provider := NewLiveAttrsBuildConfProvider(t.TempDir(), &vtedb.Conf{Type: "mysql"})
+	conf := &vteconf.VTEConf{Corpus: "syn", AtomStructure: "doc", DB: vtedb.Conf{Type: "mysql"}}
+	assert.NoError(t, provider.Save(conf, "alice"))
+	conf.AtomStructure = "p"
+	assert.NoError(t, provider.SaveWithAttrTypes(conf, AttrTypes{"doc.year": "int"}, "bob"))
+
+	history, err := provider.History("syn")
+	assert.NoError(t, err)
+	assert.Len(t, history, 2)
+	assert.ElementsMatch(
+		t,
+		[]ConfChange{
+			{Path: "atomStructure", Old: "doc", New: "p"},
+			{Path: "attrTypes.doc.year", New: "int"},
+		},
+		history[1].Diff,
+	)
+}
@@ -55,6 +55,11 @@ type PatchArgs struct {
 	Ngrams                  *vteCnf.NgramConf     `json:"ngrams"`
 	TagsetAttr              *string               `json:"tagsetAttr"`
 	TagsetName              *corp.SupportedTagset `json:"tagsetName"`
+
+	// AttrTypes declares value types of structural attributes
+	// (e.g. {"doc.year": "int"}). Attributes without a declared
+	// type are treated as strings.
+	AttrTypes AttrTypes `json:"attrTypes"`
 }
 
 func (la *PatchArgs) ValidateDataWindow() error {
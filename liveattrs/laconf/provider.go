@@ -27,6 +27,8 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/rs/zerolog/log"
 
@@ -164,10 +166,117 @@ type LiveAttrsBuildConfProvider struct {
 	confDirPath  string
 	globalDBConf *vtedb.Conf
 	data         map[string]*vteconf.VTEConf
+
+	// attrTypes caches attribute types stored along
+	// with the configuration files
+	attrTypes map[string]AttrTypes
+
+	// dataLock guards data and attrTypes as the cache can be also
+	// invalidated by other service instances
+	dataLock sync.RWMutex
+
+	// saveLock serializes saving of configurations and their versions
+	saveLock sync.Mutex
+}
+
+func (lcache *LiveAttrsBuildConfProvider) confPath(corpname string) string {
+	return filepath.Join(lcache.confDirPath, corpname+".json")
+}
+
+func (lcache *LiveAttrsBuildConfProvider) attrTypesPath(corpname string) string {
+	return path.Join(lcache.confDirPath, corpname+".types.json")
+}
+
+// GetAttrTypes returns declared types of structural attributes
+// of a corpus. In case no types are declared, an empty map is returned.
+func (lcache *LiveAttrsBuildConfProvider) GetAttrTypes(corpname string) (AttrTypes, error) {
+	lcache.dataLock.RLock()
+	v, ok := lcache.attrTypes[corpname]
+	lcache.dataLock.RUnlock()
+	if ok {
+		return v, nil
+	}
+	ans, err := lcache.readAttrTypes(corpname)
+	if err != nil {
+		return nil, err
+	}
+	lcache.dataLock.Lock()
+	lcache.attrTypes[corpname] = ans
+	lcache.dataLock.Unlock()
+	return ans, nil
+}
+
+// readAttrTypes loads attribute types directly from their file
+// (bypassing the cache)
+func (lcache *LiveAttrsBuildConfProvider) readAttrTypes(corpname string) (AttrTypes, error) {
+	ans := make(AttrTypes)
+	rawData, err := os.ReadFile(lcache.attrTypesPath(corpname))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to load attribute types: %w", err)
+
+	} else if err == nil {
+		if err := json.Unmarshal(rawData, &ans); err != nil {
+			return nil, fmt.Errorf("failed to load attribute types: %w", err)
+		}
+	}
+	return ans, nil
+}
+
+func (lcache *LiveAttrsBuildConfProvider) writeAttrTypes(corpname string, types AttrTypes) error {
+	rawData, err := json.MarshalIndent(types, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to save attribute types: %w", err)
+	}
+	if err := os.WriteFile(lcache.attrTypesPath(corpname), rawData, 0777); err != nil {
+		return fmt.Errorf("failed to save attribute types: %w", err)
+	}
+	lcache.dataLock.Lock()
+	lcache.attrTypes[corpname] = types
+	lcache.dataLock.Unlock()
+	return nil
+}
+
+// SaveAttrTypes stores declared types of structural attributes
+// of a corpus. The types are stored in a separate file so
+// the configuration remains usable by vert-tagextract. In case
+// the types change, a new version of the configuration is stored
+// (see History). The author identifies a user who made the change.
+func (lcache *LiveAttrsBuildConfProvider) SaveAttrTypes(corpname string, types AttrTypes, author string) error {
+	if types == nil {
+		types = make(AttrTypes)
+	}
+	lcache.saveLock.Lock()
+	defer lcache.saveLock.Unlock()
+	prev, err := lcache.syncFileVersion(corpname)
+	if err != nil {
+		return fmt.Errorf("failed to save attribute types: %w", err)
+	}
+	if err := lcache.writeAttrTypes(corpname, types); err != nil {
+		return err
+	}
+	if prev == nil || prev.Conf == nil {
+		return nil // nothing to attach the types to (version is created along with the conf)
+	}
+	diff := DiffAttrTypes(prev.AttrTypes, types)
+	if len(diff) == 0 {
+		return nil
+	}
+	_, err = lcache.storeVersion(
+		corpname,
+		prev,
+		ConfVersion{
+			Created:   time.Now(),
+			Author:    author,
+			Diff:      diff,
+			Conf:      prev.Conf,
+			AttrTypes: types,
+		},
+	)
+	return err
 }
 
 func (lcache *LiveAttrsBuildConfProvider) loadFromFile(corpname string, storeToCache bool) (*vteconf.VTEConf, error) {
-	confPath := path.Join(lcache.confDirPath, corpname+".json")
+	confPath := lcache.confPath(corpname)
 	isFile, err := fs.IsFile(confPath)
 	if err != nil {
 		return nil, err
@@ -178,7 +287,9 @@ func (lcache *LiveAttrsBuildConfProvider) loadFromFile(corpname string, storeToC
 			return nil, err
 		}
 		if storeToCache {
+			lcache.dataLock.Lock()
 			lcache.data[corpname] = v
+			lcache.dataLock.Unlock()
 		}
 		if lcache.globalDBConf.Type == "mysql" {
 			v.DB = *lcache.globalDBConf
@@ -194,7 +305,10 @@ func (lcache *LiveAttrsBuildConfProvider) loadFromFile(corpname string, storeToC
 // In case there is no other error but the configuration does not exist,
 // the method returns ErrorNoSuchConfig error
 func (lcache *LiveAttrsBuildConfProvider) Get(corpname string) (*vteconf.VTEConf, error) {
-	if v, ok := lcache.data[corpname]; ok {
+	lcache.dataLock.RLock()
+	v, ok := lcache.data[corpname]
+	lcache.dataLock.RUnlock()
+	if ok {
 		return v, nil
 	}
 	return lcache.loadFromFile(corpname, true)
@@ -223,42 +337,111 @@ func (lcache *LiveAttrsBuildConfProvider) GetUncachedWithoutPasswords(corpname s
 	return &ans, nil
 }
 
-// Save saves a provided configuration to a file for later use
-func (lcache *LiveAttrsBuildConfProvider) Save(data *vteconf.VTEConf) error {
+// save writes a configuration file and stores it as a new version.
+// In case types is nil, the current attribute types are kept (and versioned).
+func (lcache *LiveAttrsBuildConfProvider) save(
+	data *vteconf.VTEConf,
+	types AttrTypes,
+	author string,
+	restoredFrom int,
+) (*ConfVersion, error) {
+	lcache.saveLock.Lock()
+	defer lcache.saveLock.Unlock()
+	prev, err := lcache.syncFileVersion(data.Corpus)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save vte conf file: %w", err)
+	}
+	var prevConf *vteconf.VTEConf
+	var prevTypes AttrTypes
+	if prev != nil {
+		prevConf = prev.Conf
+		prevTypes = prev.AttrTypes
+	}
+	if types == nil {
+		types, err = lcache.readAttrTypes(data.Corpus)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save vte conf file: %w", err)
+		}
+
+	} else if err := lcache.writeAttrTypes(data.Corpus, types); err != nil {
+		return nil, fmt.Errorf("failed to save vte conf file: %w", err)
+	}
+	diff, err := diffVersions(prevConf, prevTypes, data, types)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save vte conf file: %w", err)
+	}
 	rawData, err := json.MarshalIndent(data, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to save vte conf file: %w", err)
+		return nil, fmt.Errorf("failed to save vte conf file: %w", err)
 	}
-	confPath := filepath.Join(lcache.confDirPath, data.Corpus+".json")
-	err = os.WriteFile(confPath, rawData, 0777)
+	err = os.WriteFile(lcache.confPath(data.Corpus), rawData, 0777)
 	if err != nil {
-		return fmt.Errorf("failed to save vte conf file: %w", err)
+		return nil, fmt.Errorf("failed to save vte conf file: %w", err)
 	}
+	lcache.dataLock.Lock()
 	lcache.data[data.Corpus] = data
+	lcache.dataLock.Unlock()
 	if data.DB.Type == "mysql" {
 		data.DB = *lcache.globalDBConf
 	}
-	return nil
+	expConf := data.WithoutPasswords()
+	return lcache.storeVersion(
+		data.Corpus,
+		prev,
+		ConfVersion{
+			Created:      time.Now(),
+			Author:       author,
+			RestoredFrom: restoredFrom,
+			Diff:         diff,
+			Conf:         &expConf,
+			AttrTypes:    types,
+		},
+	)
+}
+
+// Save saves a provided configuration to a file for later use.
+// Each save is also stored as a new version of the configuration
+// (see History). The author identifies a user who made the change.
+func (lcache *LiveAttrsBuildConfProvider) Save(data *vteconf.VTEConf, author string) error {
+	_, err := lcache.save(data, nil, author, 0)
+	return err
+}
+
+// SaveWithAttrTypes saves a provided configuration along with attribute
+// types as a single new version. In case types is nil, the current
+// attribute types are kept.
+func (lcache *LiveAttrsBuildConfProvider) SaveWithAttrTypes(data *vteconf.VTEConf, types AttrTypes, author string) error {
+	_, err := lcache.save(data, types, author, 0)
+	return err
 }
 
 // Uncache removes item corpusID from cache and returns true if the item
 // was present. Otherwise does nothing and returns false.
 func (lcache *LiveAttrsBuildConfProvider) Uncache(corpusID string) bool {
+	lcache.dataLock.Lock()
+	defer lcache.dataLock.Unlock()
 	_, ok := lcache.data[corpusID]
 	delete(lcache.data, corpusID)
+	delete(lcache.attrTypes, corpusID)
 	return ok
 }
 
-// Clear removes a configuration from memory and from filesystem
+// Clear removes a configuration from memory and from filesystem.
+// Stored versions of the configuration are kept.
 func (lcache *LiveAttrsBuildConfProvider) Clear(corpusID string) error {
-	delete(lcache.data, corpusID)
-	confPath := path.Join(lcache.confDirPath, corpusID+".json")
+	lcache.Uncache(corpusID)
+	confPath := lcache.confPath(corpusID)
 	isFile, err := fs.IsFile(confPath)
 	if err != nil {
 		return err
 	}
 	if isFile {
-		return os.Remove(confPath)
+		if err := os.Remove(confPath); err != nil {
+			return err
+		}
+	}
+	if err := os.Remove(lcache.attrTypesPath(corpusID)); err != nil && !os.IsNotExist(err) {
+		return err
 	}
 	return nil
 }
@@ -268,5 +451,6 @@ func NewLiveAttrsBuildConfProvider(confDirPath string, globalDBConf *vtedb.Conf)
 		confDirPath:  confDirPath,
 		globalDBConf: globalDBConf,
 		data:         make(map[string]*vteconf.VTEConf),
+		attrTypes:    make(map[string]AttrTypes),
 	}
 }
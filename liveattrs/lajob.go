@@ -18,6 +18,7 @@ package liveattrs
 
 import (
 	"frodo/jobs"
+	"slices"
 	"time"
 
 	"github.com/czcorpus/mquery-common/corp"
@@ -28,12 +29,95 @@ const (
 	JobType = "liveattrs"
 )
 
+// UpdateArgs specifies an incremental update of existing liveattrs
+// data. Documents (identified by the bib. ID attribute) found in the
+// job's vertical files replace their current versions and documents
+// listed in DeleteIDs are removed.
+type UpdateArgs struct {
+	DeleteIDs []string `json:"deleteIds"`
+
+	// DeleteOrphaned allows removing rows of aligned corpora whose
+	// self-join items are no longer present in the updated corpus.
+	// Otherwise, such an update fails.
+	DeleteOrphaned bool `json:"deleteOrphaned,omitempty"`
+}
+
+// UpdateResult describes changes made by an incremental update
+type UpdateResult struct {
+
+	// NumUpserted is a number of documents found in the vertical files
+	NumUpserted int `json:"numUpserted"`
+
+	// NumReplaced is a number of rows replaced by the upserted documents
+	NumReplaced int `json:"numReplaced"`
+
+	// NumDeleted is a number of rows removed based on the DeleteIDs
+	NumDeleted int `json:"numDeleted"`
+
+	// OrphanedItemIDs contains self-join item IDs no longer present
+	// in the updated corpus while still used by some of its aligned
+	// corpora. Rows of such items are either removed (see
+	// UpdateArgs.DeleteOrphaned) or the update is rolled back.
+	OrphanedItemIDs []string `json:"orphanedItemIds,omitempty"`
+
+	// NumOrphanedDeleted is a number of removed rows of aligned
+	// corpora (see OrphanedItemIDs)
+	NumOrphanedDeleted int `json:"numOrphanedDeleted,omitempty"`
+
+	// OrphanedCorpora contains aligned corpora affected by removing
+	// the orphaned items
+	OrphanedCorpora []string `json:"orphanedCorpora,omitempty"`
+
+	// StaleColcounts is true in case the corpus has n-gram data
+	// configured. The [corpus]_colcounts table aggregates tokens
+	// of the whole corpus without any link to documents so it
+	// cannot be updated incrementally and a full rebuild is needed
+	// to refresh it (along with the n-grams derived from it).
+	StaleColcounts bool `json:"staleColcounts,omitempty"`
+}
+
+// SwapResult describes rebuilt data which replaced the current ones
+type SwapResult struct {
+
+	// PrevNumRows is a number of rows of the replaced data
+	// (zero if there were no data)
+	PrevNumRows int `json:"prevNumRows"`
+
+	PrevSizeBytes int64 `json:"prevSizeBytes"`
+
+	NumRows int `json:"numRows"`
+
+	SizeBytes int64 `json:"sizeBytes"`
+
+	// PrevTotalNumRows and TotalNumRows count rows of whole tables
+	// which can be shared by more aligned corpora (and which are
+	// swapped as a whole)
+	PrevTotalNumRows int `json:"prevTotalNumRows"`
+
+	TotalNumRows int `json:"totalNumRows"`
+
+	// PrevCorporaNumRows and CorporaNumRows contain numbers
+	// of rows of each corpus found in the tables
+	PrevCorporaNumRows map[string]int `json:"prevCorporaNumRows,omitempty"`
+
+	CorporaNumRows map[string]int `json:"corporaNumRows,omitempty"`
+}
+
 type JobInfoArgs struct {
 	Append           bool                 `json:"append"`
 	VteConf          vteCnf.VTEConf       `json:"vteConf"`
 	NoCorpusDBUpdate bool                 `json:"noCorpusDbUpdate"`
 	TagsetAttr       string               `json:"tagsetAttr"`
 	TagsetName       corp.SupportedTagset `json:"tagsetName"`
+
+	// Update, if set, turns the job into an incremental
+	// update of existing data
+	Update *UpdateArgs `json:"update,omitempty"`
+
+	// ConfVersion is a version of the stored configuration VteConf
+	// is derived from (VteConf may be further patched by request
+	// arguments)
+	ConfVersion int `json:"confVersion,omitempty"`
 }
 
 func (jargs JobInfoArgs) WithoutPasswords() JobInfoArgs {
@@ -44,19 +128,26 @@ func (jargs JobInfoArgs) WithoutPasswords() JobInfoArgs {
 
 // LiveAttrsJobInfo collects information about corpus data synchronization job
 type LiveAttrsJobInfo struct {
-	ID              string        `json:"id"`
-	Type            string        `json:"type"`
-	CorpusID        string        `json:"corpusId"`
-	AliasedCorpusID string        `json:"aliasedCorpusId"`
-	Start           jobs.JSONTime `json:"start"`
-	Update          jobs.JSONTime `json:"update"`
-	Finished        bool          `json:"finished"`
-	Error           error         `json:"error,omitempty"`
-	ProcessedAtoms  int           `json:"processedAtoms"`
-	ProcessedLines  int           `json:"processedLines"`
-	ProcessedTokens int           `json:"processedTokens"`
-	NumRestarts     int           `json:"numRestarts"`
-	Args            JobInfoArgs   `json:"args"`
+	ID              string            `json:"id"`
+	Type            string            `json:"type"`
+	CorpusID        string            `json:"corpusId"`
+	AliasedCorpusID string            `json:"aliasedCorpusId"`
+	Start           jobs.JSONTime     `json:"start"`
+	Update          jobs.JSONTime     `json:"update"`
+	Finished        bool              `json:"finished"`
+	Cancelled       bool              `json:"cancelled"`
+	Error           error             `json:"error,omitempty"`
+	ProcessedAtoms  int               `json:"processedAtoms"`
+	ProcessedLines  int               `json:"processedLines"`
+	ProcessedTokens int               `json:"processedTokens"`
+	NumRestarts     int               `json:"numRestarts"`
+	Attempts        []jobs.JobAttempt `json:"attempts,omitempty"`
+	Args            JobInfoArgs       `json:"args"`
+	UpdateResult    *UpdateResult     `json:"updateResult,omitempty"`
+	SwapResult      *SwapResult       `json:"swapResult,omitempty"`
+
+	// DataSwapped is set once the rebuilt data replace the current ones
+	DataSwapped bool `json:"dataSwapped,omitempty"`
 }
 
 func (j LiveAttrsJobInfo) GetID() string {
@@ -96,22 +187,76 @@ func (j LiveAttrsJobInfo) IsFinished() bool {
 	return j.Finished
 }
 
+func (j LiveAttrsJobInfo) GetProgress() jobs.JobProgress {
+	return jobs.JobProgress{
+		ProcessedLines:  j.ProcessedLines,
+		ProcessedTokens: j.ProcessedTokens,
+		ProcessedAtoms:  j.ProcessedAtoms,
+	}
+}
+
+// IsIdempotent tells whether the job can be run again without
+// duplicating data. Even in the append mode, data are written to shadow
+// tables (a copy of the current data) so a run failing before the tables
+// are swapped leaves the current data intact. But once the appended data
+// are swapped in, running the job again would append them once more.
+func (j LiveAttrsJobInfo) IsIdempotent() bool {
+	return !j.Args.Append || !j.DataSwapped
+}
+
+func (j LiveAttrsJobInfo) IsCancelled() bool {
+	return j.Cancelled
+}
+
+func (j LiveAttrsJobInfo) AsCancelled() jobs.GeneralJobInfo {
+	j.Update = jobs.CurrentDatetime()
+	j.Finished = true
+	j.Cancelled = true
+	j.Error = nil
+	return j
+}
+
+func (j LiveAttrsJobInfo) GetAttempts() []jobs.JobAttempt {
+	return j.Attempts
+}
+
+func (j LiveAttrsJobInfo) AsRetry() jobs.GeneralJobInfo {
+	j.Attempts = append(slices.Clone(j.Attempts), jobs.NewJobAttempt(j))
+	j.NumRestarts++
+	j.Start = jobs.CurrentDatetime()
+	j.Update = j.Start
+	j.Finished = false
+	j.Error = nil
+	return j
+}
+
+func (j LiveAttrsJobInfo) WithRetryState(prev jobs.GeneralJobInfo) jobs.GeneralJobInfo {
+	j.Start = prev.GetStartDT()
+	j.NumRestarts = prev.GetNumRestarts()
+	j.Attempts = prev.GetAttempts()
+	return j
+}
+
 func (j LiveAttrsJobInfo) FullInfo() any {
 	return struct {
-		ID              string        `json:"id"`
-		Type            string        `json:"type"`
-		CorpusID        string        `json:"corpusId"`
-		AliasedCorpusID string        `json:"aliasedCorpusId"`
-		Start           jobs.JSONTime `json:"start"`
-		Update          jobs.JSONTime `json:"update"`
-		Finished        bool          `json:"finished"`
-		Error           string        `json:"error,omitempty"`
-		OK              bool          `json:"ok"`
-		ProcessedAtoms  int           `json:"processedAtoms"`
-		ProcessedLines  int           `json:"processedLines"`
-		ProcessedTokens int           `json:"processedTokens"`
-		NumRestarts     int           `json:"numRestarts"`
-		Args            JobInfoArgs   `json:"args"`
+		ID              string            `json:"id"`
+		Type            string            `json:"type"`
+		CorpusID        string            `json:"corpusId"`
+		AliasedCorpusID string            `json:"aliasedCorpusId"`
+		Start           jobs.JSONTime     `json:"start"`
+		Update          jobs.JSONTime     `json:"update"`
+		Finished        bool              `json:"finished"`
+		Cancelled       bool              `json:"cancelled"`
+		Error           string            `json:"error,omitempty"`
+		OK              bool              `json:"ok"`
+		ProcessedAtoms  int               `json:"processedAtoms"`
+		ProcessedLines  int               `json:"processedLines"`
+		ProcessedTokens int               `json:"processedTokens"`
+		NumRestarts     int               `json:"numRestarts"`
+		Attempts        []jobs.JobAttempt `json:"attempts,omitempty"`
+		Args            JobInfoArgs       `json:"args"`
+		UpdateResult    *UpdateResult     `json:"updateResult,omitempty"`
+		SwapResult      *SwapResult       `json:"swapResult,omitempty"`
 	}{
 		ID:              j.ID,
 		Type:            j.Type,
@@ -119,13 +264,17 @@ func (j LiveAttrsJobInfo) FullInfo() any {
 		Start:           j.Start,
 		Update:          j.Update,
 		Finished:        j.Finished,
+		Cancelled:       j.Cancelled,
 		Error:           jobs.ErrorToString(j.Error),
-		OK:              j.Error == nil,
+		OK:              j.Error == nil && !j.Cancelled,
 		ProcessedAtoms:  j.ProcessedAtoms,
 		ProcessedLines:  j.ProcessedLines,
 		ProcessedTokens: j.ProcessedTokens,
 		NumRestarts:     j.NumRestarts,
+		Attempts:        j.Attempts,
 		Args:            j.Args.WithoutPasswords(),
+		UpdateResult:    j.UpdateResult,
+		SwapResult:      j.SwapResult,
 	}
 }
 
@@ -138,9 +287,10 @@ func (j LiveAttrsJobInfo) CompactVersion() jobs.JobInfoCompact {
 		Start:           j.Start,
 		Update:          j.Update,
 		Finished:        j.Finished,
+		Cancelled:       j.Cancelled,
 		OK:              true,
 	}
-	item.OK = j.Error == nil
+	item.OK = j.Error == nil && !j.Cancelled
 	return item
 }
 
@@ -160,7 +310,10 @@ func (j LiveAttrsJobInfo) WithError(err error) jobs.GeneralJobInfo {
 		Update:          jobs.JSONTime(time.Now()),
 		Error:           err,
 		NumRestarts:     j.NumRestarts,
+		Attempts:        j.Attempts,
 		Args:            j.Args,
+		UpdateResult:    j.UpdateResult,
+		SwapResult:      j.SwapResult,
 		Finished:        true,
 	}
 }
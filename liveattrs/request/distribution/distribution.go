@@ -0,0 +1,62 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package distribution
+
+import (
+	"errors"
+	"fmt"
+	"frodo/liveattrs/request/query"
+)
+
+var (
+	ErrorMissingAttrs    = errors.New("no attributes to calculate distribution for")
+	ErrorInvalidCrossTab = errors.New("cross-tabulation requires exactly two attributes")
+)
+
+// Payload specifies a text type distribution request
+type Payload struct {
+
+	// Attrs is a text type selection the distribution is calculated for.
+	// The format is the same as for the liveattrs query.
+	Attrs query.Attrs `json:"attrs"`
+
+	Aligned []string `json:"aligned"`
+
+	// Groups contains structural attributes (e.g. doc.genre)
+	// to calculate the distribution of values for
+	Groups []string `json:"groups"`
+
+	// CrossTab enables cross-tabulation of the two attributes
+	// specified in Groups
+	CrossTab bool `json:"crossTab"`
+}
+
+func (p *Payload) Validate() error {
+	if len(p.Groups) == 0 {
+		return ErrorMissingAttrs
+	}
+	if p.CrossTab && len(p.Groups) != 2 {
+		return ErrorInvalidCrossTab
+	}
+	found := make(map[string]bool)
+	for _, attr := range p.Groups {
+		if found[attr] {
+			return fmt.Errorf("duplicate distribution attribute %s", attr)
+		}
+		found[attr] = true
+	}
+	return nil
+}
@@ -22,4 +22,8 @@ type Payload struct {
 	Corpname string      `json:"corpname"`
 	Aligned  []string    `json:"aligned"`
 	Attrs    query.Attrs `json:"attrs"`
+
+	// Filter is an optional boolean expression applied
+	// along with Attrs
+	Filter *query.Filter `json:"filter,omitempty"`
 }
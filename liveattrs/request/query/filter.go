@@ -0,0 +1,198 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const (
+	FilterOpAnd    = "and"
+	FilterOpOr     = "or"
+	FilterOpNot    = "not"
+	FilterOpEq     = "eq"
+	FilterOpIn     = "in"
+	FilterOpRegexp = "regexp"
+	FilterOpPrefix = "prefix"
+	FilterOpRange  = "range"
+
+	RangeTypeNumber = "number"
+	RangeTypeDate   = "date"
+
+	// RangeDateLayout is the only accepted format of date range bounds
+	RangeDateLayout = "2006-01-02"
+
+	maxFilterDepth = 32
+)
+
+var (
+	ErrorInvalidFilter = errors.New("invalid filter expression")
+
+	filterAttrRegexp = regexp.MustCompile(`^[a-zA-Z0-9_]+\.[a-zA-Z0-9_]+$`)
+)
+
+// Bound is a range bound which can be written either
+// as a JSON number or a string
+type Bound string
+
+func (b *Bound) UnmarshalJSON(data []byte) error {
+	var num json.Number
+	if err := json.Unmarshal(data, &num); err == nil {
+		*b = Bound(num.String())
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("range bound must be a number or a string: %w", err)
+	}
+	*b = Bound(s)
+	return nil
+}
+
+// Filter is a node of a boolean filter expression over structural
+// attributes. Inner nodes ("and", "or", "not") combine expressions
+// in Args, leaf nodes test a single attribute:
+//
+//   - eq: Attr = Value
+//   - in: Attr is one of Values
+//   - regexp: Attr matches the regular expression Value
+//   - prefix: Attr starts with Value
+//   - range: Attr is within Gt/Gte and Lt/Lte bounds, compared
+//     as numbers or dates (Type)
+//
+// E.g. "(genre=fiction AND year>2000) OR author=X" can be written as:
+//
+//	{"op": "or", "args": [
+//	  {"op": "and", "args": [
+//	    {"op": "eq", "attr": "doc.genre", "value": "fiction"},
+//	    {"op": "range", "attr": "doc.year", "gt": 2000}]},
+//	  {"op": "eq", "attr": "doc.author", "value": "X"}]}
+type Filter struct {
+	Op     string    `json:"op"`
+	Args   []*Filter `json:"args,omitempty"`
+	Attr   string    `json:"attr,omitempty"`
+	Value  string    `json:"value,omitempty"`
+	Values []string  `json:"values,omitempty"`
+	Gt     *Bound    `json:"gt,omitempty"`
+	Gte    *Bound    `json:"gte,omitempty"`
+	Lt     *Bound    `json:"lt,omitempty"`
+	Lte    *Bound    `json:"lte,omitempty"`
+
+	// Type specifies how range bounds are compared.
+	// It is either "number" (default) or "date".
+	Type string `json:"type,omitempty"`
+}
+
+func (f *Filter) RangeType() string {
+	if f.Type == "" {
+		return RangeTypeNumber
+	}
+	return f.Type
+}
+
+func (f *Filter) invalid(msg string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrorInvalidFilter, fmt.Sprintf(msg, args...))
+}
+
+func (f *Filter) validateBound(b *Bound) error {
+	if b == nil {
+		return nil
+	}
+	switch f.RangeType() {
+	case RangeTypeNumber:
+		if _, err := strconv.ParseFloat(string(*b), 64); err != nil {
+			return f.invalid("range bound %s of %s is not a number", *b, f.Attr)
+		}
+	case RangeTypeDate:
+		if _, err := time.Parse(RangeDateLayout, string(*b)); err != nil {
+			return f.invalid("range bound %s of %s is not a date (YYYY-MM-DD)", *b, f.Attr)
+		}
+	default:
+		return f.invalid("unknown range type %s", f.Type)
+	}
+	return nil
+}
+
+func (f *Filter) validate(depth int) error {
+	if depth > maxFilterDepth {
+		return f.invalid("expression nested too deep")
+	}
+	switch f.Op {
+	case FilterOpAnd, FilterOpOr:
+		if len(f.Args) == 0 {
+			return f.invalid("operator %s requires at least one argument", f.Op)
+		}
+	case FilterOpNot:
+		if len(f.Args) != 1 {
+			return f.invalid("operator not requires exactly one argument")
+		}
+	case FilterOpEq, FilterOpIn, FilterOpRegexp, FilterOpPrefix, FilterOpRange:
+		if !filterAttrRegexp.MatchString(f.Attr) {
+			return f.invalid("invalid attribute name '%s'", f.Attr)
+		}
+		if len(f.Args) > 0 {
+			return f.invalid("predicate %s cannot have arguments", f.Op)
+		}
+	default:
+		return f.invalid("unknown operator '%s'", f.Op)
+	}
+	switch f.Op {
+	case FilterOpIn:
+		if len(f.Values) == 0 {
+			return f.invalid("predicate in requires at least one value")
+		}
+	case FilterOpRegexp:
+		if f.Value == "" {
+			return f.invalid("predicate regexp requires a value")
+		}
+	case FilterOpRange:
+		if f.Gt != nil && f.Gte != nil || f.Lt != nil && f.Lte != nil {
+			return f.invalid("range of %s has conflicting bounds", f.Attr)
+		}
+		if f.Gt == nil && f.Gte == nil && f.Lt == nil && f.Lte == nil {
+			return f.invalid("range of %s has no bounds", f.Attr)
+		}
+		for _, b := range []*Bound{f.Gt, f.Gte, f.Lt, f.Lte} {
+			if err := f.validateBound(b); err != nil {
+				return err
+			}
+		}
+	}
+	for _, arg := range f.Args {
+		if arg == nil {
+			return f.invalid("missing argument of %s", f.Op)
+		}
+		if err := arg.validate(depth + 1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate tests whether the expression is complete and whether all
+// the attribute names and values can be safely used in an SQL query.
+// A nil filter is valid.
+func (f *Filter) Validate() error {
+	if f == nil {
+		return nil
+	}
+	return f.validate(0)
+}
@@ -18,6 +18,7 @@ package query
 
 import (
 	"fmt"
+	"strconv"
 )
 
 // Attrs represents a user selection of text types
@@ -50,6 +51,44 @@ func (q Attrs) GetRegexpAttrVal(attr string) (string, bool) {
 	return "", false
 }
 
+// GetRangeAttrVal tries to extract a range (i.e. a value like
+// {"from": 1990, "to": 2000}) from Attrs under the 'attr' key.
+// Both bounds are optional (an empty string is returned for
+// a missing one). In case there is no range stored in q[attr],
+// false is returned as the third value.
+func (q Attrs) GetRangeAttrVal(attr string) (string, string, bool) {
+	v, ok := q[attr]
+	if !ok {
+		v, ok = q["!"+attr]
+		if !ok {
+			return "", "", false
+		}
+	}
+	tm, ok := v.(map[string]any)
+	if !ok {
+		return "", "", false
+	}
+	from, hasFrom := tm["from"]
+	to, hasTo := tm["to"]
+	if !hasFrom && !hasTo {
+		return "", "", false
+	}
+	return rangeBound(from), rangeBound(to), true
+}
+
+func rangeBound(v any) string {
+	switch tv := v.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(tv, 'f', -1, 64)
+	case string:
+		return tv
+	default:
+		return fmt.Sprintf("%v", tv)
+	}
+}
+
 // GetListingOf returns a list of strings (= selected values) for
 // a specified attribute. In case the attribute is not represented
 // by a value listing (like e.g. in case of range values), the function
@@ -92,8 +131,18 @@ type Payload struct {
 	AutocompleteAttr string   `json:"autocompleteAttr"`
 	MaxAttrListSize  int      `json:"maxAttrListSize"`
 
+	// Filter is an optional boolean expression applied
+	// along with Attrs (i.e. both must match)
+	Filter *Filter `json:"filter,omitempty"`
+
 	// ApplyCutoff, if set true, then in case a result returns more than MaxAttrListSize,
 	// the list is cut to the MaxAttrListSize and the response is behaving like there
 	// is no problem with too much matching items
 	ApplyCutoff bool `json:"applyCutoff"`
 }
+
+// HasSelection tells whether the query restricts
+// the text types in any way
+func (p *Payload) HasSelection() bool {
+	return len(p.Attrs) > 0 || p.Filter != nil
+}
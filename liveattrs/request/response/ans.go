@@ -37,6 +37,22 @@ type SummarizedValue struct {
 	Length int `json:"length"`
 }
 
+// RangeValue summarizes values of a numeric attribute.
+// Min and Max are nil in case there are no valid values.
+type RangeValue struct {
+	Min *float64 `json:"min"`
+	Max *float64 `json:"max"`
+}
+
+func (rv *RangeValue) Add(v float64) {
+	if rv.Min == nil || v < *rv.Min {
+		rv.Min = &v
+	}
+	if rv.Max == nil || v > *rv.Max {
+		rv.Max = &v
+	}
+}
+
 type QueryAns struct {
 	Poscount       int
 	AttrValues     map[string]any
@@ -0,0 +1,172 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package response
+
+import (
+	"sort"
+)
+
+// DistCounts contains absolute and relative sizes of a group of
+// liveattrs entries (typically documents) sharing some attribute values
+type DistCounts struct {
+	Tokens    int     `json:"tokens"`
+	Docs      int     `json:"docs"`
+	TokensRel float64 `json:"tokensRel"`
+	DocsRel   float64 `json:"docsRel"`
+}
+
+func (dc *DistCounts) setRel(totalTokens, totalDocs int) {
+	if totalTokens > 0 {
+		dc.TokensRel = float64(dc.Tokens) / float64(totalTokens)
+	}
+	if totalDocs > 0 {
+		dc.DocsRel = float64(dc.Docs) / float64(totalDocs)
+	}
+}
+
+type DistItem struct {
+	Value string `json:"value"`
+	DistCounts
+}
+
+type CrossTabItem struct {
+	Values [2]string `json:"values"`
+	DistCounts
+}
+
+type CrossTab struct {
+	Attrs [2]string       `json:"attrs"`
+	Items []*CrossTabItem `json:"items"`
+}
+
+// Distribution is a frequency distribution of text type values
+// within a selection of liveattrs entries. Items are sorted by
+// token count in descending order.
+type Distribution struct {
+	Tokens   int                    `json:"tokens"`
+	Docs     int                    `json:"docs"`
+	Attrs    map[string][]*DistItem `json:"attrs"`
+	CrossTab *CrossTab              `json:"crossTab,omitempty"`
+}
+
+// DistributionBuilder accumulates liveattrs entries into
+// a Distribution
+type DistributionBuilder struct {
+	attrs       []string
+	crossTab    bool
+	emptyVal    string
+	tokens      int
+	docs        int
+	attrCounts  map[string]map[string]*DistCounts
+	crossCounts map[[2]string]*DistCounts
+}
+
+func (b *DistributionBuilder) incr(counts *DistCounts, poscount int) {
+	counts.Tokens += poscount
+	counts.Docs++
+}
+
+// Add adds a single liveattrs entry with its attribute values.
+// Missing values are counted as the empty value placeholder.
+func (b *DistributionBuilder) Add(values map[string]string, poscount int) {
+	b.tokens += poscount
+	b.docs++
+	for _, attr := range b.attrs {
+		v := b.value(values, attr)
+		counts, ok := b.attrCounts[attr][v]
+		if !ok {
+			counts = &DistCounts{}
+			b.attrCounts[attr][v] = counts
+		}
+		b.incr(counts, poscount)
+	}
+	if b.crossTab {
+		key := [2]string{b.value(values, b.attrs[0]), b.value(values, b.attrs[1])}
+		counts, ok := b.crossCounts[key]
+		if !ok {
+			counts = &DistCounts{}
+			b.crossCounts[key] = counts
+		}
+		b.incr(counts, poscount)
+	}
+}
+
+func (b *DistributionBuilder) value(values map[string]string, attr string) string {
+	v, ok := values[attr]
+	if !ok || v == "" {
+		return b.emptyVal
+	}
+	return v
+}
+
+func (b *DistributionBuilder) Result() *Distribution {
+	ans := &Distribution{
+		Tokens: b.tokens,
+		Docs:   b.docs,
+		Attrs:  make(map[string][]*DistItem),
+	}
+	for attr, counts := range b.attrCounts {
+		items := make([]*DistItem, 0, len(counts))
+		for v, c := range counts {
+			c.setRel(b.tokens, b.docs)
+			items = append(items, &DistItem{Value: v, DistCounts: *c})
+		}
+		sort.Slice(items, func(i, j int) bool {
+			if items[i].Tokens == items[j].Tokens {
+				return items[i].Value < items[j].Value
+			}
+			return items[i].Tokens > items[j].Tokens
+		})
+		ans.Attrs[attr] = items
+	}
+	if b.crossTab {
+		items := make([]*CrossTabItem, 0, len(b.crossCounts))
+		for v, c := range b.crossCounts {
+			c.setRel(b.tokens, b.docs)
+			items = append(items, &CrossTabItem{Values: v, DistCounts: *c})
+		}
+		sort.Slice(items, func(i, j int) bool {
+			if items[i].Tokens == items[j].Tokens {
+				if items[i].Values[0] == items[j].Values[0] {
+					return items[i].Values[1] < items[j].Values[1]
+				}
+				return items[i].Values[0] < items[j].Values[0]
+			}
+			return items[i].Tokens > items[j].Tokens
+		})
+		ans.CrossTab = &CrossTab{
+			Attrs: [2]string{b.attrs[0], b.attrs[1]},
+			Items: items,
+		}
+	}
+	return ans
+}
+
+// NewDistributionBuilder creates a builder for provided attributes.
+// In case of crossTab, exactly two attributes are expected.
+func NewDistributionBuilder(attrs []string, crossTab bool, emptyVal string) *DistributionBuilder {
+	ans := &DistributionBuilder{
+		attrs:       attrs,
+		crossTab:    crossTab && len(attrs) == 2,
+		emptyVal:    emptyVal,
+		attrCounts:  make(map[string]map[string]*DistCounts),
+		crossCounts: make(map[[2]string]*DistCounts),
+	}
+	for _, attr := range attrs {
+		ans.attrCounts[attr] = make(map[string]*DistCounts)
+	}
+	return ans
+}
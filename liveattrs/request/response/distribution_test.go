@@ -0,0 +1,56 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package response
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistributionBuilderCounts(t *testing.T) {
+	b := NewDistributionBuilder([]string{"doc.genre", "doc.year"}, true, "?")
+	b.Add(map[string]string{"doc.genre": "fiction", "doc.year": "2001"}, 100)
+	b.Add(map[string]string{"doc.genre": "fiction", "doc.year": "2002"}, 200)
+	b.Add(map[string]string{"doc.genre": "poetry", "doc.year": "2001"}, 50)
+	b.Add(map[string]string{"doc.year": "2001"}, 50)
+	ans := b.Result()
+
+	assert.Equal(t, 400, ans.Tokens)
+	assert.Equal(t, 4, ans.Docs)
+	genres := ans.Attrs["doc.genre"]
+	assert.Len(t, genres, 3)
+	assert.Equal(t, "fiction", genres[0].Value)
+	assert.Equal(t, 300, genres[0].Tokens)
+	assert.Equal(t, 2, genres[0].Docs)
+	assert.InDelta(t, 0.75, genres[0].TokensRel, 1e-9)
+	assert.InDelta(t, 0.5, genres[0].DocsRel, 1e-9)
+	assert.Equal(t, "?", genres[1].Value)
+	assert.Equal(t, "poetry", genres[2].Value)
+
+	assert.Equal(t, [2]string{"doc.genre", "doc.year"}, ans.CrossTab.Attrs)
+	assert.Len(t, ans.CrossTab.Items, 4)
+	assert.Equal(t, [2]string{"fiction", "2002"}, ans.CrossTab.Items[0].Values)
+	assert.InDelta(t, 0.5, ans.CrossTab.Items[0].TokensRel, 1e-9)
+}
+
+func TestDistributionBuilderWithoutCrossTab(t *testing.T) {
+	b := NewDistributionBuilder([]string{"doc.genre"}, false, "?")
+	ans := b.Result()
+	assert.Nil(t, ans.CrossTab)
+	assert.Empty(t, ans.Attrs["doc.genre"])
+	assert.Equal(t, 0, ans.Tokens)
+}
@@ -0,0 +1,281 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package subcdef renders text type selections and document lists
+// as Manatee subcorpus definitions (the format accepted by mksubc):
+//
+//	=name
+//	structure
+//	condition
+package subcdef
+
+import (
+	"errors"
+	"fmt"
+	"frodo/liveattrs/request/query"
+	"slices"
+	"strings"
+)
+
+const (
+	// DfltMaxListSize is a default maximum number of items
+	// in a single value list of a compacted condition
+	DfltMaxListSize = 500
+)
+
+var (
+	ErrorNotExpressible = errors.New("selection cannot be expressed as a structure condition")
+	ErrorEmptySelection = errors.New("empty selection")
+	ErrorInvalidName    = errors.New("invalid subcorpus name")
+)
+
+// Definition is a single Manatee subcorpus definition
+type Definition struct {
+	Name      string
+	Structure string
+
+	// Condition is a CQL-like within condition applied
+	// on the structure (e.g. genre="fiction" & year="2000")
+	Condition string
+}
+
+// String renders the definition in the subcdef file format
+func (d Definition) String() string {
+	return fmt.Sprintf("=%s\n%s\n%s\n", d.Name, d.Structure, d.Condition)
+}
+
+// ValidateName checks whether the name can be used
+// as a subcorpus name in a definition file
+func ValidateName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("%w: empty name", ErrorInvalidName)
+	}
+	if strings.ContainsAny(name, "\r\n") || strings.HasPrefix(name, "=") {
+		return fmt.Errorf("%w: %s", ErrorInvalidName, name)
+	}
+	return nil
+}
+
+// escapeRegexp escapes a literal value so it can be used
+// within a (regexp-based) Manatee attribute value
+func escapeRegexp(v string) string {
+	var ans strings.Builder
+	for _, c := range v {
+		if strings.ContainsRune(`\.[](){}*+?|^$"`, c) {
+			ans.WriteRune('\\')
+		}
+		ans.WriteRune(c)
+	}
+	return ans.String()
+}
+
+// escapeQuotes escapes a user-provided regexp so it does not break
+// the surrounding quotes
+func escapeQuotes(v string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(v, `\"`, `"`), `"`, `\"`)
+}
+
+// splitAttr splits a "struct.attr" name. In case of a negated
+// attribute ("!struct.attr"), the third value is true.
+func splitAttr(name string) (string, string, bool, error) {
+	negated := strings.HasPrefix(name, "!")
+	items := strings.Split(strings.TrimPrefix(name, "!"), ".")
+	if len(items) != 2 || items[0] == "" || items[1] == "" {
+		return "", "", false, fmt.Errorf("invalid attribute name %s", name)
+	}
+	return items[0], items[1], negated, nil
+}
+
+// FromSelection creates a definition based on a text type selection.
+// All the selected attributes must belong to a single structure and
+// only value listings and regular expressions are supported (ranges
+// cannot be expressed). Listed values liveattrs does not compare literally
+// (LIKE patterns containing "%" and "@"-prefixed bib. labels) are not
+// supported either. Otherwise, ErrorNotExpressible is returned and
+// the caller should use a document list (see FromDocIDs) instead.
+func FromSelection(name string, attrs query.Attrs) (Definition, error) {
+	if len(attrs) == 0 {
+		return Definition{}, ErrorEmptySelection
+	}
+	attrNames := make([]string, 0, len(attrs))
+	for k := range attrs {
+		attrNames = append(attrNames, k)
+	}
+	slices.SortFunc(attrNames, func(a, b string) int {
+		return strings.Compare(strings.TrimPrefix(a, "!"), strings.TrimPrefix(b, "!"))
+	})
+	var structure string
+	conditions := make([]string, 0, len(attrNames))
+	for _, k := range attrNames {
+		strct, attr, negated, err := splitAttr(k)
+		if err != nil {
+			return Definition{}, err
+		}
+		if structure != "" && strct != structure {
+			return Definition{}, fmt.Errorf(
+				"%w: attributes of multiple structures (%s, %s)", ErrorNotExpressible, structure, strct)
+		}
+		structure = strct
+		op := "="
+		if negated {
+			op = "!="
+		}
+		if _, _, ok := attrs.GetRangeAttrVal(k); ok {
+			return Definition{}, fmt.Errorf("%w: range value of %s", ErrorNotExpressible, k)
+		}
+		var value string
+		if rgx, ok := attrs.GetRegexpAttrVal(k); ok {
+			value = escapeQuotes(rgx)
+
+		} else {
+			values, err := attrs.GetListingOf(k)
+			if err != nil {
+				return Definition{}, fmt.Errorf("%w: %s", ErrorNotExpressible, err)
+			}
+			if len(values) == 0 {
+				continue
+			}
+			escaped := make([]string, len(values))
+			for i, v := range values {
+				if !isLiteralValue(v) {
+					return Definition{}, fmt.Errorf(
+						"%w: non-literal value %s of %s", ErrorNotExpressible, v, k)
+				}
+				escaped[i] = escapeRegexp(v)
+			}
+			value = strings.Join(escaped, "|")
+		}
+		conditions = append(conditions, fmt.Sprintf("%s%s\"%s\"", attr, op, value))
+	}
+	if len(conditions) == 0 {
+		return Definition{}, ErrorEmptySelection
+	}
+	return Definition{
+		Name:      name,
+		Structure: structure,
+		Condition: strings.Join(conditions, " & "),
+	}, nil
+}
+
+// isLiteralValue tests whether liveattrs matches a selected value
+// literally. Values containing "%" are used as LIKE patterns (see
+// qbuilder.CmpOperator) and values starting with "@" match bib. labels
+// instead of the attribute itself.
+func isLiteralValue(v string) bool {
+	return !strings.Contains(v, "%") && !strings.HasPrefix(v, "@")
+}
+
+// FromDocIDs creates a definition based on a list of document IDs
+// (values of bibIDAttr, e.g. doc.id). With compact set to true, the IDs
+// are merged into value lists of at most maxListSize items (see CompactIDs).
+// Otherwise, each document gets its own condition.
+func FromDocIDs(name, bibIDAttr string, docIDs []string, compact bool, maxListSize int) (Definition, error) {
+	if len(docIDs) == 0 {
+		return Definition{}, ErrorEmptySelection
+	}
+	strct, attr, _, err := splitAttr(bibIDAttr)
+	if err != nil {
+		return Definition{}, err
+	}
+	var conditions []string
+	if compact {
+		patterns := CompactIDs(docIDs)
+		if maxListSize <= 0 {
+			maxListSize = DfltMaxListSize
+		}
+		for chunk := range slices.Chunk(patterns, maxListSize) {
+			conditions = append(
+				conditions, fmt.Sprintf("%s=\"%s\"", attr, strings.Join(chunk, "|")))
+		}
+
+	} else {
+		conditions = make([]string, len(docIDs))
+		for i, docID := range docIDs {
+			conditions[i] = fmt.Sprintf("%s=\"%s\"", attr, escapeRegexp(docID))
+		}
+	}
+	return Definition{
+		Name:      name,
+		Structure: strct,
+		Condition: strings.Join(conditions, " | "),
+	}, nil
+}
+
+// CompactIDs sorts and deduplicates document IDs and merges contiguous
+// sets of IDs differing only in their last digit into a single pattern
+// (e.g. doc10, doc11, doc12, doc15 => doc1[0-2], doc15). The returned
+// values are escaped and ready to be used in a condition. Their order
+// is deterministic (sorted by the common prefix).
+func CompactIDs(docIDs []string) []string {
+	// prefix => set of trailing digits (bit i = digit i)
+	digits := make(map[string]uint16)
+	keys := make([]string, 0, len(docIDs))
+	for _, docID := range docIDs {
+		prefix, digit, ok := splitLastDigit(docID)
+		if !ok {
+			// "literal" values are stored with a key which
+			// cannot collide with a prefix
+			prefix, digit = docID+"\x00", 0
+		}
+		if _, found := digits[prefix]; !found {
+			keys = append(keys, prefix)
+		}
+		digits[prefix] |= 1 << digit
+	}
+	slices.Sort(keys)
+	ans := make([]string, 0, len(keys))
+	for _, prefix := range keys {
+		if literal, ok := strings.CutSuffix(prefix, "\x00"); ok {
+			ans = append(ans, escapeRegexp(literal))
+			continue
+		}
+		set := digits[prefix]
+		for d := 0; d < 10; {
+			if set&(1<<d) == 0 {
+				d++
+				continue
+			}
+			last := d
+			for last+1 < 10 && set&(1<<(last+1)) != 0 {
+				last++
+			}
+			switch last - d {
+			case 0:
+				ans = append(ans, escapeRegexp(prefix)+string(rune('0'+d)))
+			case 1:
+				ans = append(
+					ans,
+					escapeRegexp(prefix)+string(rune('0'+d)),
+					escapeRegexp(prefix)+string(rune('0'+last)),
+				)
+			default:
+				ans = append(ans, fmt.Sprintf("%s[%d-%d]", escapeRegexp(prefix), d, last))
+			}
+			d = last + 1
+		}
+	}
+	return ans
+}
+
+func splitLastDigit(v string) (string, int, bool) {
+	if v == "" {
+		return "", 0, false
+	}
+	last := v[len(v)-1]
+	if last < '0' || last > '9' {
+		return "", 0, false
+	}
+	return v[:len(v)-1], int(last - '0'), true
+}
@@ -0,0 +1,75 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcdef
+
+import (
+	"frodo/liveattrs/request/query"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromSelection(t *testing.T) {
+	def, err := FromSelection("fict", query.Attrs{
+		"doc.genre":  []any{"fiction", "poetry (old)"},
+		"!doc.year":  "2000",
+		"doc.author": map[string]any{"regexp": `Ča.*"x`},
+	})
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		"=fict\ndoc\nauthor=\"Ča.*\\\"x\" & genre=\"fiction|poetry \\(old\\)\" & year!=\"2000\"\n",
+		def.String(),
+	)
+}
+
+func TestFromSelectionNotExpressible(t *testing.T) {
+	_, err := FromSelection("x", query.Attrs{"doc.genre": "fiction", "text.type": "a"})
+	assert.ErrorIs(t, err, ErrorNotExpressible)
+	_, err = FromSelection("x", query.Attrs{"doc.year": map[string]any{"from": 1990.0}})
+	assert.ErrorIs(t, err, ErrorNotExpressible)
+}
+
+func TestFromSelectionLikePattern(t *testing.T) {
+	_, err := FromSelection("x", query.Attrs{"doc.title": []any{"Harry%"}})
+	assert.ErrorIs(t, err, ErrorNotExpressible)
+	_, err = FromSelection("x", query.Attrs{"!doc.title": "%Potter"})
+	assert.ErrorIs(t, err, ErrorNotExpressible)
+}
+
+func TestFromSelectionBibLabel(t *testing.T) {
+	_, err := FromSelection("x", query.Attrs{"doc.id": []any{"d1", "@Harry Potter"}})
+	assert.ErrorIs(t, err, ErrorNotExpressible)
+	def, err := FromSelection("x", query.Attrs{"doc.title": []any{"a@b"}})
+	assert.NoError(t, err)
+	assert.Equal(t, `title="a@b"`, def.Condition)
+}
+
+func TestCompactIDs(t *testing.T) {
+	ids := []string{"doc12", "doc2", "doc10", "doc11", "doc1", "doc3", "doc15", "doc16", "x.y", "doc11"}
+	assert.Equal(
+		t,
+		[]string{"doc[1-3]", "doc1[0-2]", "doc15", "doc16", `x\.y`},
+		CompactIDs(ids),
+	)
+}
+
+func TestFromDocIDsCompact(t *testing.T) {
+	def, err := FromDocIDs("s", "doc.id", []string{"a1", "a2", "a3", "b", "c"}, true, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "doc", def.Structure)
+	assert.Equal(t, `id="a[1-3]|b" | id="c"`, def.Condition)
+}
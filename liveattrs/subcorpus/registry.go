@@ -0,0 +1,357 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcorpus
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"frodo/corpus"
+	"frodo/liveattrs/utils"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/google/uuid"
+)
+
+const (
+	docInsertBatchSize = 1000
+	duplicateRowErrNo  = 1062
+
+	subcorpusCols = "id, name, owner, corpus_id, kind, definition, size, num_docs, " +
+		"created, updated, last_diff"
+)
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// Registry stores subcorpora in the `subcorpus` and `subcorpus_doc`
+// tables of the liveattrs database (see scripts/install.sql)
+type Registry struct {
+	db *sql.DB
+}
+
+func (r *Registry) scanSubcorpus(row rowScanner) (*Subcorpus, error) {
+	var ans Subcorpus
+	var definition string
+	var lastDiff sql.NullString
+	err := row.Scan(
+		&ans.ID, &ans.Name, &ans.Owner, &ans.CorpusID, &ans.Kind, &definition,
+		&ans.Size, &ans.NumDocs, &ans.Created, &ans.Updated, &lastDiff,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(definition), &ans.Definition); err != nil {
+		return nil, fmt.Errorf("invalid definition of subcorpus %s: %w", ans.ID, err)
+	}
+	if lastDiff.Valid {
+		ans.LastDiff = &MembershipDiff{}
+		if err := json.Unmarshal([]byte(lastDiff.String), ans.LastDiff); err != nil {
+			return nil, fmt.Errorf("invalid diff report of subcorpus %s: %w", ans.ID, err)
+		}
+	}
+	return &ans, nil
+}
+
+func (r *Registry) insertDocs(tx *sql.Tx, subcID string, docIDs []string) error {
+	for i := 0; i < len(docIDs); i += docInsertBatchSize {
+		batch := docIDs[i:min(i+docInsertBatchSize, len(docIDs))]
+		args := make([]any, 0, 2*len(batch))
+		for _, docID := range batch {
+			args = append(args, subcID, docID)
+		}
+		_, err := tx.Exec(
+			"INSERT INTO subcorpus_doc (subcorpus_id, doc_id) VALUES "+
+				strings.TrimSuffix(strings.Repeat("(?, ?), ", len(batch)), ", "),
+			args...,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateSize calculates a number of tokens of the frozen documents
+// actually present in liveattrs data
+func (r *Registry) updateSize(tx *sql.Tx, corpusInfo *corpus.DBInfo, subcID string) error {
+	_, err := tx.Exec(
+		fmt.Sprintf(
+			"UPDATE subcorpus SET size = ("+
+				"SELECT COALESCE(SUM(t1.poscount), 0) FROM `%s_liveattrs_entry` AS t1 "+
+				"JOIN subcorpus_doc AS sd ON sd.doc_id = t1.%s "+
+				"WHERE sd.subcorpus_id = ? AND t1.corpus_id = ?) "+
+				"WHERE id = ?",
+			corpusInfo.GroupedName(), utils.ImportKey(corpusInfo.BibIDAttr),
+		),
+		subcID, corpusInfo.Name, subcID,
+	)
+	return err
+}
+
+func uniqueDocIDs(docIDs []string) []string {
+	ans := make([]string, 0, len(docIDs))
+	found := make(map[string]bool, len(docIDs))
+	for _, v := range docIDs {
+		if !found[v] {
+			ans = append(ans, v)
+			found[v] = true
+		}
+	}
+	return ans
+}
+
+// Create stores a new subcorpus along with its frozen list of documents.
+// The ID, NumDocs, Size, Created and Updated values of subc are filled in.
+func (r *Registry) Create(corpusInfo *corpus.DBInfo, subc *Subcorpus, docIDs []string) error {
+	docIDs = uniqueDocIDs(docIDs)
+	subc.ID = uuid.New().String()
+	subc.NumDocs = len(docIDs)
+	subc.Created = time.Now()
+	subc.Updated = subc.Created
+	subc.LastDiff = nil
+	definition, err := json.Marshal(subc.Definition)
+	if err != nil {
+		return fmt.Errorf("failed to create subcorpus: %w", err)
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to create subcorpus: %w", err)
+	}
+	_, err = tx.Exec(
+		"INSERT INTO subcorpus (id, name, owner, corpus_id, kind, definition, size, num_docs, created, updated) "+
+			"VALUES (?, ?, ?, ?, ?, ?, 0, ?, ?, ?)",
+		subc.ID, subc.Name, subc.Owner, subc.CorpusID, subc.Kind, string(definition),
+		subc.NumDocs, subc.Created, subc.Updated,
+	)
+	var mErr *mysql.MySQLError
+	if errors.As(err, &mErr) && mErr.Number == duplicateRowErrNo {
+		tx.Rollback()
+		return ErrorAlreadyExists
+
+	} else if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to create subcorpus: %w", err)
+	}
+	if err := r.insertDocs(tx, subc.ID, docIDs); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to create subcorpus: %w", err)
+	}
+	if err := r.updateSize(tx, corpusInfo, subc.ID); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to create subcorpus: %w", err)
+	}
+	if err := tx.QueryRow("SELECT size FROM subcorpus WHERE id = ?", subc.ID).Scan(&subc.Size); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to create subcorpus: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to create subcorpus: %w", err)
+	}
+	return nil
+}
+
+// Get returns a subcorpus or ErrorNotFound
+func (r *Registry) Get(id string) (*Subcorpus, error) {
+	row := r.db.QueryRow("SELECT "+subcorpusCols+" FROM subcorpus WHERE id = ?", id)
+	ans, err := r.scanSubcorpus(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrorNotFound
+
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get subcorpus %s: %w", id, err)
+	}
+	return ans, nil
+}
+
+// List returns subcorpora of a corpus. An empty owner means "any owner".
+func (r *Registry) List(corpusID, owner string) ([]*Subcorpus, error) {
+	where := "corpus_id = ?"
+	args := []any{corpusID}
+	if owner != "" {
+		where += " AND owner = ?"
+		args = append(args, owner)
+	}
+	rows, err := r.db.Query(
+		"SELECT "+subcorpusCols+" FROM subcorpus WHERE "+where+" ORDER BY owner, name", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subcorpora of %s: %w", corpusID, err)
+	}
+	defer rows.Close()
+	ans := make([]*Subcorpus, 0, 20)
+	for rows.Next() {
+		item, err := r.scanSubcorpus(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list subcorpora of %s: %w", corpusID, err)
+		}
+		ans = append(ans, item)
+	}
+	return ans, rows.Err()
+}
+
+// Rename changes a name of a subcorpus
+func (r *Registry) Rename(id, name string) error {
+	res, err := r.db.Exec(
+		"UPDATE subcorpus SET name = ?, updated = ? WHERE id = ?", name, time.Now(), id)
+	var mErr *mysql.MySQLError
+	if errors.As(err, &mErr) && mErr.Number == duplicateRowErrNo {
+		return ErrorAlreadyExists
+
+	} else if err != nil {
+		return fmt.Errorf("failed to rename subcorpus %s: %w", id, err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrorNotFound
+	}
+	return nil
+}
+
+// Refreeze replaces the frozen document list of a subcorpus
+// and clears its diff report
+func (r *Registry) Refreeze(corpusInfo *corpus.DBInfo, id string, docIDs []string) error {
+	docIDs = uniqueDocIDs(docIDs)
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to refreeze subcorpus %s: %w", id, err)
+	}
+	if _, err := tx.Exec("DELETE FROM subcorpus_doc WHERE subcorpus_id = ?", id); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to refreeze subcorpus %s: %w", id, err)
+	}
+	if err := r.insertDocs(tx, id, docIDs); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to refreeze subcorpus %s: %w", id, err)
+	}
+	_, err = tx.Exec(
+		"UPDATE subcorpus SET num_docs = ?, updated = ?, last_diff = NULL WHERE id = ?",
+		len(docIDs), time.Now(), id,
+	)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to refreeze subcorpus %s: %w", id, err)
+	}
+	if err := r.updateSize(tx, corpusInfo, id); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to refreeze subcorpus %s: %w", id, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to refreeze subcorpus %s: %w", id, err)
+	}
+	return nil
+}
+
+// Delete removes a subcorpus along with its document list
+func (r *Registry) Delete(id string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to delete subcorpus %s: %w", id, err)
+	}
+	if _, err := tx.Exec("DELETE FROM subcorpus_doc WHERE subcorpus_id = ?", id); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to delete subcorpus %s: %w", id, err)
+	}
+	res, err := tx.Exec("DELETE FROM subcorpus WHERE id = ?", id)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to delete subcorpus %s: %w", id, err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		tx.Rollback()
+		return ErrorNotFound
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to delete subcorpus %s: %w", id, err)
+	}
+	return nil
+}
+
+func (r *Registry) queryDocIDs(query string, args ...any) ([]string, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	ans := make([]string, 0, 1000)
+	for rows.Next() {
+		var docID string
+		if err := rows.Scan(&docID); err != nil {
+			return nil, err
+		}
+		ans = append(ans, docID)
+	}
+	return ans, rows.Err()
+}
+
+// DocIDs returns the frozen document list of a subcorpus
+func (r *Registry) DocIDs(id string) ([]string, error) {
+	ans, err := r.queryDocIDs(
+		"SELECT doc_id FROM subcorpus_doc WHERE subcorpus_id = ? ORDER BY doc_id", id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get documents of subcorpus %s: %w", id, err)
+	}
+	return ans, nil
+}
+
+// ExistingDocIDs returns documents of the frozen list
+// which are actually present in liveattrs data
+func (r *Registry) ExistingDocIDs(corpusInfo *corpus.DBInfo, id string) ([]string, error) {
+	ans, err := r.queryDocIDs(
+		fmt.Sprintf(
+			"SELECT DISTINCT sd.doc_id FROM subcorpus_doc AS sd "+
+				"JOIN `%s_liveattrs_entry` AS t1 ON t1.%s = sd.doc_id AND t1.corpus_id = ? "+
+				"WHERE sd.subcorpus_id = ?",
+			corpusInfo.GroupedName(), utils.ImportKey(corpusInfo.BibIDAttr),
+		),
+		corpusInfo.Name, id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get existing documents of subcorpus %s: %w", id, err)
+	}
+	return ans, nil
+}
+
+// UpdateAfterRebuild recalculates a size of a subcorpus
+// and stores a result of its membership check
+func (r *Registry) UpdateAfterRebuild(corpusInfo *corpus.DBInfo, id string, diff MembershipDiff) error {
+	rawDiff, err := json.Marshal(diff)
+	if err != nil {
+		return fmt.Errorf("failed to update subcorpus %s: %w", id, err)
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to update subcorpus %s: %w", id, err)
+	}
+	if _, err := tx.Exec("UPDATE subcorpus SET last_diff = ? WHERE id = ?", string(rawDiff), id); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to update subcorpus %s: %w", id, err)
+	}
+	if err := r.updateSize(tx, corpusInfo, id); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to update subcorpus %s: %w", id, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to update subcorpus %s: %w", id, err)
+	}
+	return nil
+}
+
+func NewRegistry(db *sql.DB) *Registry {
+	return &Registry{db: db}
+}
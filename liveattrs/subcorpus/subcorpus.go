@@ -0,0 +1,122 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package subcorpus provides a registry of named subcorpora
+// defined by text type selections or by subcmixer results.
+// Each subcorpus keeps a frozen list of documents (= values
+// of the corpus bib. ID attribute) it consisted of when it was
+// saved. After a liveattrs rebuild, the list is compared with
+// the actual data.
+package subcorpus
+
+import (
+	"errors"
+	"frodo/liveattrs/request/query"
+	"frodo/liveattrs/subcmixer"
+	"slices"
+	"time"
+)
+
+const (
+	KindSelection = "selection"
+	KindMixer     = "mixer"
+)
+
+var (
+	ErrorNotFound      = errors.New("subcorpus not found")
+	ErrorAlreadyExists = errors.New("subcorpus with the same name already exists")
+)
+
+// Definition describes how a subcorpus was created. For the "selection"
+// kind, the Aligned, Attrs and Filter items are used. For the "mixer"
+// kind, the CategorySizes of the subcmixer result are stored.
+type Definition struct {
+	Aligned       []string                 `json:"aligned,omitempty"`
+	Attrs         query.Attrs              `json:"attrs,omitempty"`
+	Filter        *query.Filter            `json:"filter,omitempty"`
+	CategorySizes []subcmixer.CategorySize `json:"categorySizes,omitempty"`
+}
+
+// MembershipDiff describes how the actual liveattrs data differ
+// from the frozen document list of a subcorpus
+type MembershipDiff struct {
+	Checked time.Time `json:"checked"`
+
+	// Added contains documents which match the subcorpus definition
+	// but are not in the frozen list (always empty for the "mixer" kind)
+	Added []string `json:"added"`
+
+	// Removed contains documents from the frozen list which
+	// no longer match the definition (or which no longer exist)
+	Removed []string `json:"removed"`
+}
+
+// HasChanges tells whether the membership changed
+func (md *MembershipDiff) HasChanges() bool {
+	return len(md.Added) > 0 || len(md.Removed) > 0
+}
+
+// Subcorpus is a saved subcorpus definition
+type Subcorpus struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Owner      string     `json:"owner"`
+	CorpusID   string     `json:"corpusId"`
+	Kind       string     `json:"kind"`
+	Definition Definition `json:"definition"`
+
+	// Size is a number of tokens of the frozen documents
+	Size int64 `json:"size"`
+
+	// NumDocs is a number of the frozen documents
+	NumDocs int       `json:"numDocs"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+
+	// LastDiff is a result of a membership check performed after
+	// the last liveattrs rebuild. It is nil in case no rebuild
+	// happened since the subcorpus was saved.
+	LastDiff *MembershipDiff `json:"lastDiff,omitempty"`
+}
+
+// DiffMembership compares a frozen list of documents with an actual one
+func DiffMembership(frozen, current []string) MembershipDiff {
+	frozenSet := make(map[string]bool, len(frozen))
+	for _, v := range frozen {
+		frozenSet[v] = true
+	}
+	currentSet := make(map[string]bool, len(current))
+	ans := MembershipDiff{
+		Checked: time.Now(),
+		Added:   []string{},
+		Removed: []string{},
+	}
+	for _, v := range current {
+		currentSet[v] = true
+		if !frozenSet[v] {
+			ans.Added = append(ans.Added, v)
+		}
+	}
+	for _, v := range frozen {
+		if !currentSet[v] {
+			ans.Removed = append(ans.Removed, v)
+		}
+	}
+	slices.Sort(ans.Added)
+	ans.Added = slices.Compact(ans.Added)
+	slices.Sort(ans.Removed)
+	ans.Removed = slices.Compact(ans.Removed)
+	return ans
+}
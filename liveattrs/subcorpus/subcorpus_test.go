@@ -0,0 +1,36 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package subcorpus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffMembership(t *testing.T) {
+	diff := DiffMembership([]string{"d3", "d1", "d2", "d2"}, []string{"d4", "d2", "d1", "d5"})
+	assert.Equal(t, []string{"d4", "d5"}, diff.Added)
+	assert.Equal(t, []string{"d3"}, diff.Removed)
+	assert.True(t, diff.HasChanges())
+}
+
+func TestDiffMembershipNoChanges(t *testing.T) {
+	diff := DiffMembership([]string{"d1", "d2"}, []string{"d2", "d1"})
+	assert.Empty(t, diff.Added)
+	assert.Empty(t, diff.Removed)
+	assert.False(t, diff.HasChanges())
+}
@@ -0,0 +1,457 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package vertcheck provides a dry-run validation of a corpus vertical
+// against a liveattrs (vert-tagextract) configuration. It reports
+// structures and attributes found in the vertical along with their
+// value cardinalities and malformed lines without touching any database.
+package vertcheck
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	// DfltMaxDistinctValues is a default maximum number of distinct
+	// values tracked per attribute. Above the limit, the reported
+	// cardinality is just a lower bound.
+	DfltMaxDistinctValues = 10000
+
+	// DfltMaxReportedProblems is a default maximum number of malformed
+	// lines listed in a report (all of them are counted though)
+	DfltMaxReportedProblems = 100
+
+	scannerInitialBufferCap = 64 * 1024
+	scannerMaxBufferSizeCap = 512 * 1024
+)
+
+var (
+	tagNameRegexp  = regexp.MustCompile(`^\w+$`)
+	attrValRegexp  = regexp.MustCompile(`^\s*(\w+)="([^"]*)"`)
+	maybeTagRegexp = regexp.MustCompile(`^</?\w+(\s|$)`)
+)
+
+// Problem describes a malformed line of a vertical
+type Problem struct {
+	File string `json:"file"`
+
+	// Line is a 1-based line number within File
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+// AttrStats contains statistics of a structural attribute
+type AttrStats struct {
+
+	// NumValues is a number of structure instances with the attribute
+	NumValues int `json:"numValues"`
+
+	// Cardinality is a number of distinct values
+	Cardinality int `json:"cardinality"`
+
+	// CardinalityIsLowerBound is true if there were more distinct
+	// values than the validator was configured to track
+	CardinalityIsLowerBound bool `json:"cardinalityIsLowerBound"`
+
+	// Configured is true if the liveattrs config extracts the attribute
+	Configured bool `json:"configured"`
+}
+
+// StructStats contains statistics of a structure
+type StructStats struct {
+	Count int `json:"count"`
+
+	// NumTokens is a number of tokens enclosed by the structure
+	NumTokens int `json:"numTokens"`
+
+	// Configured is true if the liveattrs config extracts the structure
+	Configured bool `json:"configured"`
+
+	Attrs map[string]AttrStats `json:"attrs"`
+}
+
+// Report is a result of a vertical validation
+type Report struct {
+	NumLines  int `json:"numLines"`
+	NumTokens int `json:"numTokens"`
+
+	// Complete is false if only a sample of the vertical
+	// has been validated
+	Complete bool `json:"complete"`
+
+	Structures map[string]StructStats `json:"structures"`
+
+	// UnknownStructures are found in the vertical but not configured
+	UnknownStructures []string `json:"unknownStructures"`
+
+	// MissingStructures are configured but not found in the vertical
+	MissingStructures []string `json:"missingStructures"`
+
+	// UnknownAttrs are found in configured structures of the vertical
+	// but not configured (encoded as struct.attr)
+	UnknownAttrs []string `json:"unknownAttrs"`
+
+	// MissingAttrs are configured but not found in the vertical
+	// (encoded as struct.attr)
+	MissingAttrs []string `json:"missingAttrs"`
+
+	NumMalformedLines int       `json:"numMalformedLines"`
+	MalformedLines    []Problem `json:"malformedLines"`
+
+	AtomStructure string `json:"atomStructure"`
+
+	// RecommendedAtomStructure is the innermost configured structure
+	// containing tokens. Empty if no such structure has been found.
+	RecommendedAtomStructure string `json:"recommendedAtomStructure"`
+}
+
+type openStruct struct {
+	name string
+	file string
+	line int
+}
+
+type attrAcc struct {
+	numValues int
+	values    map[string]struct{}
+	overflow  bool
+}
+
+type structAcc struct {
+	count     int
+	numTokens int
+	attrs     map[string]*attrAcc
+
+	// ancestors contains structures enclosing all the instances
+	// of the structure; nil before the first instance
+	ancestors map[string]bool
+}
+
+// Validator scans one or more vertical files and collects data for a Report.
+// Files are expected to be processed in the order vert-tagextract reads them.
+type Validator struct {
+	structures    map[string][]string
+	atomStructure string
+
+	// maxLines limits the number of lines to scan (<= 0 means no limit)
+	maxLines            int
+	maxDistinctValues   int
+	maxReportedProblems int
+
+	numLines     int
+	numTokens    int
+	numColumns   int
+	limitReached bool
+	stack        []openStruct
+	stats        map[string]*structAcc
+	problems     []Problem
+	numProblems  int
+}
+
+func (v *Validator) addProblem(file string, line int, msg string, args ...any) {
+	v.numProblems++
+	if len(v.problems) < v.maxReportedProblems {
+		v.problems = append(v.problems, Problem{File: file, Line: line, Message: fmt.Sprintf(msg, args...)})
+	}
+}
+
+func (v *Validator) structStats(name string) *structAcc {
+	st, ok := v.stats[name]
+	if !ok {
+		st = &structAcc{attrs: make(map[string]*attrAcc)}
+		v.stats[name] = st
+	}
+	return st
+}
+
+func (v *Validator) addAttrValue(st *structAcc, attr, value string) {
+	acc, ok := st.attrs[attr]
+	if !ok {
+		acc = &attrAcc{values: make(map[string]struct{})}
+		st.attrs[attr] = acc
+	}
+	acc.numValues++
+	if _, ok := acc.values[value]; ok {
+		return
+	}
+	if len(acc.values) < v.maxDistinctValues {
+		acc.values[value] = struct{}{}
+
+	} else {
+		acc.overflow = true
+	}
+}
+
+// parseAttrs parses attributes of an opening tag
+// (i.e. everything between a tag name and ">")
+func parseAttrs(src string) (map[string]string, error) {
+	ans := make(map[string]string)
+	for {
+		srch := attrValRegexp.FindStringSubmatch(src)
+		if srch == nil {
+			break
+		}
+		if _, ok := ans[srch[1]]; ok {
+			return ans, fmt.Errorf("duplicate attribute %s", srch[1])
+		}
+		ans[srch[1]] = srch[2]
+		src = src[len(srch[0]):]
+	}
+	if strings.TrimSpace(src) != "" {
+		return ans, fmt.Errorf("malformed attributes near '%s'", strings.TrimSpace(src))
+	}
+	return ans, nil
+}
+
+func (v *Validator) procOpenTag(file string, lineNum int, line string) {
+	isEmpty := strings.HasSuffix(line, "/>")
+	body := strings.TrimPrefix(line, "<")
+	if isEmpty {
+		body = strings.TrimSuffix(body, "/>")
+
+	} else {
+		body = strings.TrimSuffix(body, ">")
+	}
+	name, attrSrc, _ := strings.Cut(body, " ")
+	if !tagNameRegexp.MatchString(name) {
+		v.addProblem(file, lineNum, "invalid structure name '%s'", name)
+		return
+	}
+	attrs, err := parseAttrs(attrSrc)
+	if err != nil {
+		v.addProblem(file, lineNum, "structure %s: %s", name, err)
+	}
+	st := v.structStats(name)
+	st.count++
+	enclosing := make(map[string]bool, len(v.stack))
+	for _, item := range v.stack {
+		if st.ancestors == nil || st.ancestors[item.name] {
+			enclosing[item.name] = true
+		}
+	}
+	st.ancestors = enclosing
+	for k, val := range attrs {
+		v.addAttrValue(st, k, val)
+	}
+	if !isEmpty {
+		v.stack = append(v.stack, openStruct{name: name, file: file, line: lineNum})
+	}
+}
+
+func (v *Validator) procCloseTag(file string, lineNum int, line string) {
+	name := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(line, "</"), ">"))
+	idx := slices.IndexFunc(v.stack, func(item openStruct) bool { return item.name == name })
+	if idx < 0 {
+		if len(v.stack) == 0 {
+			v.addProblem(file, lineNum, "closing tag </%s> without a matching opening tag", name)
+
+		} else {
+			top := v.stack[len(v.stack)-1]
+			v.addProblem(
+				file, lineNum, "closing tag </%s> does not match <%s> opened at line %d", name, top.name, top.line)
+		}
+		return
+	}
+	for i := len(v.stack) - 1; i > idx; i-- {
+		v.addProblem(
+			file, lineNum, "structure <%s> opened at line %d not closed before </%s>",
+			v.stack[i].name, v.stack[i].line, name)
+	}
+	v.stack = v.stack[:idx]
+}
+
+func (v *Validator) procToken(file string, lineNum int, line string) {
+	v.numTokens++
+	numCols := strings.Count(line, "\t") + 1
+	if v.numColumns == 0 {
+		v.numColumns = numCols
+
+	} else if numCols != v.numColumns {
+		v.addProblem(
+			file, lineNum, "unexpected number of columns (expected %d, found %d)", v.numColumns, numCols)
+	}
+	for _, item := range v.stack {
+		v.stats[item.name].numTokens++
+	}
+}
+
+func (v *Validator) procLine(file string, lineNum int, line string) {
+	line = strings.TrimRight(line, "\r ")
+	switch {
+	case line == "":
+		return
+	case strings.HasPrefix(line, "</") && strings.HasSuffix(line, ">"):
+		v.procCloseTag(file, lineNum, line)
+	case strings.HasPrefix(line, "<") && strings.HasSuffix(line, ">"):
+		v.procOpenTag(file, lineNum, line)
+	case maybeTagRegexp.MatchString(line) && !strings.Contains(line, "\t"):
+		v.addProblem(file, lineNum, "unterminated tag")
+	default:
+		v.procToken(file, lineNum, line)
+	}
+}
+
+// Process scans a single vertical file. The file name is used
+// only to identify malformed lines. Once the configured line limit
+// is reached, the method does nothing.
+func (v *Validator) Process(file string, rd io.Reader) error {
+	if v.limitReached {
+		return nil
+	}
+	sc := bufio.NewScanner(rd)
+	sc.Buffer(make([]byte, 0, scannerInitialBufferCap), scannerMaxBufferSizeCap)
+	lineNum := 0
+	for sc.Scan() {
+		if v.maxLines > 0 && v.numLines >= v.maxLines {
+			v.limitReached = true
+			break
+		}
+		lineNum++
+		v.numLines++
+		v.procLine(file, lineNum, sc.Text())
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("failed to read vertical %s (line %d): %w", file, lineNum+1, err)
+	}
+	return nil
+}
+
+// LimitReached tells whether the validator stopped
+// scanning due to the configured line limit
+func (v *Validator) LimitReached() bool {
+	return v.limitReached
+}
+
+// NumLines returns the number of lines scanned so far
+func (v *Validator) NumLines() int {
+	return v.numLines
+}
+
+// recommendAtom returns the innermost configured structure
+// containing tokens. In case of siblings, the more frequent one
+// is preferred.
+func (v *Validator) recommendAtom() string {
+	var ans string
+	bestDepth, bestCount := -1, 0
+	for name, st := range v.stats {
+		if _, ok := v.structures[name]; !ok || st.numTokens == 0 {
+			continue
+		}
+		depth := 0
+		for anc := range st.ancestors {
+			if _, ok := v.structures[anc]; ok {
+				depth++
+			}
+		}
+		if depth > bestDepth ||
+			depth == bestDepth && st.count > bestCount ||
+			depth == bestDepth && st.count == bestCount && name < ans {
+			ans = name
+			bestDepth = depth
+			bestCount = st.count
+		}
+	}
+	return ans
+}
+
+// Report creates a validation report based on the data scanned so far.
+// Unclosed structures are reported only in case the vertical has been
+// scanned completely.
+func (v *Validator) Report() Report {
+	ans := Report{
+		NumLines:          v.numLines,
+		NumTokens:         v.numTokens,
+		Complete:          !v.limitReached,
+		Structures:        make(map[string]StructStats, len(v.stats)),
+		UnknownStructures: []string{},
+		MissingStructures: []string{},
+		UnknownAttrs:      []string{},
+		MissingAttrs:      []string{},
+		AtomStructure:     v.atomStructure,
+	}
+	if ans.Complete {
+		for _, item := range v.stack {
+			v.addProblem(item.file, item.line, "structure <%s> not closed", item.name)
+		}
+		v.stack = v.stack[:0]
+	}
+	ans.NumMalformedLines = v.numProblems
+	ans.MalformedLines = v.problems
+	if ans.MalformedLines == nil {
+		ans.MalformedLines = []Problem{}
+	}
+
+	for name, st := range v.stats {
+		confAttrs, configured := v.structures[name]
+		stats := StructStats{
+			Count:      st.count,
+			NumTokens:  st.numTokens,
+			Configured: configured,
+			Attrs:      make(map[string]AttrStats, len(st.attrs)),
+		}
+		for attr, acc := range st.attrs {
+			attrConfigured := slices.Contains(confAttrs, attr)
+			stats.Attrs[attr] = AttrStats{
+				NumValues:               acc.numValues,
+				Cardinality:             len(acc.values),
+				CardinalityIsLowerBound: acc.overflow,
+				Configured:              attrConfigured,
+			}
+			if configured && !attrConfigured {
+				ans.UnknownAttrs = append(ans.UnknownAttrs, name+"."+attr)
+			}
+		}
+		ans.Structures[name] = stats
+		if !configured {
+			ans.UnknownStructures = append(ans.UnknownStructures, name)
+		}
+	}
+	for name, attrs := range v.structures {
+		st, ok := v.stats[name]
+		if !ok {
+			ans.MissingStructures = append(ans.MissingStructures, name)
+		}
+		for _, attr := range attrs {
+			if !ok || st.attrs[attr] == nil {
+				ans.MissingAttrs = append(ans.MissingAttrs, name+"."+attr)
+			}
+		}
+	}
+	slices.Sort(ans.UnknownStructures)
+	slices.Sort(ans.MissingStructures)
+	slices.Sort(ans.UnknownAttrs)
+	slices.Sort(ans.MissingAttrs)
+	ans.RecommendedAtomStructure = v.recommendAtom()
+	return ans
+}
+
+// NewValidator creates a validator for configured structures
+// (structure => attributes as in vert-tagextract config).
+// The maxLines argument limits the number of lines to scan (<= 0 means no limit).
+func NewValidator(structures map[string][]string, atomStructure string, maxLines int) *Validator {
+	return &Validator{
+		structures:          structures,
+		atomStructure:       atomStructure,
+		maxLines:            maxLines,
+		maxDistinctValues:   DfltMaxDistinctValues,
+		maxReportedProblems: DfltMaxReportedProblems,
+		stats:               make(map[string]*structAcc),
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vertcheck
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testVertical = `<doc id="d1" genre="fiction">
+<p id="p1">
+<s>
+Hello	hello	NN
+world	world	NN
+</s>
+</p>
+</doc>
+<doc id="d2" genre="poetry" year="2001">
+<p id="p2" id="p3">
+<s>
+Hi	hi
+</p>
+<doc id="d3
+</x>
+</doc>
+<doc id="d4" genre="fiction" broken>
+Yo	yo	NN
+`
+
+func TestValidatorReport(t *testing.T) {
+	v := NewValidator(
+		map[string][]string{"doc": {"id", "genre", "author"}, "p": {"id"}, "text": {"id"}},
+		"doc",
+		0,
+	)
+	assert.NoError(t, v.Process("test.vert", strings.NewReader(testVertical)))
+	rep := v.Report()
+
+	assert.True(t, rep.Complete)
+	assert.Equal(t, 18, rep.NumLines)
+	assert.Equal(t, 4, rep.NumTokens)
+	assert.Equal(t, []string{"s"}, rep.UnknownStructures)
+	assert.Equal(t, []string{"text"}, rep.MissingStructures)
+	assert.Equal(t, []string{"doc.year"}, rep.UnknownAttrs)
+	assert.Equal(t, []string{"doc.author", "text.id"}, rep.MissingAttrs)
+	assert.Equal(t, 3, rep.Structures["doc"].Count)
+	assert.Equal(t, 2, rep.Structures["doc"].Attrs["genre"].Cardinality)
+	assert.Equal(t, 3, rep.Structures["doc"].Attrs["genre"].NumValues)
+	assert.Equal(t, "p", rep.RecommendedAtomStructure)
+
+	lines := make([]int, len(rep.MalformedLines))
+	for i, p := range rep.MalformedLines {
+		lines[i] = p.Line
+	}
+	// 10: duplicate attr, 12: columns, 13: unclosed <s>, 14: unterminated tag,
+	// 15: mismatched close, 17: malformed attrs, 17: <doc> not closed at the end
+	assert.Equal(t, []int{10, 12, 13, 14, 15, 17, 17}, lines)
+	assert.Equal(t, 7, rep.NumMalformedLines)
+}
+
+func TestValidatorLineLimit(t *testing.T) {
+	v := NewValidator(map[string][]string{"doc": {"id"}}, "doc", 3)
+	assert.NoError(t, v.Process("a.vert", strings.NewReader(testVertical)))
+	assert.True(t, v.LimitReached())
+	assert.NoError(t, v.Process("b.vert", strings.NewReader(testVertical)))
+	rep := v.Report()
+	assert.False(t, rep.Complete)
+	assert.Equal(t, 3, rep.NumLines)
+	assert.Empty(t, rep.MalformedLines)
+}
@@ -192,7 +192,7 @@ func (a *Actions) AutoConf(ctx *gin.Context) {
 		return
 	}
 	conf.LiveTokens = profileConf
-	if err := a.laConfCache.Save(conf); err != nil {
+	if err := a.laConfCache.Save(conf, ctx.GetHeader(laconf.AuthorHeader)); err != nil {
 		uniresp.RespondWithErrorJSON(ctx, err, http.StatusInternalServerError)
 		return
 	}
@@ -249,7 +249,7 @@ func (a *Actions) AutoConfWithProfile(ctx *gin.Context) {
 
 			} else {
 				vteConf.LiveTokens = pConfNew
-				if err := a.laConfCache.Save(vteConf); err != nil {
+				if err := a.laConfCache.Save(vteConf, ctx.GetHeader(laconf.AuthorHeader)); err != nil {
 					status.Error = err.Error()
 
 				} else {
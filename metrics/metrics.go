@@ -0,0 +1,203 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics provides Prometheus metrics of the service.
+// Metrics are registered in a package-level registry so that
+// any package can update them without a need to pass a metrics
+// object around.
+package metrics
+
+import (
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	namespace = "frodo"
+)
+
+var (
+	registry = prometheus.NewRegistry()
+
+	jobDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "jobs",
+			Name:      "duration_seconds",
+			Help:      "Duration of finished jobs",
+			Buckets:   prometheus.ExponentialBuckets(1, 4, 10),
+		},
+		[]string{"type", "status"},
+	)
+
+	liveAttrsQueryDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "liveattrs",
+			Name:      "query_duration_seconds",
+			Help:      "Latency of liveattrs queries",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 2.5, 12),
+		},
+		[]string{"corpus", "cached"},
+	)
+
+	emptyQueryCacheRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "liveattrs",
+			Name:      "empty_query_cache_requests_total",
+			Help:      "Number of cacheable liveattrs queries by the cache result (hit, miss)",
+		},
+		[]string{"result"},
+	)
+
+	queryCacheRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "liveattrs",
+			Name:      "query_cache_requests_total",
+			Help:      "Number of liveattrs queries with non-empty attributes by the cache result (hit, miss)",
+		},
+		[]string{"result"},
+	)
+
+	ngramImportedItems = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "ngrams",
+			Name:      "imported_items_total",
+			Help:      "Number of n-grams written to n-gram tables",
+		},
+		[]string{"corpus"},
+	)
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		jobDuration,
+		liveAttrsQueryDuration,
+		emptyQueryCacheRequests,
+		queryCacheRequests,
+		ngramImportedItems,
+	)
+}
+
+// ObserveJobDuration records a duration of a finished job
+func ObserveJobDuration(jobType, status string, dur time.Duration) {
+	jobDuration.WithLabelValues(jobType, status).Observe(dur.Seconds())
+}
+
+// ObserveLiveAttrsQuery records a latency of a liveattrs query
+func ObserveLiveAttrsQuery(corpusID string, isCached bool, dur time.Duration) {
+	liveAttrsQueryDuration.WithLabelValues(corpusID, strconv.FormatBool(isCached)).Observe(dur.Seconds())
+}
+
+// CountEmptyQueryCacheRequest records a hit or a miss of the liveattrs
+// empty query cache
+func CountEmptyQueryCacheRequest(hit bool) {
+	if hit {
+		emptyQueryCacheRequests.WithLabelValues("hit").Inc()
+
+	} else {
+		emptyQueryCacheRequests.WithLabelValues("miss").Inc()
+	}
+}
+
+// CountQueryCacheRequest records a hit or a miss of the liveattrs
+// query cache
+func CountQueryCacheRequest(hit bool) {
+	if hit {
+		queryCacheRequests.WithLabelValues("hit").Inc()
+
+	} else {
+		queryCacheRequests.WithLabelValues("miss").Inc()
+	}
+}
+
+// AddNgramImportedItems increases the number of n-grams written
+// to the n-gram tables of a corpus
+func AddNgramImportedItems(corpusID string, numItems int) {
+	ngramImportedItems.WithLabelValues(corpusID).Add(float64(numItems))
+}
+
+// JobStatsProvider provides current job queue statistics
+type JobStatsProvider interface {
+
+	// QueueLengthByType returns numbers of queued jobs (key = job type)
+	QueueLengthByType() map[string]int
+
+	// RunningJobsByType returns numbers of running jobs (key = job type)
+	RunningJobsByType() map[string]int
+}
+
+// jobStatsCollector reads job statistics on each scrape
+type jobStatsCollector struct {
+	provider    JobStatsProvider
+	queueLength *prometheus.Desc
+	runningJobs *prometheus.Desc
+}
+
+func (c *jobStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.queueLength
+	ch <- c.runningJobs
+}
+
+func (c *jobStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	for jobType, n := range c.provider.QueueLengthByType() {
+		ch <- prometheus.MustNewConstMetric(c.queueLength, prometheus.GaugeValue, float64(n), jobType)
+	}
+	for jobType, n := range c.provider.RunningJobsByType() {
+		ch <- prometheus.MustNewConstMetric(c.runningJobs, prometheus.GaugeValue, float64(n), jobType)
+	}
+}
+
+// RegisterJobStats registers metrics of the job queue and running jobs
+func RegisterJobStats(provider JobStatsProvider) {
+	registry.MustRegister(&jobStatsCollector{
+		provider: provider,
+		queueLength: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "jobs", "queue_length"),
+			"Number of queued jobs",
+			[]string{"type"}, nil,
+		),
+		runningJobs: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "jobs", "running"),
+			"Number of running jobs",
+			[]string{"type"}, nil,
+		),
+	})
+}
+
+// RegisterDBStats registers connection pool metrics of a database
+func RegisterDBStats(db *sql.DB, dbName string) {
+	registry.MustRegister(collectors.NewDBStatsCollector(db, dbName))
+}
+
+// Handler godoc
+// @Summary      Provides service metrics in the Prometheus text format
+// @Produce      plain
+// @Success      200 {string} string
+// @Router       /metrics [get]
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+}
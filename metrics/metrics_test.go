@@ -0,0 +1,57 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type fixedJobStats struct{}
+
+func (fs fixedJobStats) QueueLengthByType() map[string]int {
+	return map[string]int{"liveattrs": 2}
+}
+
+func (fs fixedJobStats) RunningJobsByType() map[string]int {
+	return map[string]int{"ngram-generating": 1}
+}
+
+func TestHandlerProvidesMetrics(t *testing.T) {
+	RegisterJobStats(fixedJobStats{})
+	ObserveJobDuration("liveattrs", "finished", 3*time.Second)
+	CountEmptyQueryCacheRequest(true)
+	CountEmptyQueryCacheRequest(false)
+	CountEmptyQueryCacheRequest(false)
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.GET("/metrics", Handler())
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	assert.Contains(t, body, `frodo_jobs_queue_length{type="liveattrs"} 2`)
+	assert.Contains(t, body, `frodo_jobs_running{type="ngram-generating"} 1`)
+	assert.Contains(t, body, `frodo_jobs_duration_seconds_count{status="finished",type="liveattrs"} 1`)
+	assert.Contains(t, body, `frodo_liveattrs_empty_query_cache_requests_total{result="miss"} 2`)
+}
@@ -0,0 +1,193 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"frodo/jobs"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/czcorpus/cnc-gokit/uniresp"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	pipelineMaxAge = 168 * time.Hour
+)
+
+// Actions contains pipeline-related actions
+type Actions struct {
+	ctx        context.Context
+	jobActions *jobs.Actions
+
+	// handler is the server's HTTP handler used to create
+	// jobs of pipeline steps
+	handler   http.Handler
+	pipelines map[string]*pipeline
+	lock      sync.Mutex
+}
+
+func (a *Actions) status(pln *pipeline) Status {
+	ans := Status{
+		ID:      pln.id,
+		Created: pln.created,
+		Steps:   make([]StepStatus, len(pln.steps)),
+	}
+	for i, step := range pln.steps {
+		stepStatus := StepStatus{
+			ID:     step.ID,
+			Action: step.Action,
+			Target: step.Target,
+			After:  step.After,
+			JobID:  pln.jobIDs[step.ID],
+		}
+		if stepStatus.After == nil {
+			stepStatus.After = []string{}
+		}
+		if job, ok := a.jobActions.GetJob(stepStatus.JobID); ok {
+			stepStatus.Status, stepStatus.Error = jobStatus(job)
+
+		} else if a.jobActions.IsQueued(stepStatus.JobID) {
+			stepStatus.Status = StatusQueued
+
+		} else {
+			stepStatus.Status = StatusUnknown
+		}
+		ans.Steps[i] = stepStatus
+	}
+	ans.Status = aggregateStatus(ans.Steps)
+	return ans
+}
+
+// clearOldPipelines removes pipelines older than jobs
+// kept by jobs.Actions. The method expects the lock to be held.
+func (a *Actions) clearOldPipelines() {
+	for id, pln := range a.pipelines {
+		if time.Since(time.Time(pln.created)) > pipelineMaxAge {
+			delete(a.pipelines, id)
+		}
+	}
+}
+
+// cancelJobs cancels jobs of a partially submitted pipeline
+func (a *Actions) cancelJobs(jobIDs []string) {
+	for _, jobID := range slices.Backward(jobIDs) {
+		if err := a.jobActions.CancelJob(jobID); err != nil {
+			log.Error().Err(err).Str("jobId", jobID).Msg("failed to cancel job of an unsubmitted pipeline")
+		}
+	}
+}
+
+// Submit godoc
+// @Summary      Submits a pipeline of jobs with dependencies
+// @Description  Steps are enqueued in a dependency order, each step waits for all the steps listed in its "after". Steps with a failed or cancelled parent are skipped. Each step uses the same query and args as the respective HTTP action (liveattrs, ngrams, datasetSize, keywords, keywordsOfTheWeek). In case any step cannot be created, the already created steps are cancelled.
+// @Accept       json
+// @Produce      json
+// @Param        pipeline body Request true "Pipeline steps"
+// @Success      201 {object} Status
+// @Failure      400 {object} uniresp.ActionError
+// @Failure      422 {object} uniresp.ActionError
+// @Router       /jobs/pipeline [post]
+func (a *Actions) Submit(ctx *gin.Context) {
+	var req Request
+	if err := json.NewDecoder(ctx.Request.Body).Decode(&req); err != nil {
+		uniresp.RespondWithErrorJSON(ctx, err, http.StatusBadRequest)
+		return
+	}
+	steps, err := req.SortedSteps()
+	if err != nil {
+		uniresp.RespondWithErrorJSON(ctx, err, http.StatusBadRequest)
+		return
+	}
+	pipelineID, err := uuid.NewUUID()
+	if err != nil {
+		uniresp.RespondWithErrorJSON(ctx, err, http.StatusInternalServerError)
+		return
+	}
+	pln := &pipeline{
+		id:      pipelineID.String(),
+		created: jobs.CurrentDatetime(),
+		steps:   steps,
+		jobIDs:  make(map[string]string),
+	}
+	created := make([]string, 0, len(steps))
+	for _, step := range steps {
+		parentJobIDs := make([]string, len(step.After))
+		for i, parent := range step.After {
+			parentJobIDs[i] = pln.jobIDs[parent]
+		}
+		jobID, err := CallJobAction(
+			a.ctx, a.handler, step.path(), step.query(parentJobIDs), step.body())
+		if err != nil {
+			a.cancelJobs(created)
+			uniresp.RespondWithErrorJSON(
+				ctx,
+				fmt.Errorf("failed to create job of step %s: %w", step.ID, err),
+				http.StatusUnprocessableEntity,
+			)
+			return
+		}
+		pln.jobIDs[step.ID] = jobID
+		created = append(created, jobID)
+	}
+	a.lock.Lock()
+	a.clearOldPipelines()
+	a.pipelines[pln.id] = pln
+	a.lock.Unlock()
+	log.Info().
+		Str("pipelineId", pln.id).
+		Strs("jobIds", created).
+		Msg("pipeline submitted")
+	uniresp.WriteJSONResponseWithStatus(ctx.Writer, http.StatusCreated, a.status(pln))
+}
+
+// Status godoc
+// @Summary      Shows an aggregate status of a pipeline and statuses of its steps
+// @Produce      json
+// @Param        pipelineId path string true "Pipeline ID"
+// @Success      200 {object} Status
+// @Failure      404 {object} uniresp.ActionError
+// @Router       /jobs/pipeline/{pipelineId} [get]
+func (a *Actions) Status(ctx *gin.Context) {
+	a.lock.Lock()
+	pln, ok := a.pipelines[ctx.Param("pipelineId")]
+	a.lock.Unlock()
+	if !ok {
+		uniresp.WriteJSONErrorResponse(
+			ctx.Writer, uniresp.NewActionError("pipeline not found"), http.StatusNotFound)
+		return
+	}
+	uniresp.WriteJSONResponse(ctx.Writer, a.status(pln))
+}
+
+// NewActions is the default factory for Actions. The handler
+// is expected to be the server's HTTP handler with all the job
+// actions registered.
+func NewActions(ctx context.Context, jobActions *jobs.Actions, handler http.Handler) *Actions {
+	return &Actions{
+		ctx:        ctx,
+		jobActions: jobActions,
+		handler:    handler,
+		pipelines:  make(map[string]*pipeline),
+	}
+}
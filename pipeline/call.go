@@ -0,0 +1,75 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+)
+
+type createdJob struct {
+	ID    string `json:"id"`
+	Error string `json:"error"`
+}
+
+// CallJobAction creates a job by passing a POST request directly
+// to the server's HTTP handler. This way, internally created jobs
+// are processed exactly the same way as the ones created via
+// the HTTP API (incl. all the arguments validation).
+// The function returns ID of the created job.
+func CallJobAction(
+	ctx context.Context,
+	handler http.Handler,
+	path string,
+	query url.Values,
+	body any,
+) (string, error) {
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			return "", fmt.Errorf("failed to encode job arguments: %w", err)
+		}
+	}
+	target := path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req := httptest.NewRequestWithContext(ctx, http.MethodPost, target, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+
+	var ans createdJob
+	decodeErr := json.Unmarshal(resp.Body.Bytes(), &ans)
+	if resp.Code >= 400 {
+		if decodeErr != nil || ans.Error == "" {
+			return "", fmt.Errorf("%s returned status %d", path, resp.Code)
+		}
+		return "", fmt.Errorf("%s returned status %d: %s", path, resp.Code, ans.Error)
+	}
+	if decodeErr != nil {
+		return "", fmt.Errorf("failed to decode response of %s: %w", path, decodeErr)
+	}
+	if ans.ID == "" {
+		return "", fmt.Errorf("%s did not return a job ID", path)
+	}
+	return ans.ID, nil
+}
@@ -0,0 +1,242 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"frodo/jobs"
+	"net/url"
+)
+
+const (
+	ActionLiveAttrs         = "liveattrs"
+	ActionNgrams            = "ngrams"
+	ActionKeywords          = "keywords"
+	ActionKeywordsOfTheWeek = "keywordsOfTheWeek"
+	ActionDatasetSize       = "datasetSize"
+
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusFinished  = "finished"
+	StatusFailed    = "failed"
+	StatusSkipped   = "skipped"
+	StatusCancelled = "cancelled"
+	StatusUnknown   = "unknown"
+)
+
+var (
+	ErrorCircularPipeline = errors.New("pipeline steps contain a cycle")
+)
+
+// actionPaths maps step actions to paths of respective
+// job-creating HTTP actions (the argument is a corpus or dataset ID)
+var actionPaths = map[string]string{
+	ActionLiveAttrs:         "/liveAttributes/%s/data",
+	ActionNgrams:            "/dictionary/%s/ngrams",
+	ActionKeywords:          "/keywordsOfPeriod/%s",
+	ActionKeywordsOfTheWeek: "/keywordsOfTheWeek/%s",
+	ActionDatasetSize:       "/dictionary/%s/datasetSize",
+}
+
+// Step is a single job of a pipeline. The Query and Args
+// are the same as the ones of the respective HTTP API action.
+type Step struct {
+	ID     string `json:"id"`
+	Action string `json:"action"`
+
+	// Target is a corpus ID (liveattrs, ngrams, datasetSize) or a monitoring
+	// dataset ID (keywords, keywordsOfTheWeek)
+	Target string            `json:"target"`
+	Query  map[string]string `json:"query"`
+	Args   json.RawMessage   `json:"args,omitempty"`
+
+	// After contains IDs of steps which must finish
+	// successfully before this step can run
+	After []string `json:"after"`
+}
+
+func (step Step) path() string {
+	return fmt.Sprintf(actionPaths[step.Action], url.PathEscape(step.Target))
+}
+
+func (step Step) query(parentJobIDs []string) url.Values {
+	ans := url.Values{}
+	for k, v := range step.Query {
+		ans.Set(k, v)
+	}
+	for _, pid := range parentJobIDs {
+		ans.Add("parentJobId", pid)
+	}
+	return ans
+}
+
+func (step Step) body() any {
+	if len(step.Args) == 0 {
+		return nil
+	}
+	return step.Args
+}
+
+// Request is a declarative description of a pipeline
+type Request struct {
+	Steps []Step `json:"steps"`
+}
+
+// SortedSteps validates the pipeline and returns its steps
+// in an order where each step comes after all its parents
+func (req Request) SortedSteps() ([]Step, error) {
+	if len(req.Steps) == 0 {
+		return []Step{}, fmt.Errorf("empty pipeline")
+	}
+	byID := make(map[string]Step)
+	for _, step := range req.Steps {
+		if step.ID == "" {
+			return []Step{}, fmt.Errorf("step with missing id")
+		}
+		if _, ok := byID[step.ID]; ok {
+			return []Step{}, fmt.Errorf("duplicate step id %s", step.ID)
+		}
+		if _, ok := actionPaths[step.Action]; !ok {
+			return []Step{}, fmt.Errorf("unknown action %s in step %s", step.Action, step.ID)
+		}
+		if step.Target == "" {
+			return []Step{}, fmt.Errorf("missing target in step %s", step.ID)
+		}
+		byID[step.ID] = step
+	}
+	for _, step := range req.Steps {
+		for _, parent := range step.After {
+			if _, ok := byID[parent]; !ok {
+				return []Step{}, fmt.Errorf("step %s depends on unknown step %s", step.ID, parent)
+			}
+		}
+	}
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int)
+	ans := make([]Step, 0, len(req.Steps))
+	var visit func(step Step) error
+	visit = func(step Step) error {
+		switch state[step.ID] {
+		case visiting:
+			return ErrorCircularPipeline
+		case visited:
+			return nil
+		}
+		state[step.ID] = visiting
+		for _, parent := range step.After {
+			if err := visit(byID[parent]); err != nil {
+				return err
+			}
+		}
+		state[step.ID] = visited
+		ans = append(ans, step)
+		return nil
+	}
+	for _, step := range req.Steps {
+		if err := visit(step); err != nil {
+			return []Step{}, err
+		}
+	}
+	return ans, nil
+}
+
+// StepStatus describes a state of a pipeline step
+// and its respective job
+type StepStatus struct {
+	ID     string   `json:"id"`
+	Action string   `json:"action"`
+	Target string   `json:"target"`
+	After  []string `json:"after"`
+	JobID  string   `json:"jobId"`
+	Status string   `json:"status"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// Status is an aggregate status of a pipeline
+type Status struct {
+	ID      string        `json:"id"`
+	Created jobs.JSONTime `json:"created"`
+	Status  string        `json:"status"`
+	Steps   []StepStatus  `json:"steps"`
+}
+
+// pipeline is a submitted pipeline with its steps
+// mapped to created jobs
+type pipeline struct {
+	id      string
+	created jobs.JSONTime
+	steps   []Step
+	jobIDs  map[string]string
+}
+
+// jobStatus maps a job state to a pipeline step status
+func jobStatus(job jobs.GeneralJobInfo) (string, string) {
+	if !job.IsFinished() {
+		return StatusRunning, ""
+	}
+	if job.IsCancelled() {
+		return StatusCancelled, ""
+	}
+	if err := job.GetError(); err != nil {
+		if errors.Is(err, jobs.ErrorFailedParent) {
+			return StatusSkipped, err.Error()
+		}
+		return StatusFailed, err.Error()
+	}
+	return StatusFinished, ""
+}
+
+// aggregateStatus derives a pipeline status from its steps. A pipeline
+// is running until all its steps are resolved. Then it is failed in case
+// any step failed, cancelled in case any step was cancelled or skipped
+// and finished otherwise.
+func aggregateStatus(steps []StepStatus) string {
+	var numQueued, numRunning, numFailed, numCancelled int
+	for _, step := range steps {
+		switch step.Status {
+		case StatusQueued:
+			numQueued++
+		case StatusRunning:
+			numRunning++
+		case StatusFailed, StatusUnknown:
+			numFailed++
+		case StatusCancelled, StatusSkipped:
+			numCancelled++
+		}
+	}
+	if numRunning > 0 {
+		return StatusRunning
+	}
+	if numQueued > 0 {
+		if numQueued < len(steps) {
+			return StatusRunning
+		}
+		return StatusQueued
+	}
+	if numFailed > 0 {
+		return StatusFailed
+	}
+	if numCancelled > 0 {
+		return StatusCancelled
+	}
+	return StatusFinished
+}
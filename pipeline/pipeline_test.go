@@ -0,0 +1,82 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortedStepsRespectsDependencies(t *testing.T) {
+	req := Request{
+		Steps: []Step{
+			{ID: "ngrams", Action: ActionNgrams, Target: "syn2020", After: []string{"la1", "la2"}},
+			{ID: "la2", Action: ActionLiveAttrs, Target: "syn2020", After: []string{"la1"}},
+			{ID: "la1", Action: ActionLiveAttrs, Target: "syn2020"},
+		},
+	}
+	steps, err := req.SortedSteps()
+	assert.NoError(t, err)
+	ids := make([]string, len(steps))
+	for i, step := range steps {
+		ids[i] = step.ID
+	}
+	assert.Equal(t, []string{"la1", "la2", "ngrams"}, ids)
+}
+
+func TestSortedStepsRejectsInvalidGraph(t *testing.T) {
+	_, err := Request{
+		Steps: []Step{
+			{ID: "a", Action: ActionLiveAttrs, Target: "x", After: []string{"b"}},
+			{ID: "b", Action: ActionNgrams, Target: "x", After: []string{"a"}},
+		},
+	}.SortedSteps()
+	assert.ErrorIs(t, err, ErrorCircularPipeline)
+
+	_, err = Request{
+		Steps: []Step{{ID: "a", Action: ActionLiveAttrs, Target: "x", After: []string{"c"}}},
+	}.SortedSteps()
+	assert.Error(t, err)
+
+	_, err = Request{Steps: []Step{{ID: "a", Action: "foo", Target: "x"}}}.SortedSteps()
+	assert.Error(t, err)
+}
+
+func TestAggregateStatus(t *testing.T) {
+	st := func(values ...string) []StepStatus {
+		ans := make([]StepStatus, len(values))
+		for i, v := range values {
+			ans[i] = StepStatus{Status: v}
+		}
+		return ans
+	}
+	assert.Equal(t, StatusQueued, aggregateStatus(st(StatusQueued, StatusQueued)))
+	assert.Equal(t, StatusRunning, aggregateStatus(st(StatusFinished, StatusQueued)))
+	assert.Equal(t, StatusRunning, aggregateStatus(st(StatusFailed, StatusRunning)))
+	assert.Equal(t, StatusFailed, aggregateStatus(st(StatusFailed, StatusSkipped)))
+	assert.Equal(t, StatusCancelled, aggregateStatus(st(StatusCancelled, StatusSkipped)))
+	assert.Equal(t, StatusFinished, aggregateStatus(st(StatusFinished, StatusFinished)))
+}
+
+func TestStepPath(t *testing.T) {
+	assert.Equal(
+		t,
+		"/dictionary/wag%2Fsyn/datasetSize",
+		Step{Action: ActionDatasetSize, Target: "wag/syn"}.path(),
+	)
+	assert.Equal(t, "/liveAttributes/syn2020/data", Step{Action: ActionLiveAttrs, Target: "syn2020"}.path())
+}
@@ -0,0 +1,101 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"net/http"
+
+	"github.com/czcorpus/cnc-gokit/uniresp"
+	"github.com/gin-gonic/gin"
+)
+
+type Actions struct {
+	scheduler *Scheduler
+}
+
+func (a *Actions) respond(ctx *gin.Context, ans ScheduleInfo, err error) {
+	if err == ErrorScheduleNotFound {
+		uniresp.RespondWithErrorJSON(ctx, err, http.StatusNotFound)
+		return
+
+	} else if err != nil {
+		uniresp.RespondWithErrorJSON(ctx, err, http.StatusInternalServerError)
+		return
+	}
+	uniresp.WriteJSONResponse(ctx.Writer, ans)
+}
+
+// List godoc
+// @Summary      Lists configured job schedules
+// @Produce      json
+// @Success      200 {object} []ScheduleInfo
+// @Router       /schedules [get]
+func (a *Actions) List(ctx *gin.Context) {
+	uniresp.WriteJSONResponse(ctx.Writer, a.scheduler.List())
+}
+
+// Get godoc
+// @Summary      Shows a job schedule
+// @Produce      json
+// @Param        scheduleId path string true "Schedule ID"
+// @Success      200 {object} ScheduleInfo
+// @Failure      404 {object} uniresp.ActionError
+// @Router       /schedules/{scheduleId} [get]
+func (a *Actions) Get(ctx *gin.Context) {
+	ans, err := a.scheduler.Get(ctx.Param("scheduleId"))
+	a.respond(ctx, ans, err)
+}
+
+// Pause godoc
+// @Summary      Pauses a job schedule
+// @Description  A paused schedule does not create any jobs until it is resumed. The state is kept across server restarts.
+// @Produce      json
+// @Param        scheduleId path string true "Schedule ID"
+// @Success      200 {object} ScheduleInfo
+// @Failure      404 {object} uniresp.ActionError
+// @Router       /schedules/{scheduleId}/pause [post]
+func (a *Actions) Pause(ctx *gin.Context) {
+	ans, err := a.scheduler.SetPaused(ctx.Param("scheduleId"), true)
+	a.respond(ctx, ans, err)
+}
+
+// Resume godoc
+// @Summary      Resumes a paused job schedule
+// @Produce      json
+// @Param        scheduleId path string true "Schedule ID"
+// @Success      200 {object} ScheduleInfo
+// @Failure      404 {object} uniresp.ActionError
+// @Router       /schedules/{scheduleId}/resume [post]
+func (a *Actions) Resume(ctx *gin.Context) {
+	ans, err := a.scheduler.SetPaused(ctx.Param("scheduleId"), false)
+	a.respond(ctx, ans, err)
+}
+
+// Run godoc
+// @Summary      Creates jobs of a schedule immediately (even if the schedule is paused)
+// @Produce      json
+// @Param        scheduleId path string true "Schedule ID"
+// @Success      200 {object} ScheduleInfo
+// @Failure      404 {object} uniresp.ActionError
+// @Router       /schedules/{scheduleId}/run [post]
+func (a *Actions) Run(ctx *gin.Context) {
+	ans, err := a.scheduler.RunNow(ctx.Param("scheduleId"))
+	a.respond(ctx, ans, err)
+}
+
+func NewActions(scheduler *Scheduler) *Actions {
+	return &Actions{scheduler: scheduler}
+}
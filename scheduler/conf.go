@@ -0,0 +1,170 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"fmt"
+	dictActions "frodo/dictionary/actions"
+	"frodo/liveattrs/laconf"
+
+	"github.com/robfig/cron/v3"
+)
+
+const (
+	JobTypeLiveAttrs = "liveattrs"
+	JobTypeNgrams    = "ngrams"
+	JobTypeKeywords  = "keywords"
+
+	// MissedRunSkip ignores runs missed during a server downtime
+	MissedRunSkip = "skip"
+
+	// MissedRunOnce runs a job once after startup in case
+	// at least one run was missed during a server downtime
+	MissedRunOnce = "runOnce"
+)
+
+var cronParser = cron.NewParser(
+	cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// Conf configures scheduled jobs
+type Conf struct {
+
+	// StatePath is a path of a file where the scheduler keeps
+	// last runs and paused schedules. Without the file, missed runs
+	// cannot be detected and pausing won't survive a restart.
+	StatePath string `json:"statePath"`
+
+	Schedules []ScheduleConf `json:"schedules"`
+}
+
+func (conf *Conf) Validate() error {
+	if conf == nil {
+		return nil
+	}
+	ids := make(map[string]bool)
+	for _, sch := range conf.Schedules {
+		if sch.ID == "" {
+			return fmt.Errorf("schedule with missing id")
+		}
+		if ids[sch.ID] {
+			return fmt.Errorf("duplicate schedule id %s", sch.ID)
+		}
+		ids[sch.ID] = true
+		if err := sch.Validate(); err != nil {
+			return fmt.Errorf("invalid schedule %s: %w", sch.ID, err)
+		}
+	}
+	return nil
+}
+
+// ScheduleConf maps a cron expression to a job template
+type ScheduleConf struct {
+	ID string `json:"id"`
+
+	// Cron is a standard cron expression (minute, hour, day of month,
+	// month, day of week) or a descriptor like "@daily"
+	Cron string `json:"cron"`
+
+	// Paused is an initial state of the schedule. The value
+	// stored in the scheduler state takes precedence.
+	Paused bool `json:"paused"`
+
+	// MissedRuns specifies how to handle runs missed during a server
+	// downtime ("skip" (default) or "runOnce")
+	MissedRuns string `json:"missedRuns"`
+
+	Job JobTemplate `json:"job"`
+}
+
+func (sc ScheduleConf) Validate() error {
+	if _, err := cronParser.Parse(sc.Cron); err != nil {
+		return fmt.Errorf("invalid cron expression: %w", err)
+	}
+	switch sc.MissedRuns {
+	case "", MissedRunSkip, MissedRunOnce:
+	default:
+		return fmt.Errorf("invalid missedRuns value %s", sc.MissedRuns)
+	}
+	return sc.Job.Validate()
+}
+
+func (sc ScheduleConf) MissedRunsPolicy() string {
+	if sc.MissedRuns == "" {
+		return MissedRunSkip
+	}
+	return sc.MissedRuns
+}
+
+// JobTemplate describes a job created on each scheduled run.
+// The arguments are the same as the ones of the respective
+// HTTP API actions.
+type JobTemplate struct {
+
+	// Type is one of "liveattrs", "ngrams", "keywords"
+	Type string `json:"type"`
+
+	// CorpusID is used by liveattrs and ngrams jobs
+	CorpusID string `json:"corpusId"`
+
+	// DatasetID is a monitoring dataset used by keywords jobs
+	DatasetID string `json:"datasetId"`
+
+	// Query contains URL arguments of the respective action
+	// (e.g. aliasOf, reconfigure, append, ngramSize, daysBack)
+	Query map[string]string `json:"query"`
+
+	// LiveAttrsArgs is used by liveattrs jobs
+	LiveAttrsArgs *laconf.PatchArgs `json:"liveAttrsArgs"`
+
+	// NgramsArgs is used by ngrams jobs. In case it is also
+	// set for a liveattrs job, n-grams are generated once
+	// the liveattrs job finishes.
+	NgramsArgs *dictActions.NGramsReqArgs `json:"ngramsArgs"`
+
+	// NgramsQuery contains URL arguments of the n-grams action
+	// chained after a liveattrs job
+	NgramsQuery map[string]string `json:"ngramsQuery"`
+}
+
+func (jt JobTemplate) Validate() error {
+	switch jt.Type {
+	case JobTypeLiveAttrs:
+		if jt.CorpusID == "" {
+			return fmt.Errorf("missing corpusId")
+		}
+		if jt.LiveAttrsArgs != nil {
+			if err := jt.LiveAttrsArgs.ValidateDataWindow(); err != nil {
+				return err
+			}
+		}
+	case JobTypeNgrams:
+		if jt.CorpusID == "" {
+			return fmt.Errorf("missing corpusId")
+		}
+	case JobTypeKeywords:
+		if jt.DatasetID == "" {
+			return fmt.Errorf("missing datasetId")
+		}
+	default:
+		return fmt.Errorf("unknown job type %s", jt.Type)
+	}
+	if jt.NgramsArgs != nil {
+		if err := jt.NgramsArgs.Validate(); err != nil {
+			return fmt.Errorf("invalid ngramsArgs: %w", err)
+		}
+	}
+	return nil
+}
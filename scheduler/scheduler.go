@@ -0,0 +1,274 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	ErrorScheduleNotFound = errors.New("schedule not found")
+)
+
+type schedule struct {
+	conf  ScheduleConf
+	sched cron.Schedule
+	state scheduleState
+}
+
+// ScheduleInfo is a JSON-friendly overview of a schedule
+type ScheduleInfo struct {
+	ID         string      `json:"id"`
+	Cron       string      `json:"cron"`
+	Paused     bool        `json:"paused"`
+	MissedRuns string      `json:"missedRuns"`
+	Job        JobTemplate `json:"job"`
+	LastRun    *time.Time  `json:"lastRun"`
+	NextRun    *time.Time  `json:"nextRun"`
+	LastJobIDs []string    `json:"lastJobIds"`
+	LastError  string      `json:"lastError,omitempty"`
+}
+
+// Scheduler creates jobs based on configured cron expressions
+type Scheduler struct {
+	ctx       context.Context
+	statePath string
+	location  *time.Location
+	cron      *cron.Cron
+	trigger   Trigger
+	schedules map[string]*schedule
+	order     []string
+	lock      sync.Mutex
+}
+
+func (s *Scheduler) info(sch *schedule) ScheduleInfo {
+	ans := ScheduleInfo{
+		ID:         sch.conf.ID,
+		Cron:       sch.conf.Cron,
+		Paused:     sch.state.Paused,
+		MissedRuns: sch.conf.MissedRunsPolicy(),
+		Job:        sch.conf.Job,
+		LastJobIDs: sch.state.LastJobIDs,
+		LastError:  sch.state.LastError,
+	}
+	if ans.LastJobIDs == nil {
+		ans.LastJobIDs = []string{}
+	}
+	if !sch.state.LastRun.IsZero() {
+		lastRun := sch.state.LastRun.In(s.location)
+		ans.LastRun = &lastRun
+	}
+	if !sch.state.Paused {
+		nextRun := sch.sched.Next(time.Now().In(s.location))
+		ans.NextRun = &nextRun
+	}
+	return ans
+}
+
+func (s *Scheduler) saveState() {
+	state := make(map[string]scheduleState)
+	for id, sch := range s.schedules {
+		state[id] = sch.state
+	}
+	if err := saveState(s.statePath, state); err != nil {
+		log.Error().Err(err).Msg("failed to save scheduler state")
+	}
+}
+
+// run creates jobs of a schedule. With force set,
+// the paused state is ignored.
+func (s *Scheduler) run(scheduleID string, force bool) (ScheduleInfo, error) {
+	s.lock.Lock()
+	sch, ok := s.schedules[scheduleID]
+	if !ok {
+		s.lock.Unlock()
+		return ScheduleInfo{}, ErrorScheduleNotFound
+	}
+	if sch.state.Paused && !force {
+		s.lock.Unlock()
+		log.Info().Str("scheduleId", scheduleID).Msg("schedule paused, skipping run")
+		return s.info(sch), nil
+	}
+	tpl := sch.conf.Job
+	s.lock.Unlock()
+
+	runTime := time.Now()
+	jobIDs, err := s.trigger.Trigger(s.ctx, tpl)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	sch.state.LastRun = runTime
+	sch.state.LastJobIDs = jobIDs
+	sch.state.LastError = ""
+	if err != nil {
+		sch.state.LastError = err.Error()
+		log.Error().Err(err).Str("scheduleId", scheduleID).Msg("failed to run scheduled job")
+
+	} else {
+		log.Info().
+			Str("scheduleId", scheduleID).
+			Strs("jobIds", jobIDs).
+			Msg("scheduled job created")
+	}
+	s.saveState()
+	return s.info(sch), err
+}
+
+// missedRuns returns IDs of schedules which should have run
+// while the server was down and which want to catch up
+func (s *Scheduler) missedRuns(now time.Time) []string {
+	ans := make([]string, 0, len(s.order))
+	for _, id := range s.order {
+		sch := s.schedules[id]
+		if sch.state.LastRun.IsZero() || sch.state.Paused {
+			continue
+		}
+		next := sch.sched.Next(sch.state.LastRun.In(s.location))
+		if !next.Before(now) {
+			continue
+		}
+		if sch.conf.MissedRunsPolicy() == MissedRunOnce {
+			log.Warn().
+				Str("scheduleId", id).
+				Time("missedRun", next).
+				Msg("schedule missed a run during downtime, running it now")
+			ans = append(ans, id)
+
+		} else {
+			log.Warn().
+				Str("scheduleId", id).
+				Time("missedRun", next).
+				Msg("schedule missed a run during downtime, skipping")
+		}
+	}
+	return ans
+}
+
+// Start runs missed jobs (based on configured policies) and starts
+// to watch schedules. The scheduler stops along with its context.
+func (s *Scheduler) Start() {
+	s.lock.Lock()
+	missed := s.missedRuns(time.Now().In(s.location))
+	s.lock.Unlock()
+	for _, id := range missed {
+		s.run(id, false)
+	}
+	s.cron.Start()
+	go func() {
+		<-s.ctx.Done()
+		<-s.cron.Stop().Done()
+		log.Info().Msg("scheduler stopped")
+	}()
+	log.Info().Int("numSchedules", len(s.order)).Msg("scheduler started")
+}
+
+// List returns all the configured schedules
+func (s *Scheduler) List() []ScheduleInfo {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	ans := make([]ScheduleInfo, 0, len(s.order))
+	for _, id := range s.order {
+		ans = append(ans, s.info(s.schedules[id]))
+	}
+	return ans
+}
+
+// Get returns a schedule info
+func (s *Scheduler) Get(scheduleID string) (ScheduleInfo, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	sch, ok := s.schedules[scheduleID]
+	if !ok {
+		return ScheduleInfo{}, ErrorScheduleNotFound
+	}
+	return s.info(sch), nil
+}
+
+// SetPaused pauses or resumes a schedule
+func (s *Scheduler) SetPaused(scheduleID string, paused bool) (ScheduleInfo, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	sch, ok := s.schedules[scheduleID]
+	if !ok {
+		return ScheduleInfo{}, ErrorScheduleNotFound
+	}
+	sch.state.Paused = paused
+	s.saveState()
+	log.Info().Str("scheduleId", scheduleID).Bool("paused", paused).Msg("schedule state changed")
+	return s.info(sch), nil
+}
+
+// RunNow creates jobs of a schedule immediately (even if paused)
+func (s *Scheduler) RunNow(scheduleID string) (ScheduleInfo, error) {
+	return s.run(scheduleID, true)
+}
+
+// NewScheduler creates a scheduler with schedules defined in conf.
+// Stored state (if any) is loaded so that paused schedules
+// and last runs are preserved across restarts.
+func NewScheduler(
+	ctx context.Context,
+	conf *Conf,
+	location *time.Location,
+	trigger Trigger,
+) (*Scheduler, error) {
+	ans := &Scheduler{
+		ctx:       ctx,
+		location:  location,
+		cron:      cron.New(cron.WithLocation(location), cron.WithParser(cronParser)),
+		trigger:   trigger,
+		schedules: make(map[string]*schedule),
+		order:     make([]string, 0, 10),
+	}
+	if conf == nil {
+		return ans, nil
+	}
+	ans.statePath = conf.StatePath
+	state, err := loadState(conf.StatePath)
+	if err != nil {
+		return nil, err
+	}
+	if conf.StatePath == "" && len(conf.Schedules) > 0 {
+		log.Warn().Msg("scheduler statePath not set, missed runs won't be detected")
+	}
+	for _, schConf := range conf.Schedules {
+		sched, err := cronParser.Parse(schConf.Cron)
+		if err != nil {
+			return nil, err
+		}
+		schState, ok := state[schConf.ID]
+		if !ok {
+			schState.Paused = schConf.Paused
+		}
+		ans.schedules[schConf.ID] = &schedule{
+			conf:  schConf,
+			sched: sched,
+			state: schState,
+		}
+		ans.order = append(ans.order, schConf.ID)
+		scheduleID := schConf.ID
+		ans.cron.Schedule(sched, cron.FuncJob(func() {
+			ans.run(scheduleID, false)
+		}))
+	}
+	return ans, nil
+}
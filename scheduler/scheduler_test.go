@@ -0,0 +1,106 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	dictActions "frodo/dictionary/actions"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingTrigger struct {
+	templates []JobTemplate
+}
+
+func (rt *recordingTrigger) Trigger(ctx context.Context, tpl JobTemplate) ([]string, error) {
+	rt.templates = append(rt.templates, tpl)
+	return []string{"job-1"}, nil
+}
+
+func TestMissedRunsPolicy(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "scheduler.json")
+	lastRun := time.Now().Add(-48 * time.Hour)
+	assert.NoError(t, saveState(statePath, map[string]scheduleState{
+		"a": {LastRun: lastRun},
+		"b": {LastRun: lastRun},
+		"c": {LastRun: lastRun, Paused: true},
+		"d": {LastRun: time.Now()},
+	}))
+	conf := &Conf{
+		StatePath: statePath,
+		Schedules: []ScheduleConf{
+			{ID: "a", Cron: "@daily", MissedRuns: MissedRunOnce, Job: JobTemplate{Type: JobTypeKeywords, DatasetID: "a"}},
+			{ID: "b", Cron: "@daily", Job: JobTemplate{Type: JobTypeKeywords, DatasetID: "b"}},
+			{ID: "c", Cron: "@daily", MissedRuns: MissedRunOnce, Job: JobTemplate{Type: JobTypeKeywords, DatasetID: "c"}},
+			{ID: "d", Cron: "@daily", MissedRuns: MissedRunOnce, Job: JobTemplate{Type: JobTypeKeywords, DatasetID: "d"}},
+		},
+	}
+	assert.NoError(t, conf.Validate())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	trigger := &recordingTrigger{}
+	sched, err := NewScheduler(ctx, conf, time.UTC, trigger)
+	assert.NoError(t, err)
+	sched.Start()
+	assert.Equal(t, 1, len(trigger.templates))
+	assert.Equal(t, "a", trigger.templates[0].DatasetID)
+
+	state, err := loadState(statePath)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"job-1"}, state["a"].LastJobIDs)
+	assert.True(t, state["c"].Paused)
+}
+
+func TestHandlerTriggerChainsNgrams(t *testing.T) {
+	var calls []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		calls = append(calls, req.URL.Path+"?"+req.URL.RawQuery)
+		w.Write([]byte(`{"id": "job-` + req.URL.Path[1:2] + `"}`))
+	})
+	trigger := NewHandlerTrigger(handler)
+	ids, err := trigger.Trigger(context.Background(), JobTemplate{
+		Type:        JobTypeLiveAttrs,
+		CorpusID:    "syn2020",
+		Query:       map[string]string{"reconfigure": "1"},
+		NgramsArgs:  &dictActions.NGramsReqArgs{MinFreq: 1},
+		NgramsQuery: map[string]string{"ngramSize": "2"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"job-l", "job-d"}, ids)
+	assert.Equal(
+		t,
+		[]string{
+			"/liveAttributes/syn2020/data?reconfigure=1",
+			"/dictionary/syn2020/ngrams?ngramSize=2&parentJobId=job-l",
+		},
+		calls,
+	)
+}
+
+func TestHandlerTriggerReportsError(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code": 404, "error": "unknown dataset"}`))
+	})
+	trigger := NewHandlerTrigger(handler)
+	_, err := trigger.Trigger(context.Background(), JobTemplate{Type: JobTypeKeywords, DatasetID: "foo"})
+	assert.EqualError(t, err, "/keywordsOfTheWeek/foo returned status 404: unknown dataset")
+}
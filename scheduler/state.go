@@ -0,0 +1,82 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// scheduleState is a persistent part of a schedule
+type scheduleState struct {
+	Paused     bool      `json:"paused"`
+	LastRun    time.Time `json:"lastRun"`
+	LastJobIDs []string  `json:"lastJobIds,omitempty"`
+	LastError  string    `json:"lastError,omitempty"`
+}
+
+// loadState reads scheduler state from a JSON file. A missing
+// file produces an empty state.
+func loadState(path string) (map[string]scheduleState, error) {
+	ans := make(map[string]scheduleState)
+	if path == "" {
+		return ans, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ans, nil
+
+	} else if err != nil {
+		return ans, fmt.Errorf("failed to load scheduler state: %w", err)
+	}
+	if err := json.Unmarshal(data, &ans); err != nil {
+		return ans, fmt.Errorf("failed to load scheduler state: %w", err)
+	}
+	return ans, nil
+}
+
+// saveState writes scheduler state so that a crash during
+// the write cannot damage the previous version
+func saveState(path string, state map[string]scheduleState) error {
+	if path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to save scheduler state: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to save scheduler state: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to save scheduler state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to save scheduler state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to save scheduler state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"frodo/pipeline"
+	"net/http"
+	"net/url"
+)
+
+// Trigger creates jobs based on a job template and returns
+// IDs of the created jobs
+type Trigger interface {
+	Trigger(ctx context.Context, tpl JobTemplate) ([]string, error)
+}
+
+// HandlerTrigger creates jobs by passing requests directly
+// to the server's HTTP handler. This way, scheduled jobs are
+// processed exactly the same way as the ones created via
+// the HTTP API (incl. all the arguments validation).
+type HandlerTrigger struct {
+	handler http.Handler
+}
+
+func (ht *HandlerTrigger) call(
+	ctx context.Context,
+	path string,
+	query map[string]string,
+	body any,
+) (string, error) {
+	args := url.Values{}
+	for k, v := range query {
+		args.Set(k, v)
+	}
+	return pipeline.CallJobAction(ctx, ht.handler, path, args, body)
+}
+
+func (ht *HandlerTrigger) ngrams(
+	ctx context.Context,
+	tpl JobTemplate,
+	query map[string]string,
+	parentJobID string,
+) (string, error) {
+	if parentJobID != "" {
+		tmp := make(map[string]string)
+		for k, v := range query {
+			tmp[k] = v
+		}
+		tmp["parentJobId"] = parentJobID
+		query = tmp
+	}
+	return ht.call(
+		ctx,
+		fmt.Sprintf("/dictionary/%s/ngrams", url.PathEscape(tpl.CorpusID)),
+		query,
+		tpl.NgramsArgs,
+	)
+}
+
+func (ht *HandlerTrigger) Trigger(ctx context.Context, tpl JobTemplate) ([]string, error) {
+	switch tpl.Type {
+	case JobTypeLiveAttrs:
+		jobID, err := ht.call(
+			ctx,
+			fmt.Sprintf("/liveAttributes/%s/data", url.PathEscape(tpl.CorpusID)),
+			tpl.Query,
+			tpl.LiveAttrsArgs,
+		)
+		if err != nil {
+			return []string{}, err
+		}
+		if tpl.NgramsArgs == nil {
+			return []string{jobID}, nil
+		}
+		ngramsJobID, err := ht.ngrams(ctx, tpl, tpl.NgramsQuery, jobID)
+		if err != nil {
+			return []string{jobID}, fmt.Errorf("failed to chain n-grams job: %w", err)
+		}
+		return []string{jobID, ngramsJobID}, nil
+	case JobTypeNgrams:
+		jobID, err := ht.ngrams(ctx, tpl, tpl.Query, "")
+		if err != nil {
+			return []string{}, err
+		}
+		return []string{jobID}, nil
+	case JobTypeKeywords:
+		jobID, err := ht.call(
+			ctx,
+			fmt.Sprintf("/keywordsOfTheWeek/%s", url.PathEscape(tpl.DatasetID)),
+			tpl.Query,
+			nil,
+		)
+		if err != nil {
+			return []string{}, err
+		}
+		return []string{jobID}, nil
+	default:
+		return []string{}, fmt.Errorf("unknown job type %s", tpl.Type)
+	}
+}
+
+func NewHandlerTrigger(handler http.Handler) *HandlerTrigger {
+	return &HandlerTrigger{handler: handler}
+}
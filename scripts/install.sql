@@ -1,7 +1,10 @@
+-- Database schema for a new installation. Existing installations
+-- must apply scripts in the migrations directory instead (in order).
+
 CREATE TABLE proc_times (
     id int NOT NULL AUTO_INCREMENT,
     data_size INT NOT NULL,
-    proc_type ENUM('ngrams', 'qs'),
+    proc_type ENUM('ngrams', 'qs', 'liveattrs'),
     num_items INT NOT NULL,
     proc_time float,
     PRIMARY KEY (id)
@@ -14,5 +17,54 @@ CREATE TABLE `usage` (
 	PRIMARY KEY (corpus_id, structattr_name)
 );
 
+CREATE TABLE job_store (
+    id varchar(64) NOT NULL,
+    kind varchar(127) NOT NULL,
+    job_type varchar(63) NOT NULL,
+    corpus_id varchar(127),
+    finished tinyint(1) NOT NULL DEFAULT 0,
+    data mediumtext NOT NULL,
+    queued tinyint(1) NOT NULL DEFAULT 0,
+    parent_ids text,
+    last_update datetime NOT NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE TABLE job_history (
+    id varchar(64) NOT NULL,
+    job_type varchar(63) NOT NULL,
+    corpus_id varchar(127),
+    status varchar(15) NOT NULL,
+    start datetime NOT NULL,
+    last_update datetime NOT NULL,
+    data mediumtext NOT NULL,
+    PRIMARY KEY (id),
+    KEY job_history_start_idx (start),
+    KEY job_history_corpus_id_idx (corpus_id),
+    KEY job_history_job_type_idx (job_type)
+);
+
+CREATE TABLE subcorpus (
+    id varchar(64) NOT NULL,
+    name varchar(255) NOT NULL,
+    owner varchar(127) NOT NULL,
+    corpus_id varchar(127) NOT NULL,
+    kind varchar(15) NOT NULL,
+    definition mediumtext NOT NULL,
+    size bigint NOT NULL DEFAULT 0,
+    num_docs int NOT NULL DEFAULT 0,
+    created datetime NOT NULL,
+    updated datetime NOT NULL,
+    last_diff mediumtext,
+    PRIMARY KEY (id),
+    UNIQUE KEY subcorpus_corpus_owner_name_idx (corpus_id, owner, name)
+);
+
+CREATE TABLE subcorpus_doc (
+    subcorpus_id varchar(64) NOT NULL,
+    doc_id varchar(255) NOT NULL,
+    PRIMARY KEY (subcorpus_id, doc_id)
+);
+
 -- individual data tables for live attributes and n-grams
 -- are created/dropped by Frodo dynamically
@@ -0,0 +1,6 @@
+-- Upgrades the proc_times table of installations created before
+-- liveattrs run times were recorded (see the 'liveattrs' proc_type).
+-- Without the migration, storing liveattrs run times fails in strict
+-- SQL mode (or stores an empty value otherwise).
+
+ALTER TABLE proc_times MODIFY proc_type ENUM('ngrams', 'qs', 'liveattrs');
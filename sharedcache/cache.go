@@ -0,0 +1,173 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sharedcache provides a cache shared by multiple FRODO
+// instances along with invalidation messages allowing the instances
+// to purge their local caches.
+package sharedcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	TypeMemory = "memory"
+	TypeRedis  = "redis"
+
+	// InvalidateLiveAttrsData is sent once liveattrs data
+	// of a corpus is rebuilt
+	InvalidateLiveAttrsData = "liveattrsData"
+
+	// InvalidateLiveAttrsConf is sent once liveattrs configuration
+	// of a corpus is changed or flushed from cache
+	InvalidateLiveAttrsConf = "liveattrsConf"
+
+	dfltKeyPrefix = "frodo:"
+	dfltChannel   = "frodo:invalidation"
+)
+
+var (
+	ErrorNotFound = errors.New("cache item not found")
+)
+
+// Conf configures the shared cache. A nil configuration
+// means an in-process cache.
+type Conf struct {
+
+	// Type is either "memory" (default) or "redis"
+	Type string `json:"type"`
+
+	// Address is a host:port of a Redis-compatible server
+	Address  string `json:"address"`
+	Password string `json:"password"`
+	DB       int    `json:"db"`
+
+	// KeyPrefix is prepended to all the keys so more services
+	// can share a single Redis database
+	KeyPrefix string `json:"keyPrefix"`
+
+	// Channel is a pub/sub channel for invalidation messages
+	Channel string `json:"channel"`
+}
+
+func (conf *Conf) Validate() error {
+	if conf == nil {
+		return nil
+	}
+	switch conf.Type {
+	case TypeMemory, "":
+	case TypeRedis:
+		if conf.Address == "" {
+			return fmt.Errorf("missing address of the redis shared cache")
+		}
+	default:
+		return fmt.Errorf("unknown shared cache type: %s", conf.Type)
+	}
+	return nil
+}
+
+func (conf *Conf) keyPrefix() string {
+	if conf.KeyPrefix == "" {
+		return dfltKeyPrefix
+	}
+	return conf.KeyPrefix
+}
+
+func (conf *Conf) channel() string {
+	if conf.Channel == "" {
+		return dfltChannel
+	}
+	return conf.Channel
+}
+
+// Invalidation is a message telling other instances
+// to purge their local caches
+type Invalidation struct {
+
+	// Origin identifies an instance which sent the message
+	Origin string `json:"origin"`
+
+	// Kind is one of the Invalidate* values
+	Kind string `json:"kind"`
+
+	CorpusID string `json:"corpusId"`
+}
+
+// Cache is a key-value cache with invalidation messages. Implementations
+// must be safe for concurrent use.
+type Cache interface {
+
+	// Get returns a cached value or ErrorNotFound
+	Get(ctx context.Context, key string) ([]byte, error)
+
+	// Set stores a value. Zero ttl means "no expiration".
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Del removes values. Removing a non-existing key is not an error.
+	Del(ctx context.Context, keys ...string) error
+
+	// Publish sends an invalidation message to other instances
+	// sharing the cache. The message is not delivered back to the sender.
+	Publish(ctx context.Context, msg Invalidation) error
+
+	// Subscribe registers a handler of invalidation messages
+	// sent by other instances
+	Subscribe(handler func(msg Invalidation))
+
+	Close() error
+}
+
+// DatasetSizeKey returns a key of a cached dataset size
+func DatasetSizeKey(datasetName string) string {
+	return "datasetSize:" + datasetName
+}
+
+// GetInt is a helper function for integer values
+func GetInt(ctx context.Context, cache Cache, key string) (int64, error) {
+	data, err := cache.Get(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	ans, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid integer value of cache key %s: %w", key, err)
+	}
+	return ans, nil
+}
+
+// SetInt is a helper function for integer values
+func SetInt(ctx context.Context, cache Cache, key string, value int64, ttl time.Duration) error {
+	return cache.Set(ctx, key, []byte(strconv.FormatInt(value, 10)), ttl)
+}
+
+// NewCache creates a cache based on the provided configuration.
+// The context controls the lifetime of a pub/sub subscription.
+func NewCache(ctx context.Context, conf *Conf) (Cache, error) {
+	if conf == nil {
+		return NewMemoryCache(), nil
+	}
+	switch conf.Type {
+	case TypeMemory, "":
+		return NewMemoryCache(), nil
+	case TypeRedis:
+		return NewRedisCache(ctx, conf)
+	default:
+		return nil, fmt.Errorf("unknown shared cache type: %s", conf.Type)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sharedcache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestRedisCache(t *testing.T, ctx context.Context, srv *miniredis.Miniredis) *RedisCache {
+	cache, err := NewRedisCache(ctx, &Conf{Type: TypeRedis, Address: srv.Addr()})
+	assert.NoError(t, err)
+	return cache
+}
+
+func TestRedisCacheIsShared(t *testing.T) {
+	srv := miniredis.RunT(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cache1 := newTestRedisCache(t, ctx, srv)
+	cache2 := newTestRedisCache(t, ctx, srv)
+
+	assert.NoError(t, SetInt(ctx, cache1, DatasetSizeKey("syn2020"), 120000000, time.Minute))
+	v, err := GetInt(ctx, cache2, DatasetSizeKey("syn2020"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(120000000), v)
+	assert.True(t, srv.Exists("frodo:datasetSize:syn2020"))
+
+	assert.NoError(t, cache2.Del(ctx, DatasetSizeKey("syn2020")))
+	_, err = cache1.Get(ctx, DatasetSizeKey("syn2020"))
+	assert.ErrorIs(t, err, ErrorNotFound)
+}
+
+func TestRedisCacheCloseAfterContextDone(t *testing.T) {
+	srv := miniredis.RunT(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cache := newTestRedisCache(t, ctx, srv)
+	cancel()
+	assert.NoError(t, cache.Close())
+	assert.NoError(t, cache.Close())
+}
+
+func TestRedisCacheDeliversInvalidationToOthers(t *testing.T) {
+	srv := miniredis.RunT(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cache1 := newTestRedisCache(t, ctx, srv)
+	cache2 := newTestRedisCache(t, ctx, srv)
+
+	received1 := make(chan Invalidation, 1)
+	received2 := make(chan Invalidation, 1)
+	cache1.Subscribe(func(msg Invalidation) { received1 <- msg })
+	cache2.Subscribe(func(msg Invalidation) { received2 <- msg })
+
+	assert.NoError(t, cache1.Publish(ctx, Invalidation{Kind: InvalidateLiveAttrsData, CorpusID: "syn2020"}))
+	select {
+	case msg := <-received2:
+		assert.Equal(t, InvalidateLiveAttrsData, msg.Kind)
+		assert.Equal(t, "syn2020", msg.CorpusID)
+	case <-time.After(5 * time.Second):
+		t.Fatal("invalidation message not delivered")
+	}
+	select {
+	case <-received1:
+		t.Fatal("invalidation message delivered back to its sender")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestMemoryCacheExpiration(t *testing.T) {
+	cache := NewMemoryCache()
+	ctx := context.Background()
+	assert.NoError(t, cache.Set(ctx, "a", []byte("1"), time.Nanosecond))
+	assert.NoError(t, cache.Set(ctx, "b", []byte("2"), 0))
+	time.Sleep(time.Millisecond)
+	_, err := cache.Get(ctx, "a")
+	assert.ErrorIs(t, err, ErrorNotFound)
+	v, err := cache.Get(ctx, "b")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("2"), v)
+}
@@ -0,0 +1,83 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sharedcache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryItem struct {
+	value   []byte
+	expires time.Time
+}
+
+// MemoryCache is an in-process cache for single-instance deployments.
+// As there are no other instances, invalidation messages are dropped.
+type MemoryCache struct {
+	data map[string]memoryItem
+	lock sync.Mutex
+}
+
+func (mc *MemoryCache) Get(ctx context.Context, key string) ([]byte, error) {
+	mc.lock.Lock()
+	defer mc.lock.Unlock()
+	item, ok := mc.data[key]
+	if !ok {
+		return nil, ErrorNotFound
+	}
+	if !item.expires.IsZero() && time.Now().After(item.expires) {
+		delete(mc.data, key)
+		return nil, ErrorNotFound
+	}
+	return item.value, nil
+}
+
+func (mc *MemoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	mc.lock.Lock()
+	defer mc.lock.Unlock()
+	item := memoryItem{value: value}
+	if ttl > 0 {
+		item.expires = time.Now().Add(ttl)
+	}
+	mc.data[key] = item
+	return nil
+}
+
+func (mc *MemoryCache) Del(ctx context.Context, keys ...string) error {
+	mc.lock.Lock()
+	defer mc.lock.Unlock()
+	for _, key := range keys {
+		delete(mc.data, key)
+	}
+	return nil
+}
+
+func (mc *MemoryCache) Publish(ctx context.Context, msg Invalidation) error {
+	return nil
+}
+
+func (mc *MemoryCache) Subscribe(handler func(msg Invalidation)) {
+}
+
+func (mc *MemoryCache) Close() error {
+	return nil
+}
+
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{data: make(map[string]memoryItem)}
+}
@@ -0,0 +1,182 @@
+// Copyright 2026 Institute of the Czech National Corpus,
+//                Faculty of Arts, Charles University
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sharedcache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+)
+
+// RedisCache is a cache stored in a Redis-compatible server. Invalidation
+// messages are distributed via the server's pub/sub.
+type RedisCache struct {
+	client    *redis.Client
+	pubsub    *redis.PubSub
+	keyPrefix string
+	channel   string
+
+	// origin identifies the instance in invalidation messages
+	origin string
+
+	handlers     []func(msg Invalidation)
+	handlersLock sync.RWMutex
+
+	// pubsub is closed either along with the context passed
+	// to NewRedisCache or by Close, whichever comes first
+	unsubscribeOnce sync.Once
+	unsubscribeErr  error
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+func (rc *RedisCache) key(k string) string {
+	return rc.keyPrefix + k
+}
+
+func (rc *RedisCache) Get(ctx context.Context, key string) ([]byte, error) {
+	ans, err := rc.client.Get(ctx, rc.key(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrorNotFound
+
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get cache item %s: %w", key, err)
+	}
+	return ans, nil
+}
+
+func (rc *RedisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := rc.client.Set(ctx, rc.key(key), value, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to set cache item %s: %w", key, err)
+	}
+	return nil
+}
+
+func (rc *RedisCache) Del(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	pkeys := make([]string, len(keys))
+	for i, k := range keys {
+		pkeys[i] = rc.key(k)
+	}
+	if err := rc.client.Del(ctx, pkeys...).Err(); err != nil {
+		return fmt.Errorf("failed to delete cache items: %w", err)
+	}
+	return nil
+}
+
+func (rc *RedisCache) Publish(ctx context.Context, msg Invalidation) error {
+	msg.Origin = rc.origin
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to publish invalidation message: %w", err)
+	}
+	if err := rc.client.Publish(ctx, rc.channel, data).Err(); err != nil {
+		return fmt.Errorf("failed to publish invalidation message: %w", err)
+	}
+	return nil
+}
+
+func (rc *RedisCache) Subscribe(handler func(msg Invalidation)) {
+	rc.handlersLock.Lock()
+	defer rc.handlersLock.Unlock()
+	rc.handlers = append(rc.handlers, handler)
+}
+
+// dispatch passes received messages to handlers until
+// the subscription is closed
+func (rc *RedisCache) dispatch(messages <-chan *redis.Message) {
+	for rmsg := range messages {
+		var msg Invalidation
+		if err := json.Unmarshal([]byte(rmsg.Payload), &msg); err != nil {
+			log.Error().Err(err).Msg("failed to decode invalidation message, ignoring")
+			continue
+		}
+		if msg.Origin == rc.origin {
+			continue
+		}
+		log.Info().
+			Str("kind", msg.Kind).
+			Str("corpusId", msg.CorpusID).
+			Str("origin", msg.Origin).
+			Msg("received cache invalidation message")
+		rc.handlersLock.RLock()
+		for _, handler := range rc.handlers {
+			handler(msg)
+		}
+		rc.handlersLock.RUnlock()
+	}
+}
+
+// unsubscribe closes the invalidation messages subscription.
+// It is safe to call it multiple times.
+func (rc *RedisCache) unsubscribe() error {
+	rc.unsubscribeOnce.Do(func() {
+		rc.unsubscribeErr = rc.pubsub.Close()
+	})
+	return rc.unsubscribeErr
+}
+
+// Close closes the subscription and the client connection.
+// It is safe to call it multiple times.
+func (rc *RedisCache) Close() error {
+	rc.closeOnce.Do(func() {
+		rc.closeErr = errors.Join(rc.unsubscribe(), rc.client.Close())
+	})
+	return rc.closeErr
+}
+
+// NewRedisCache connects to a Redis-compatible server and subscribes
+// to invalidation messages. The subscription is closed along with ctx.
+func NewRedisCache(ctx context.Context, conf *Conf) (*RedisCache, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     conf.Address,
+		Password: conf.Password,
+		DB:       conf.DB,
+	})
+	ans := &RedisCache{
+		client:    client,
+		keyPrefix: conf.keyPrefix(),
+		channel:   conf.channel(),
+		origin:    uuid.New().String(),
+	}
+	ans.pubsub = client.Subscribe(ctx, ans.channel)
+	// make sure we are subscribed before any message can be published
+	if _, err := ans.pubsub.Receive(ctx); err != nil {
+		ans.pubsub.Close()
+		client.Close()
+		return nil, fmt.Errorf("failed to subscribe to shared cache channel %s: %w", ans.channel, err)
+	}
+	go ans.dispatch(ans.pubsub.Channel())
+	go func() {
+		<-ctx.Done()
+		ans.unsubscribe()
+	}()
+	log.Info().
+		Str("address", conf.Address).
+		Str("channel", ans.channel).
+		Msg("connected to redis shared cache")
+	return ans, nil
+}